@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/asgard-ai-platform/asgard-mcp-server/pkg/mcp"
+)
+
+// runBench implements the "bench" subcommand, a load generator for a single tool
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	configFile := fs.String("config", "", "Path to a YAML or JSON config file whose endpoint, credentials, and client settings are used; flags override its values")
+	endpointURL := fs.String("endpoint", "", "The endpoint URL for the MCP asgard-mcp-server (default $"+mcp.EnvEndpoint+")")
+	apiKey := fs.String("api-key", "", "The API key for authentication (default $"+mcp.EnvAPIKey+")")
+	apiKeyFile := fs.String("api-key-file", "", "Read the API key from this file")
+	toolName := fs.String("tool", "", "The name of the tool to invoke")
+	toolArgs := fs.String("args", "{}", "JSON arguments passed to every tool call")
+	duration := fs.Duration("duration", 30*time.Second, "How long to generate load")
+	concurrency := fs.Int("concurrency", 1, "Number of concurrent workers")
+	rate := fs.Float64("rate", 0, "Target calls per second across all workers (0 for unthrottled)")
+	_ = fs.Parse(args)
+
+	// Build the client the way the server does, from the config file with flags and the environment on top
+	cfg := mcp.DefaultConfig()
+	if *configFile != "" {
+		loaded, err := mcp.LoadConfig(*configFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		cfg = *loaded
+	}
+	if *endpointURL != "" {
+		cfg.Endpoint = *endpointURL
+	}
+	if *apiKey != "" {
+		cfg.APIKey = *apiKey
+	}
+	if *apiKeyFile != "" {
+		cfg.APIKeyFile = *apiKeyFile
+	}
+	cfg.ApplyEnvironment()
+
+	if cfg.Endpoint == "" || (cfg.APIKey == "" && cfg.APIKeyFile == "" && !cfg.OAuth2.Enabled()) || *toolName == "" {
+		fmt.Println("Error: endpoint URL, API key and tool name are required")
+		fs.Usage()
+		return 1
+	}
+	if !json.Valid([]byte(*toolArgs)) {
+		fmt.Println("Error: -args must be valid JSON")
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Error: invalid configuration:\n%v\n", err)
+		return 1
+	}
+	clientOpts, err := cfg.APIClientOptions()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	// Interrupting stops the manifest fetch as well as the run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := mcp.NewAPIClientWithOptions(cfg.Endpoint, cfg.APIKey, clientOpts...)
+	defer func() { _ = client.Close() }()
+	manifest, err := client.FetchToolsetManifest(ctx)
+	if err != nil {
+		fmt.Printf("Error: failed to fetch toolset manifest: %v\n", err)
+		return 1
+	}
+
+	var tool *mcp.Tool
+	for i := range manifest.Tools {
+		if manifest.Tools[i].Name == *toolName {
+			tool = &manifest.Tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		fmt.Printf("Error: tool %q not found in toolset %s/%s\n", *toolName, manifest.Namespace, manifest.Name)
+		return 1
+	}
+
+	fmt.Printf("Benchmarking tool %s for %s (concurrency=%d, rate=%.2f/s)\n", tool.Name, *duration, *concurrency, *rate)
+	report, err := mcp.RunBenchmark(ctx, client, tool, json.RawMessage(*toolArgs), mcp.BenchmarkOptions{
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Rate:        *rate,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(report.String())
+	return 0
+}
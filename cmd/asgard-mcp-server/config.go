@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/asgard-ai-platform/asgard-mcp-server/pkg/mcp"
+)
+
+// runConfig implements the "config" subcommand
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Println("Usage: asgard-mcp-server config validate [-check-tools] <config-file>")
+		return 1
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	checkTools := fs.Bool("check-tools", false, "Fetch the manifest of every endpoint and check that referenced tools exist")
+	_ = fs.Parse(args[1:])
+
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: asgard-mcp-server config validate [-check-tools] <config-file>")
+		return 1
+	}
+	path := fs.Arg(0)
+
+	cfg, err := mcp.LoadConfig(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("%s is invalid:\n%v\n", path, err)
+		return 1
+	}
+
+	// Referential checks need the live manifests
+	if *checkTools {
+		if err := cfg.CheckTools(); err != nil {
+			fmt.Printf("%s is invalid:\n%v\n", path, err)
+			return 1
+		}
+	}
+
+	fmt.Printf("%s is valid\n", path)
+	return 0
+}
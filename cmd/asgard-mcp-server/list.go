@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/asgard-ai-platform/asgard-mcp-server/pkg/mcp"
+)
+
+// listedTool is a tool as printed by -list-tools
+type listedTool struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	AllowUploadFiles bool   `json:"allow_upload_files"`
+	Endpoint         string `json:"endpoint"`
+}
+
+// listTools creates the server from the config without serving and prints the tools clients would see, as a
+// table or as JSON; it returns a nonzero exit code when a manifest cannot be fetched
+func listTools(cfg mcp.Config, format string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The server names, filters, and describes the tools exactly as when serving
+	server, err := mcp.NewServerFromConfigWithContext(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	exposed, err := server.ExposedTools()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	tools := make([]listedTool, 0, len(exposed))
+	for _, tool := range exposed {
+		tools = append(tools, listedTool{
+			Name:             tool.Name,
+			Description:      tool.Description,
+			AllowUploadFiles: tool.AllowUploadFiles,
+			Endpoint:         tool.Endpoint,
+		})
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tools); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tUPLOADS\tDESCRIPTION")
+	for _, tool := range tools {
+		// Only the first line of multi-line descriptions fits the table
+		description, _, _ := strings.Cut(tool.Description, "\n")
+		_, _ = fmt.Fprintf(w, "%s\t%t\t%s\n", tool.Name, tool.AllowUploadFiles, description)
+	}
+	_ = w.Flush()
+	return 0
+}
@@ -1,37 +1,139 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/asgard-ai-platform/asgard-mcp-server/pkg/mcp"
 )
 
 func main() {
-	// Define flags for endpoint URL and API key
-	endpointURL := flag.String("endpoint", "", "The endpoint URL for the MCP asgard-mcp-server")
-	apiKey := flag.String("api-key", "", "The API key for authentication")
+	// Dispatch subcommands before parsing the server flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		}
+	}
+
+	// Define a flag for every option, defaulting to the config file's values so flags given alongside it take
+	// precedence
+	configFlags, err := mcp.NewConfigFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	showVersion := flag.Bool("version", false, "Print the version and build information and exit")
+
+	// Dry run
+	listToolsFlag := flag.Bool("list-tools", false, "Fetch the manifests, print the tools clients would see, and exit without serving")
+	listFormat := flag.String("list-format", "text", "Output format of -list-tools: text or json")
 
 	// Parse flags
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(versionString())
+		os.Exit(0)
+	}
+
+	cfg, err := configFlags.Config()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Validate mandatory parameters
-	if *endpointURL == "" || *apiKey == "" {
-		fmt.Println("Error: Both endpoint URL and API key are required")
+	if cfg.Endpoint == "" || (cfg.APIKey == "" && cfg.APIKeyFile == "" && !cfg.OAuth2.Enabled()) {
+		fmt.Printf("Error: Both endpoint URL and API key are required, set with -endpoint and -api-key (or -api-key-file, or OAuth2 client credentials) or the %s and %s environment variables\n",
+			mcp.EnvEndpoint, mcp.EnvAPIKey)
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	// Validate the resulting config
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Error: invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	// Send every log record, including those of the standard log package, through the configured handler
+	logger, err := mcp.NewLogger(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	// Validate the endpoint and credentials without serving
+	if *listToolsFlag {
+		if *listFormat != "text" && *listFormat != "json" {
+			fmt.Printf("Error: invalid -list-format value %q, expected text or json\n", *listFormat)
+			os.Exit(1)
+		}
+		os.Exit(listTools(cfg, *listFormat))
+	}
+
+	// Export traces when the OTEL_* environment configures an exporter
+	shutdownTracing, err := mcp.SetupTracing(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
+
 	// Initialize MCP asgard-mcp-server
-	server, err := mcp.NewServer(*endpointURL, *apiKey)
+	// SIGINT and SIGTERM interrupt startup retries; once created, the server handles them itself
+	serverVersion, _, _ := buildVersion()
+	startupCtx, stopStartup := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	server, err := mcp.NewServerFromConfigWithContext(startupCtx, cfg, mcp.WithVersion(serverVersion))
+	stopStartup()
 	if err != nil {
 		log.Fatalf("Failed to create MCP asgard-mcp-server: %v", err)
 	}
 
+	// Reload the tools on SIGHUP until serving stops
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	reloadOnHangup(reloadCtx, server)
+
 	// Start the asgard-mcp-server
-	if err := server.Start(); err != nil {
+	err = server.Start()
+	stopReload()
+	if err != nil {
 		log.Fatalf("Failed to start MCP asgard-mcp-server: %v", err)
 	}
 }
+
+// reloadOnHangup reloads the server's tools whenever the process receives SIGHUP; canceling ctx stops listening
+// and aborts a running reload
+func reloadOnHangup(ctx context.Context, server *mcp.Server) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-sigCh:
+				slog.Info("Received SIGHUP, reloading tools")
+				if _, _, err := server.ReloadTools(ctx); err != nil {
+					slog.Warn("Reload failed, keeping the current tools", "error", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
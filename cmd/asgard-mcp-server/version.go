@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, set by the release build with -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+// buildVersion returns the version the binary was built as, falling back to the module version and VCS details
+// recorded by the Go toolchain for builds without ldflags, such as go install
+func buildVersion() (string, string, string) {
+	v, c, d := version, commit, date
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v, c, d
+	}
+	if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && c == "none":
+			c = setting.Value
+		case setting.Key == "vcs.time" && d == "unknown":
+			d = setting.Value
+		}
+	}
+	return v, c, d
+}
+
+// versionString describes the build for -version
+func versionString() string {
+	v, c, d := buildVersion()
+	return fmt.Sprintf("asgard-mcp-server %s (commit %s, built %s, %s %s/%s)", v, c, d, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionIsNeverEmpty(t *testing.T) {
+	v, c, d := buildVersion()
+	if v == "" || c == "" || d == "" {
+		t.Errorf("buildVersion() = %q, %q, %q, want every part set", v, c, d)
+	}
+	if got := versionString(); !strings.HasPrefix(got, "asgard-mcp-server "+v+" ") {
+		t.Errorf("versionString() = %q, want it to start with the version %q", got, v)
+	}
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolAnnotations are the behavior hints of a manifest tool, passed to clients as MCP tool annotations to help
+// them decide whether a call needs approval; unset hints are conservative
+type ToolAnnotations struct {
+	ReadOnly    *bool `json:"read_only"`
+	Destructive *bool `json:"destructive"`
+	Idempotent  *bool `json:"idempotent"`
+	OpenWorld   *bool `json:"open_world"`
+}
+
+// hints returns the MCP annotations, assuming a tool modifies its environment destructively, is not idempotent,
+// and reaches external systems unless the manifest says otherwise
+func (a ToolAnnotations) hints() mcp.ToolAnnotation {
+	readOnly := valueOr(a.ReadOnly, false)
+	return mcp.ToolAnnotation{
+		ReadOnlyHint: mcp.ToBoolPtr(readOnly),
+		// Tools that modify nothing cannot be destructive
+		DestructiveHint: mcp.ToBoolPtr(valueOr(a.Destructive, !readOnly)),
+		IdempotentHint:  mcp.ToBoolPtr(valueOr(a.Idempotent, readOnly)),
+		OpenWorldHint:   mcp.ToBoolPtr(valueOr(a.OpenWorld, true)),
+	}
+}
+
+// valueOr returns the value v points to, or fallback when v is nil
+func valueOr(v *bool, fallback bool) bool {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+// toolTitle returns the human-friendly title of a tool: the manifest title, or else the name with underscores,
+// dashes, and dots turned into spaces and each word capitalized, as in "Get Customer By ID" for get_customer_by_id
+func toolTitle(tool Tool) string {
+	if tool.Title != "" {
+		return tool.Title
+	}
+
+	words := strings.FieldsFunc(tool.Name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+	for i, word := range words {
+		if strings.EqualFold(word, "id") || strings.EqualFold(word, "url") || strings.EqualFold(word, "api") {
+			words[i] = strings.ToUpper(word)
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAnnotationsReachTheRegisteredTool(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`),
+		`{"name":"lookup","description":"Look up","annotations":{"read_only":true},"invoke_endpoints":{"json":"$BACKEND/lookup"}}`,
+		`{"name":"update","description":"Update","annotations":{"destructive":false,"idempotent":true,"open_world":false},"invoke_endpoints":{"json":"$BACKEND/update"}}`,
+		`{"name":"wipe","description":"Wipe","invoke_endpoints":{"json":"$BACKEND/wipe"}}`,
+	)
+	s := newToolServer(t, backend)
+
+	want := map[string]string{
+		"lookup": "read_only=true destructive=false idempotent=true open_world=true",
+		"update": "read_only=false destructive=false idempotent=true open_world=false",
+		"wipe":   "read_only=false destructive=true idempotent=false open_world=true",
+	}
+	tools := listToolDefinitions(t, s)
+	if len(tools) != len(want) {
+		t.Fatalf("tools/list returned %d tools, want %d", len(tools), len(want))
+	}
+	for _, tool := range tools {
+		a := tool.Annotations
+		if a.ReadOnlyHint == nil || a.DestructiveHint == nil || a.IdempotentHint == nil || a.OpenWorldHint == nil {
+			t.Errorf("%s annotations = %+v, want every hint set", tool.Name, a)
+			continue
+		}
+		got := fmt.Sprintf("read_only=%v destructive=%v idempotent=%v open_world=%v",
+			*a.ReadOnlyHint, *a.DestructiveHint, *a.IdempotentHint, *a.OpenWorldHint)
+		if got != want[tool.Name] {
+			t.Errorf("%s annotations = %s, want %s", tool.Name, got, want[tool.Name])
+		}
+	}
+}
+
+func TestToolTitles(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`),
+		`{"name":"get_customer_by_id","description":"Get a customer","invoke_endpoints":{"json":"$BACKEND/customer"}}`,
+		`{"name":"list_orders","title":"Recent orders","description":"List orders","invoke_endpoints":{"json":"$BACKEND/orders"}}`,
+		`{"name":"export-report.csv","display_name":"Report export","description":"Export","invoke_endpoints":{"json":"$BACKEND/export"}}`,
+	)
+	s := newToolServer(t, backend)
+
+	want := map[string]string{
+		"get_customer_by_id": "Get Customer By ID",
+		"list_orders":        "Recent orders",
+		"export-report_csv":  "Report export",
+	}
+	tools := listToolDefinitions(t, s)
+	if len(tools) != len(want) {
+		t.Fatalf("tools/list returned %d tools, want %d", len(tools), len(want))
+	}
+	for _, tool := range tools {
+		if tool.Annotations.Title != want[tool.Name] {
+			t.Errorf("%s title = %q, want %q", tool.Name, tool.Annotations.Title, want[tool.Name])
+		}
+	}
+}
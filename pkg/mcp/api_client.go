@@ -2,32 +2,162 @@ package mcp
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
+	"net"
 	"net/http"
-	"net/textproto"
-	"os"
 	"path/filepath"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIClient handles API requests to the MCP asgard-mcp-server
 type APIClient struct {
 	baseURL string
-	apiKey  string
 	client  *http.Client
+
+	// apiKey is replaced when apiKeyFile changes, keeping previousAPIKey for the rotation window
+	keyMu          sync.RWMutex
+	apiKey         string
+	previousAPIKey string
+	apiKeyFile     string
+	keyWatcher     *fsnotify.Watcher
+
+	// authMode selects the header the key is sent in
+	authMode AuthMode
+
+	// oauth obtains bearer tokens with client credentials instead of sending the key
+	oauth *oauthTokens
+
+	// metrics records manifest fetches when the server exposes metrics
+	metrics *metrics
+
+	// extraHeaders are added to every manifest and tool request
+	extraHeaders http.Header
+
+	// manifests caches the last manifest for conditional refreshes
+	manifests manifestCache
+
+	// lastManifestSuccess holds the UnixNano time of the last successful manifest fetch
+	lastManifestSuccess atomic.Int64
+
+	// transport is the client's transport, tuned by options, dialing through dialer
+	transport *http.Transport
+	dialer    *net.Dialer
+
+	duplicateFileNames DuplicateFileNameMode
+	bodyReadTimeout    time.Duration
+	uploadsDisabled    bool
+	gzipUploadsMin     int64
+
+	// gzipRequestsMin is the JSON body size from which requests to tools accepting gzip are compressed, when positive
+	gzipRequestsMin int64
+
+	// uploadField is the argument the files to upload are read from
+	uploadField string
+
+	// bestEffortUploads leaves out unreadable local files instead of failing the call
+	bestEffortUploads bool
+
+	// uploadBaseDir is the absolute directory relative upload paths are resolved against, when set
+	uploadBaseDir string
+
+	// uploadRoots are the resolved directories local uploads must stay within, when set
+	uploadRoots []string
+
+	// uploadURLAllowlist restricts URL uploads to matching hosts and prefixes, fetched through uploadURLClient
+	uploadURLAllowlist []string
+	uploadURLClient    *http.Client
+
+	// maxUploadFileSize and maxUploadTotalSize bound uploads per file and per request when positive
+	maxUploadFileSize  int64
+	maxUploadTotalSize int64
+
+	// maxBodySize bounds manifest pages and buffered tool responses when positive
+	maxBodySize int64
+
+	// maxStreamSize bounds the data of a streamed response kept for the tool result when positive
+	maxStreamSize int
+
+	// limiter bounds concurrent tool calls, ordered by toolPriorities
+	limiter        *callLimiter
+	toolPriorities map[string]int
+
+	// breaker fails tool calls fast while the backend keeps failing
+	breaker *circuitBreaker
+
+	// retryPolicy and retryClassifier decide which failed requests are retried and when
+	retryPolicy     RetryPolicy
+	retryClassifier RetryClassifier
+
+	// dnsRefresh enables DNS pinning through resolver when positive
+	dnsRefresh time.Duration
+	resolver   *net.Resolver
+
+	// retryableTools may retry calls under the retry policy; nonRetryableTools are attempted exactly once whatever
+	// the retry policy
+	retryableTools    map[string]bool
+	nonRetryableTools map[string]bool
+
+	// bodyTemplates wrap the arguments of the named tools in a request envelope
+	bodyTemplates map[string]json.RawMessage
+
+	// requestIDHeader names the response header carrying the backend's request ID
+	requestIDHeader string
+
+	// maxRedirects bounds redirect chains
+	maxRedirects int
+
+	// invalidTools decides whether incomplete manifest tools are skipped or rejected
+	invalidTools InvalidToolsMode
+
+	// manifestKey, when set, is used to verify manifest signatures
+	manifestKey ed25519.PublicKey
+
+	// logger records manifest fetches, retries, and tool calls
+	logger *slog.Logger
 }
 
 // Tool represents a tool from the API
 type Tool struct {
-	Name             string              `json:"name"`
-	Description      string              `json:"description"`
-	InputSchema      json.RawMessage     `json:"input_schema"`
-	AllowUploadFiles bool                `json:"allow_upload_files"`
-	InvokeEndpoints  ToolInvokeEndpoints `json:"invoke_endpoints"`
+	Name              string              `json:"name"`
+	Title             string              `json:"title"`
+	Description       string              `json:"description"`
+	InputSchema       json.RawMessage     `json:"input_schema"`
+	AllowUploadFiles  bool                `json:"allow_upload_files"`
+	Prompt            bool                `json:"prompt"`
+	Tags              []string            `json:"tags"`
+	AcceptGzipUploads bool                `json:"accept_gzip_uploads"`
+	AcceptGzipBody    bool                `json:"accept_gzip_body"`
+	Retryable         bool                `json:"retryable"`
+	NonRetryable      bool                `json:"non_retryable"`
+	BinaryOutput      bool                `json:"binary_output"`
+	Annotations       ToolAnnotations     `json:"annotations"`
+	InvokeEndpoints   ToolInvokeEndpoints `json:"invoke_endpoints"`
+
+	// Timeout bounds calls of the tool in place of the client and call timeouts when positive; the manifest
+	// declares it as timeout_seconds
+	Timeout time.Duration `json:"-"`
+
+	// endpoint is the server endpoint the tool was fetched from
+	endpoint *endpoint
+	// exposed is the sanitized, unique name clients see for the tool
+	exposed string
 }
 
 // ToolInvokeEndpoints represents the invoke endpoints for a tool
@@ -42,112 +172,309 @@ type ToolsetManifest struct {
 	Name       string `json:"name"`
 	Generation int    `json:"generation"`
 	Tools      []Tool `json:"tools"`
+
+	// Resources are the read-only data sources of the toolset
+	Resources []Resource `json:"resources"`
+
+	// Prompts are the reusable prompt templates of the toolset
+	Prompts []PromptTemplate `json:"prompts"`
 }
 
+// Timeout defaults of the API client
+const (
+	// DefaultTimeout bounds a whole backend request, from dialing to reading the last body byte
+	DefaultTimeout = 30 * time.Second
+	// DefaultConnectTimeout bounds establishing a connection to the backend
+	DefaultConnectTimeout = 30 * time.Second
+)
+
+// Connection pool defaults of the API client, sized for a chatty workload against a single backend host
+const (
+	// DefaultMaxIdleConns bounds idle connections kept across all hosts
+	DefaultMaxIdleConns = 100
+	// DefaultMaxIdleConnsPerHost bounds idle connections kept per host, far above net/http's default of 2
+	DefaultMaxIdleConnsPerHost = 32
+	// DefaultIdleConnTimeout closes connections idle for longer
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
 // NewAPIClient creates a new API client
 func NewAPIClient(baseURL, apiKey string) *APIClient {
-	return &APIClient{
+	return NewAPIClientWithOptions(baseURL, apiKey)
+}
+
+// NewAPIClientWithOptions creates a new API client configured by the given options
+func NewAPIClientWithOptions(baseURL, apiKey string, opts ...APIClientOption) *APIClient {
+	dialer := &net.Dialer{
+		Timeout:   DefaultConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.MaxIdleConns = DefaultMaxIdleConns
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	c := &APIClient{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   DefaultTimeout,
+			Transport: transport,
 		},
+		transport:          transport,
+		dialer:             dialer,
+		duplicateFileNames: DuplicateFileNameKeep,
+		retryClassifier:    DefaultRetryClassifier,
+		invalidTools:       InvalidToolsSkip,
+		maxRedirects:       DefaultMaxRedirects,
+		requestIDHeader:    DefaultRequestIDHeader,
+		uploadField:        UploadedFilePathsFieldName,
+		maxBodySize:        DefaultMaxBodySize,
+		maxStreamSize:      DefaultMaxResponseSize,
+		logger:             slog.Default(),
 	}
+	c.client.CheckRedirect = c.checkRedirect
+	c.tlsConfig()
+
+	// Apply options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Resolve the upload roots the same way upload paths are resolved
+	for i, root := range c.uploadRoots {
+		c.uploadRoots[i] = resolvePath(root)
+	}
+	if c.uploadBaseDir != "" {
+		if abs, err := filepath.Abs(c.uploadBaseDir); err == nil {
+			c.uploadBaseDir = abs
+		}
+	}
+	if len(c.uploadRoots) > 0 {
+		c.logger.Info("Upload roots restrict local files only, URL uploads follow the upload URL policy",
+			"roots", c.uploadRoots, "url_allowlist", c.uploadURLAllowlist)
+	}
+	c.uploadURLClient = c.newUploadURLClient()
+
+	if c.breaker != nil {
+		c.breaker.logger = c.logger
+	}
+
+	// Pin DNS resolution of backend hosts when enabled
+	if c.dnsRefresh > 0 {
+		pinned := newPinnedResolver(c.resolver, c.dialer, c.dnsRefresh, c.logger)
+		c.transport.DialContext = pinned.dialContext
+		pinned.warm(baseURL)
+	}
+
+	// Follow API key rotations
+	if c.apiKeyFile != "" {
+		if err := c.watchAPIKeyFile(); err != nil {
+			c.logger.Warn("Not watching the API key file", "path", c.apiKeyFile, "error", err)
+		}
+	}
+
+	return c
+}
+
+// FetchToolsetManifest fetches the toolset manifest from the endpoint; canceling ctx aborts the fetch
+func (c *APIClient) FetchToolsetManifest(ctx context.Context) (*ToolsetManifest, error) {
+	manifest, err := c.fetchToolsetManifest(ctx)
+	c.metrics.manifestFetched(err)
+	if err == nil {
+		c.lastManifestSuccess.Store(time.Now().UnixNano())
+	}
+	return manifest, err
+}
+
+// lastManifestFetch returns when the manifest was last fetched successfully, or the zero time
+func (c *APIClient) lastManifestFetch() time.Time {
+	if nanos := c.lastManifestSuccess.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
 }
 
-// FetchToolsetManifest fetches the toolset manifest from the endpoint
-func (c *APIClient) FetchToolsetManifest() (*ToolsetManifest, error) {
+// fetchToolsetManifest fetches and parses the toolset manifest, following its pages
+func (c *APIClient) fetchToolsetManifest(ctx context.Context) (*ToolsetManifest, error) {
+	var manifest *ToolsetManifest
+	var first *http.Response
+	pageURL := c.baseURL
+	seen := map[string]bool{pageURL: true}
+	for pages := 1; ; pages++ {
+		// Only the first page is revalidated; a cached manifest holds every page
+		page, resp, err := c.fetchManifestPage(ctx, pageURL, pages == 1)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			c.logger.Info("Manifest not modified, reusing the cached manifest", "endpoint", redactURL(c.baseURL, c.apiKeys()...))
+			return c.manifests.cached(), nil
+		}
+
+		if manifest == nil {
+			first = resp
+			manifest = &ToolsetManifest{
+				Namespace:  page.Namespace,
+				Name:       page.Name,
+				Generation: page.Generation,
+				Tools:      make([]Tool, 0, len(page.Tools)),
+			}
+		}
+		for _, t := range page.Tools {
+			manifest.Tools = append(manifest.Tools, t.tool())
+		}
+		manifest.Resources = append(manifest.Resources, page.Resources...)
+		manifest.Prompts = append(manifest.Prompts, page.Prompts...)
+
+		next, err := nextManifestPageURL(c.baseURL, page)
+		if err != nil {
+			return nil, err
+		}
+		if next == "" {
+			if pages > 1 {
+				c.logger.Info("Fetched paged manifest", "endpoint", redactURL(c.baseURL, c.apiKeys()...), "tools", len(manifest.Tools), "pages", pages)
+			}
+			break
+		}
+		if pages >= maxManifestPages {
+			return nil, fmt.Errorf("manifest has more than %d pages", maxManifestPages)
+		}
+		if seen[next] {
+			return nil, fmt.Errorf("manifest pagination loops back to %s", c.redact(next))
+		}
+		seen[next] = true
+		pageURL = next
+	}
+
+	manifest.Resources = c.validateManifestResources(manifest.Resources)
+	manifest.Prompts = c.validateManifestPrompts(manifest.Prompts)
+
+	// Surface incomplete tool definitions at discovery time
+	var err error
+	manifest.Tools, err = c.validateManifestTools(manifest.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	c.manifests.store(first, manifest)
+	return manifest, nil
+}
+
+// fetchManifestPage fetches and parses one page of the toolset manifest; a nil page means the backend confirmed
+// the cached manifest is current, which is only asked for when conditional is set
+func (c *APIClient) fetchManifestPage(ctx context.Context, pageURL string, conditional bool) (*manifestPage, *http.Response, error) {
 	// Create HTTP request
-	req, err := http.NewRequest("GET", c.baseURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
 	req.Header.Set("accept", "application/json")
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, nil, err
+	}
+	c.applyExtraHeaders(req)
+	if conditional {
+		c.manifests.conditional(req)
+	}
 
 	// Execute request
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, nil, c.redactError(fmt.Errorf("failed to execute request: %w", err))
 	}
 	defer func() { _ = resp.Body.Close() }()
+	requestID := c.requestID(resp)
+	if requestID != "" {
+		c.logger.Info("Fetched manifest page", "page", redactURL(pageURL, c.apiKeys()...), "request_id", requestID)
+	}
+
+	// Reuse the cached manifest when it has not changed
+	if resp.StatusCode == http.StatusNotModified && conditional && c.manifests.cached() != nil {
+		return nil, resp, nil
+	}
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.readBody(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return nil, nil, &APIError{StatusCode: resp.StatusCode, Code: errorCodeOf(body), Body: c.redact(string(body)), Header: resp.Header, RequestID: requestID}
+	}
+
+	// Verify the manifest signature when required
+	if c.manifestKey != nil {
+		if err := VerifyManifestSignature(body, resp.Header.Get(ManifestSignatureHeader), c.manifestKey); err != nil {
+			return nil, nil, fmt.Errorf("refusing to load manifest: %w", err)
+		}
 	}
 
 	// Parse response
 	var response struct {
-		IsSuccess bool `json:"isSuccess"`
-		Data      struct {
-			Namespace  string `json:"namespace"`
-			Name       string `json:"name"`
-			Generation int    `json:"generation"`
-			Tools      []struct {
-				Name             string          `json:"name"`
-				Description      string          `json:"description"`
-				InputSchema      json.RawMessage `json:"input_schema"`
-				AllowUploadFiles bool            `json:"allow_upload_files"`
-				InvokeEndpoints  struct {
-					JSON string `json:"json"`
-					Form string `json:"form"`
-				} `json:"invoke_endpoints"`
-			} `json:"tools"`
-		} `json:"data"`
-		Error     *string `json:"error"`
-		ErrorCode *string `json:"errorCode"`
+		IsSuccess bool         `json:"isSuccess"`
+		Data      manifestPage `json:"data"`
+		Error     *string      `json:"error"`
+		ErrorCode *string      `json:"errorCode"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	// Check for API errors
 	if !response.IsSuccess {
-		errMsg := "unknown error"
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: "unknown error", Body: c.redact(string(body)), Header: resp.Header, RequestID: requestID}
 		if response.Error != nil {
-			errMsg = *response.Error
+			apiErr.Message = c.redact(*response.Error)
 		}
-		return nil, fmt.Errorf("API error: %s", errMsg)
-	}
-
-	// Create toolset manifest with converted tools
-	manifest := &ToolsetManifest{
-		Namespace:  response.Data.Namespace,
-		Name:       response.Data.Name,
-		Generation: response.Data.Generation,
-		Tools:      make([]Tool, 0, len(response.Data.Tools)),
-	}
-
-	// Convert tools
-	for _, t := range response.Data.Tools {
-		tool := Tool{
-			Name:             t.Name,
-			Description:      t.Description,
-			InputSchema:      t.InputSchema,
-			AllowUploadFiles: t.AllowUploadFiles,
-			InvokeEndpoints: ToolInvokeEndpoints{
-				JSON: t.InvokeEndpoints.JSON,
-				Form: t.InvokeEndpoints.Form,
-			},
+		if response.ErrorCode != nil {
+			apiErr.Code = *response.ErrorCode
 		}
-		manifest.Tools = append(manifest.Tools, tool)
+		return nil, nil, apiErr
 	}
 
-	return manifest, nil
+	return &response.Data, resp, nil
+}
+
+// toolResponse is the outcome of a successful tool invocation
+type toolResponse struct {
+	// Data is the data portion of the response, or the raw body when it is not in the Asgard format
+	Data json.RawMessage
+	// RequestID is the backend's request ID, if any
+	RequestID string
+	// ContentType is the response content type, set for binary output tools and image or audio responses
+	ContentType string
 }
 
-// ExecuteToolRequest executes a tool request by making an HTTP request to the invoke endpoint
-func (c *APIClient) ExecuteToolRequest(tool *Tool, input json.RawMessage) (json.RawMessage, error) {
+// ExecuteToolRequest executes a tool request by making an HTTP request to the invoke endpoint,
+// aborting it when ctx is canceled
+func (c *APIClient) ExecuteToolRequest(ctx context.Context, tool *Tool, input json.RawMessage) (json.RawMessage, error) {
+	resp, err := c.invokeTool(ctx, tool, input)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// invokeTool executes a tool request and returns the response along with its metadata
+func (c *APIClient) invokeTool(ctx context.Context, tool *Tool, input json.RawMessage) (*toolResponse, error) {
+	// Fail fast while the backend is considered down
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	// Wait for a free slot when concurrency is bounded
+	if c.limiter != nil {
+		if err := c.limiter.acquire(ctx, c.toolPriority(tool)); err != nil {
+			return nil, fmt.Errorf("failed to acquire call slot: %w", err)
+		}
+		defer c.limiter.release()
+	}
 
 	// Determine the endpoint based on tool definition
 	endpoint := ""
@@ -162,102 +489,185 @@ func (c *APIClient) ExecuteToolRequest(tool *Tool, input json.RawMessage) (json.
 		return nil, fmt.Errorf("tool %s has no invoke endpoint", tool.Name)
 	}
 
-	// Prepare the body
-	var body io.Reader
-	var contentType string
-
+	// Extract the files to attach, leaving only the names of inline files in the JSON payload
+	var entries []uploadEntry
 	if tool.AllowUploadFiles {
-		// Buffer + multipart writer
-		buf := &bytes.Buffer{}
-		mw := multipart.NewWriter(buf)
-
-		// 1) JSON payload field
-		if err := mw.WriteField(FormDataKeyJSON, string(input)); err != nil {
-			return nil, fmt.Errorf("failed to write JSON field: %w", err)
-		}
-
-		// 2) Extract file paths and attach them
 		inputData := make(map[string]interface{})
 		if err := json.Unmarshal(input, &inputData); err != nil {
 			return nil, fmt.Errorf("failed to parse uploaded_file_paths: %w", err)
 		}
-		if filePathsIntf, ok := inputData[UploadedFilePathsFieldName].([]interface{}); ok {
-			for _, fpIntf := range filePathsIntf {
-				fp := fmt.Sprintf("%v", fpIntf)
-				f, err := os.Open(fp) //nolint
-				if err != nil {
-					return nil, fmt.Errorf("failed to open file %s: %w", fp, err)
-				}
-				defer func(f *os.File) {
-					_ = f.Close()
-				}(f)
-
-				// Detect MIME type
-				mimeType, err := DetectMime(fp)
-				if err != nil {
-					return nil, fmt.Errorf("failed to detect MIME type for file %s: %w", fp, err)
-				}
+		var err error
+		if entries, err = parseUploadEntries(inputData[c.uploadField]); err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 && c.uploadsDisabled {
+			return nil, errUploadsDisabled
+		}
+		if err := c.checkURLUploads(entries); err != nil {
+			return nil, err
+		}
 
-				h := make(textproto.MIMEHeader)
-				quoteEscaper := strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
-				fileName := quoteEscaper.Replace(filepath.Base(fp))
-				h.Set("Content-Disposition",
-					fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-						FormDataKeyFile, fileName))
-				h.Set("Content-Type", mimeType)
-				part, err := mw.CreatePart(h)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create form file part: %w", err)
-				}
-				if _, err := io.Copy(part, f); err != nil {
-					return nil, fmt.Errorf("failed to copy file into form: %w", err)
+		// Check the local files up front, leaving out unreadable ones in best-effort mode
+		if c.bestEffortUploads {
+			var skipped []skippedUpload
+			if entries, skipped = c.skipUnreadableUploads(entries); len(skipped) > 0 {
+				c.logger.Warn("Uploading without unreadable files", "tool", tool.Name, "skipped", len(skipped))
+				inputData[SkippedUploadsFieldName] = skipped
+				if input, err = json.Marshal(inputData); err != nil {
+					return nil, fmt.Errorf("failed to marshal arguments: %w", err)
 				}
 			}
+		} else if err := c.checkLocalUploads(entries); err != nil {
+			return nil, err
 		}
-
-		// 3) finalize
-		err := mw.Close()
-		if err != nil {
-			return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+		if input, err = stripInlineUploads(inputData, c.uploadField, entries, input); err != nil {
+			return nil, err
 		}
-		body = buf
+	}
+
+	// Wrap the arguments in the tool's request envelope
+	payload, err := c.applyBodyTemplate(tool, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the body, which can be recreated for retries
+	var newBody func() (io.ReadCloser, error)
+	var contentType, contentEncoding string
+
+	if tool.AllowUploadFiles {
+		// Stream the multipart body so files are never held in memory
+		mw := multipart.NewWriter(io.Discard)
+		boundary := mw.Boundary()
 		contentType = mw.FormDataContentType()
+		newBody = func() (io.ReadCloser, error) {
+			return c.streamMultipart(ctx, tool, boundary, payload, entries)
+		}
 	} else {
-		// JSON path
-		body = bytes.NewReader(input)
+		// JSON path, compressed once when large enough and accepted by the tool
+		if c.compressibleBody(tool, payload) {
+			if payload, err = gzipBytes(payload); err != nil {
+				return nil, err
+			}
+			contentEncoding = "gzip"
+		}
+		newBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(payload)), nil
+		}
 		contentType = "application/json"
 	}
 
+	// Tool calls are POSTs that may have side effects, so only tools marked retryable get more than one attempt
+	if !c.retryable(tool) {
+		ctx = withoutRetries(ctx)
+	}
+
+	// Tools declaring their own timeout are not bound by the client's
+	if tool.Timeout > 0 {
+		ctx = withRequestTimeout(ctx, tool.Timeout)
+	}
+
+	// Trace the backend request
+	ctx, span := tracer().Start(ctx, "POST "+tool.Name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attrToolName.String(tool.Name),
+		attribute.String("http.request.method", "POST"),
+		attribute.String("url.full", redactURL(endpoint)),
+	))
+	defer span.End()
+
 	// Create HTTP request
-	req, err := http.NewRequest("POST", endpoint, body)
+	body, err := newBody()
+	if err != nil {
+		failSpan(span, err)
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
 	if err != nil {
+		_ = body.Close()
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if !tool.AllowUploadFiles {
+		req.ContentLength = int64(len(payload))
+	}
+	req.GetBody = newBody
 
 	// Add headers
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	if tool.BinaryOutput {
+		req.Header.Set("Accept", "*/*")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	if err := c.authorize(req); err != nil {
+		_ = body.Close()
+		failSpan(span, err)
+		return nil, err
+	}
+	c.applyExtraHeaders(req)
+
+	// Propagate the trace context to the backend
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	// Execute request
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
+	if !errors.Is(err, context.Canceled) {
+		c.breaker.record(backendFailed(resp, err))
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		err = c.redactError(fmt.Errorf("failed to execute request: %w", err))
+		failSpan(span, err)
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	requestID := c.requestID(resp)
+	if requestID != "" {
+		c.logger.Info("Tool call reached the backend", "tool", tool.Name, "status", resp.StatusCode, "request_id", requestID)
+	}
+
+	// Relay streamed responses event by event
+	if resp.StatusCode == http.StatusOK && isEventStream(resp.Header.Get("Content-Type")) {
+		data, size, err := readEventStream(resp.Body, streamHandler(ctx), c.maxStreamSize)
+		if err != nil {
+			failSpan(span, err)
+			return nil, err
+		}
+		if c.maxStreamSize > 0 && size > c.maxStreamSize {
+			c.logger.Warn("Truncated streamed tool response", "tool", tool.Name, "bytes", size, "limit", c.maxStreamSize)
+		}
+		span.SetAttributes(attrResponseSize.Int(size))
+		return &toolResponse{Data: data, RequestID: requestID}, nil
+	}
 
 	// Read response body
-	respBytes, err := io.ReadAll(resp.Body)
+	respBytes, err := c.readBody(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		err = fmt.Errorf("failed to read response body: %w", err)
+		failSpan(span, err)
+		return nil, err
 	}
+	span.SetAttributes(attrResponseSize.Int(len(respBytes)))
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(respBytes))
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: errorCodeOf(respBytes), Body: c.redact(string(respBytes)), Header: resp.Header, RequestID: requestID}
+	}
+
+	// Binary output, images, and audio are passed through without assuming JSON
+	if contentType := resp.Header.Get("Content-Type"); tool.BinaryOutput || isMediaContent(contentType) {
+		return &toolResponse{Data: respBytes, RequestID: requestID, ContentType: contentType}, nil
 	}
 
-	// Parse Asgard MCP response format
+	return c.parseAsgardResponse(resp, respBytes, requestID)
+}
+
+// parseAsgardResponse unwraps the data of a successful response in the Asgard format, or returns the body as is
+// when it is not in that format
+func (c *APIClient) parseAsgardResponse(resp *http.Response, respBytes []byte, requestID string) (*toolResponse, error) {
 	var asgardResponse struct {
 		IsSuccess bool            `json:"isSuccess"`
 		Data      json.RawMessage `json:"data"`
@@ -267,23 +677,44 @@ func (c *APIClient) ExecuteToolRequest(tool *Tool, input json.RawMessage) (json.
 
 	if err := json.Unmarshal(respBytes, &asgardResponse); err != nil {
 		// If it's not in the Asgard format, return the raw response
-		return respBytes, nil
+		return &toolResponse{Data: respBytes, RequestID: requestID}, nil
 	}
 
 	// Check for API errors
 	if !asgardResponse.IsSuccess {
-		errMsg := "unknown error"
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: "unknown error", Body: c.redact(string(respBytes)), Header: resp.Header, RequestID: requestID}
 		if asgardResponse.Error != nil {
-			errMsg = *asgardResponse.Error
+			apiErr.Message = c.redact(*asgardResponse.Error)
+		}
+		if asgardResponse.ErrorCode != nil {
+			apiErr.Code = *asgardResponse.ErrorCode
 		}
-		return nil, fmt.Errorf("API error: %s", errMsg)
+		return nil, apiErr
 	}
 
 	// Return the data portion of the response
 	if asgardResponse.Data != nil {
-		return asgardResponse.Data, nil
+		return &toolResponse{Data: asgardResponse.Data, RequestID: requestID}, nil
 	}
 
 	// If no data but success is true, return the original body
-	return respBytes, nil
+	return &toolResponse{Data: respBytes, RequestID: requestID}, nil
+}
+
+// compressibleBody reports whether a JSON request body is worth gzip-encoding for the tool
+func (c *APIClient) compressibleBody(tool *Tool, payload []byte) bool {
+	return c.gzipRequestsMin > 0 && tool.AcceptGzipBody && int64(len(payload)) >= c.gzipRequestsMin
+}
+
+// gzipBytes returns the gzip encoding of data
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// AuthMode selects how the API key is presented to the backend
+type AuthMode string
+
+const (
+	// AuthModeAPIKey sends the key in the X-API-KEY header
+	AuthModeAPIKey AuthMode = "api-key"
+	// AuthModeBearer sends the key as a bearer token in the Authorization header
+	AuthModeBearer AuthMode = "bearer"
+)
+
+// WithAuthMode selects how the API key is sent to the backend, AuthModeAPIKey by default
+func WithAuthMode(mode AuthMode) APIClientOption {
+	return func(c *APIClient) {
+		c.authMode = mode
+	}
+}
+
+// setCredential presents key on the request according to the auth mode
+func (c *APIClient) setCredential(req *http.Request, key string) {
+	if c.authMode == AuthModeBearer {
+		req.Header.Set("Authorization", "Bearer "+key)
+		return
+	}
+	req.Header.Set("X-API-KEY", key)
+}
+
+// credential returns the key presented on the request according to the auth mode
+func (c *APIClient) credential(req *http.Request) string {
+	if c.authMode == AuthModeBearer {
+		return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+	return req.Header.Get("X-API-KEY")
+}
+
+// readAPIKeyFile reads an API key from a file, ignoring surrounding whitespace
+func readAPIKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", path)
+	}
+	return key, nil
+}
+
+// currentAPIKey returns the API key sent with new requests
+func (c *APIClient) currentAPIKey() string {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+	return c.apiKey
+}
+
+// apiKeys returns the current and, during a rotation, the previous API key for redaction, along with any
+// OAuth2 client secret and access token
+func (c *APIClient) apiKeys() []string {
+	c.keyMu.RLock()
+	keys := []string{c.apiKey, c.previousAPIKey}
+	c.keyMu.RUnlock()
+	if c.oauth != nil {
+		keys = append(keys, c.oauth.secrets()...)
+	}
+	return keys
+}
+
+// reloadAPIKey adopts the key in the API key file, keeping the replaced key as a fallback
+func (c *APIClient) reloadAPIKey() {
+	key, err := readAPIKeyFile(c.apiKeyFile)
+	if err != nil {
+		c.logger.Warn("Keeping the current API key", "path", c.apiKeyFile, "error", err)
+		return
+	}
+
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	if key == c.apiKey {
+		return
+	}
+	c.previousAPIKey = c.apiKey
+	c.apiKey = key
+	c.logger.Info("Adopted a new API key", "path", c.apiKeyFile)
+}
+
+// watchAPIKeyFile loads the API key file and reloads it whenever it changes
+func (c *APIClient) watchAPIKeyFile() error {
+	c.reloadAPIKey()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch API key file: %w", err)
+	}
+
+	// Watch the directory so atomic replacements and symlink swaps are seen
+	if err := watcher.Add(filepath.Dir(c.apiKeyFile)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch API key file: %w", err)
+	}
+	c.keyWatcher = watcher
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+					c.reloadAPIKey()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				c.logger.Warn("API key file watch failed", "path", c.apiKeyFile, "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// retryUnauthorized retries a request rejected with 401 using the other key of an ongoing rotation
+func (c *APIClient) retryUnauthorized(req *http.Request, resp *http.Response) (*http.Response, error) {
+	used := c.credential(req)
+	if c.apiKeyFile == "" || used == "" || resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// Pick up a key rotated since the request was built, or fall back to the previous key
+	c.reloadAPIKey()
+	c.keyMu.RLock()
+	next := c.apiKey
+	if next == used {
+		next = c.previousAPIKey
+	}
+	c.keyMu.RUnlock()
+	if next == "" || next == used {
+		return resp, nil
+	}
+
+	// A consumed body can only be replayed when the request knows how to recreate it
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	c.setCredential(retry, next)
+	_ = resp.Body.Close()
+
+	c.logger.Info("Request was unauthorized, retrying with the other rotated key", "method", req.Method, "url", redactURL(req.URL.String(), c.apiKeys()...))
+	return c.doWithRetry(retry)
+}
+
+// Close stops watching the API key file
+func (c *APIClient) Close() error {
+	if c.keyWatcher != nil {
+		return c.keyWatcher.Close()
+	}
+	return nil
+}
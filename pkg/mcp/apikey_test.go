@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyBackend accepts tool calls carrying one of its keys and records the key and body of every request
+type keyBackend struct {
+	*httptest.Server
+	mu       sync.Mutex
+	accepted map[string]bool
+	keys     []string
+	bodies   []string
+}
+
+func newKeyBackend(t *testing.T, accepted ...string) *keyBackend {
+	t.Helper()
+	b := &keyBackend{accepted: map[string]bool{}}
+	for _, key := range accepted {
+		b.accepted[key] = true
+	}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		key := r.Header.Get("X-API-KEY")
+		b.mu.Lock()
+		b.keys = append(b.keys, key)
+		b.bodies = append(b.bodies, string(body))
+		ok := b.accepted[key]
+		b.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(w, `{"isSuccess":false,"error":"invalid API key"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// received returns the keys and bodies of the requests seen so far
+func (b *keyBackend) received() ([]string, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.keys...), append([]string(nil), b.bodies...)
+}
+
+func (b *keyBackend) tool() *Tool {
+	return &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: b.URL + "/search"}}
+}
+
+// newKeyFileClient writes key to a key file and returns a client reading its key from that file
+func newKeyFileClient(t *testing.T, backend *keyBackend, key string) (*APIClient, string) {
+	t.Helper()
+	keyFile := filepath.Join(t.TempDir(), "api-key")
+	writeFile(t, keyFile, key+"\n")
+	c := newTestClient(backend.URL, WithAPIKeyFile(keyFile))
+	t.Cleanup(func() { _ = c.Close() })
+	return c, keyFile
+}
+
+func TestAPIKeyFileChangesAreAdopted(t *testing.T) {
+	backend := newKeyBackend(t, "old-key", "new-key")
+	c, keyFile := newKeyFileClient(t, backend, "old-key")
+	if got := c.currentAPIKey(); got != "old-key" {
+		t.Fatalf("currentAPIKey() = %q, want the key from the file", got)
+	}
+
+	writeFile(t, keyFile, "new-key\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for c.currentAPIKey() != "new-key" {
+		if time.Now().After(deadline) {
+			t.Fatalf("currentAPIKey() = %q, want the rewritten key adopted", c.currentAPIKey())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := backend.received(); len(keys) != 1 || keys[0] != "new-key" {
+		t.Errorf("backend received keys %q, want the new key", keys)
+	}
+}
+
+func TestAPIKeyFileKeepsKeyWhenEmptied(t *testing.T) {
+	backend := newKeyBackend(t, "old-key")
+	c, keyFile := newKeyFileClient(t, backend, "old-key")
+
+	writeFile(t, keyFile, "  \n")
+	c.reloadAPIKey()
+	if got := c.currentAPIKey(); got != "old-key" {
+		t.Errorf("currentAPIKey() = %q, want the current key kept when the file is empty", got)
+	}
+}
+
+func TestUnauthorizedRetriesWithPreviousKey(t *testing.T) {
+	// The file was rotated before the backend learned the new key
+	backend := newKeyBackend(t, "old-key")
+	c, keyFile := newKeyFileClient(t, backend, "old-key")
+	writeFile(t, keyFile, "new-key\n")
+	c.reloadAPIKey()
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{"query":"q"}`)); err != nil {
+		t.Fatalf("ExecuteToolRequest() = %v, want the retry with the previous key to succeed", err)
+	}
+	keys, bodies := backend.received()
+	if fmt.Sprint(keys) != "[new-key old-key]" {
+		t.Errorf("backend received keys %q, want the new key then the previous one", keys)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"query":"q"}` {
+		t.Errorf("backend received bodies %q, want the body replayed on the retry", bodies)
+	}
+}
+
+func TestUnauthorizedRetriesWithRotatedKey(t *testing.T) {
+	// The backend revoked the old key before the file watcher noticed the rotation
+	backend := newKeyBackend(t, "new-key")
+	c, keyFile := newKeyFileClient(t, backend, "old-key")
+	writeFile(t, keyFile, "new-key\n")
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{}`)); err != nil {
+		t.Fatalf("ExecuteToolRequest() = %v, want the rotated key picked up", err)
+	}
+	if keys, _ := backend.received(); keys[len(keys)-1] != "new-key" {
+		t.Errorf("backend received keys %q, want the last request to carry the rotated key", keys)
+	}
+}
+
+func TestUnauthorizedRetriesOnlyOnce(t *testing.T) {
+	backend := newKeyBackend(t)
+	c, keyFile := newKeyFileClient(t, backend, "old-key")
+	writeFile(t, keyFile, "new-key\n")
+	c.reloadAPIKey()
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("ExecuteToolRequest() = %v, want the 401 returned", err)
+	}
+	if keys, _ := backend.received(); len(keys) != 2 {
+		t.Errorf("backend received keys %q, want one request per key", keys)
+	}
+}
+
+func TestAuthModesSetTheirHeader(t *testing.T) {
+	tests := map[string]struct {
+		opts          []APIClientOption
+		apiKey, token string
+	}{
+		"default": {apiKey: "test-key"},
+		"api-key": {opts: []APIClientOption{WithAuthMode(AuthModeAPIKey)}, apiKey: "test-key"},
+		"bearer":  {opts: []APIClientOption{WithAuthMode(AuthModeBearer)}, token: "Bearer test-key"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var recorder headerRecorder
+			backend := httptest.NewServer(&recorder)
+			t.Cleanup(backend.Close)
+			c := newTestClient(backend.URL, tt.opts...)
+
+			if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+			if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+				t.Fatal(err)
+			}
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				header := recorder.of(method)
+				if got := header.Get("X-API-KEY"); got != tt.apiKey {
+					t.Errorf("%s request X-API-KEY = %q, want %q", method, got, tt.apiKey)
+				}
+				if got := header.Get("Authorization"); got != tt.token {
+					t.Errorf("%s request Authorization = %q, want %q", method, got, tt.token)
+				}
+			}
+		})
+	}
+}
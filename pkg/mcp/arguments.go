@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UnknownArgumentsMode controls how arguments missing from a tool's declared properties are handled
+type UnknownArgumentsMode string
+
+const (
+	// UnknownArgumentsPass sends unknown arguments to the backend unchanged
+	UnknownArgumentsPass UnknownArgumentsMode = "pass"
+	// UnknownArgumentsStrip drops unknown arguments before invocation
+	UnknownArgumentsStrip UnknownArgumentsMode = "strip"
+	// UnknownArgumentsReject fails the call with a validation error naming the unknown arguments
+	UnknownArgumentsReject UnknownArgumentsMode = "reject"
+)
+
+// ArgumentRule adapts old-style arguments of a tool to its current contract for a range of manifest generations.
+//
+// Rules are read from a JSON array, for example:
+//
+//	[{"tool": "search", "min_generation": 3, "rename": {"q": "query"}}]
+//
+// A rule applies when the manifest generation is at least MinGeneration and, if MaxGeneration is non-zero,
+// at most MaxGeneration. Each Rename entry moves the value of the old field to the new field unless the new
+// field is already present.
+type ArgumentRule struct {
+	Tool          string            `json:"tool" yaml:"tool"`
+	MinGeneration int               `json:"min_generation" yaml:"min_generation"`
+	MaxGeneration int               `json:"max_generation" yaml:"max_generation"`
+	Rename        map[string]string `json:"rename" yaml:"rename"`
+}
+
+// matches reports whether the rule applies to the tool at the given manifest generation
+func (r ArgumentRule) matches(toolName string, generation int) bool {
+	if r.Tool != toolName || generation < r.MinGeneration {
+		return false
+	}
+	return r.MaxGeneration == 0 || generation <= r.MaxGeneration
+}
+
+// LoadArgumentRules reads argument rules from a JSON file
+func LoadArgumentRules(path string) ([]ArgumentRule, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return nil, fmt.Errorf("failed to read argument rules: %w", err)
+	}
+
+	var rules []ArgumentRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse argument rules: %w", err)
+	}
+	for i, rule := range rules {
+		if rule.Tool == "" {
+			return nil, fmt.Errorf("argument rule %d has no tool", i)
+		}
+		if rule.MaxGeneration != 0 && rule.MaxGeneration < rule.MinGeneration {
+			return nil, fmt.Errorf("argument rule %d for tool %s has max_generation below min_generation", i, rule.Tool)
+		}
+	}
+
+	return rules, nil
+}
+
+// transformArguments applies the configured argument transformations for the tool before invocation
+func (s *Server) transformArguments(tool Tool, args map[string]interface{}) (map[string]interface{}, error) {
+	// Refuse files outright while uploads are disabled
+	if _, ok := args[s.uploadField]; ok && s.uploadsBlocked() {
+		return nil, errUploadsDisabled
+	}
+
+	// Work on a copy so the original request stays untouched
+	out := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+
+	// Rename fields according to generation-keyed rules
+	s.mutex.RLock()
+	generation := s.generation
+	s.mutex.RUnlock()
+	for _, rule := range s.argumentRules {
+		if !rule.matches(tool.Name, generation) {
+			continue
+		}
+		for from, to := range rule.Rename {
+			value, ok := out[from]
+			if !ok {
+				continue
+			}
+			if _, exists := out[to]; !exists {
+				out[to] = value
+				s.logger.Debug("Renamed argument", "tool", tool.Name, "from", from, "to", to, "generation", generation)
+			}
+			delete(out, from)
+		}
+	}
+
+	// Normalize keys and values
+	for _, rule := range s.normalizeRules {
+		if rule.Tool == tool.Name {
+			rule.apply(out, s.logger)
+		}
+	}
+
+	// Handle arguments the schema does not declare
+	if s.unknownArguments == UnknownArgumentsStrip || s.unknownArguments == UnknownArgumentsReject {
+		if declared := declaredProperties(tool, s.uploadField); declared != nil {
+			var unknown []string
+			for name := range out {
+				if !declared[name] {
+					unknown = append(unknown, name)
+				}
+			}
+			sort.Strings(unknown)
+			if len(unknown) > 0 && s.unknownArguments == UnknownArgumentsReject {
+				return nil, fmt.Errorf("unknown arguments: %s", strings.Join(unknown, ", "))
+			}
+			for _, name := range unknown {
+				s.logger.Info("Dropping unknown argument", "tool", tool.Name, "argument", name)
+				delete(out, name)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// declaredProperties returns the argument names the tool's schema declares along with the uploaded file paths
+// field of upload tools, or nil when the schema allows any
+func declaredProperties(tool Tool, uploadField string) map[string]bool {
+	var schema struct {
+		Properties           map[string]json.RawMessage `json:"properties"`
+		AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(tool.InputSchema, &schema); err != nil || schema.Properties == nil {
+		return nil
+	}
+
+	// Schemas explicitly allowing extra properties accept anything
+	if len(schema.AdditionalProperties) > 0 && string(schema.AdditionalProperties) != "false" {
+		return nil
+	}
+
+	declared := make(map[string]bool, len(schema.Properties)+1)
+	for name := range schema.Properties {
+		declared[name] = true
+	}
+	if tool.AllowUploadFiles {
+		declared[uploadField] = true
+	}
+	return declared
+}
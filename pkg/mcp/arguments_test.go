@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// bodyRecorder answers tool calls with "ok" and records the JSON bodies it receives
+type bodyRecorder struct {
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+}
+
+// reply records the request body and answers the call
+func (b *bodyRecorder) reply(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	data, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &body)
+	b.mu.Lock()
+	b.bodies = append(b.bodies, body)
+	b.mu.Unlock()
+	_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+}
+
+// received returns the bodies recorded so far
+func (b *bodyRecorder) received() []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]map[string]interface{}(nil), b.bodies...)
+}
+
+func TestUnknownArgumentModes(t *testing.T) {
+	const strictTool = `{"name":"search","description":"Search","invoke_endpoints":{"json":"$BACKEND/search"},` +
+		`"input_schema":{"type":"object","properties":{"query":{"type":"string"}}}}`
+	const openTool = `{"name":"search","description":"Search","invoke_endpoints":{"json":"$BACKEND/search"},` +
+		`"input_schema":{"type":"object","properties":{"query":{"type":"string"}},"additionalProperties":true}}`
+	tests := map[string]struct {
+		tool      string
+		mode      UnknownArgumentsMode
+		wantExtra bool
+		wantError string
+	}{
+		"pass":                        {tool: strictTool, mode: UnknownArgumentsPass, wantExtra: true},
+		"strip":                       {tool: strictTool, mode: UnknownArgumentsStrip},
+		"reject":                      {tool: strictTool, mode: UnknownArgumentsReject, wantError: "unknown arguments: extra"},
+		"strip keeps open schemas":    {tool: openTool, mode: UnknownArgumentsStrip, wantExtra: true},
+		"reject accepts open schemas": {tool: openTool, mode: UnknownArgumentsReject, wantExtra: true},
+		"default passes":              {tool: strictTool, wantExtra: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var recorder bodyRecorder
+			backend := newToolBackend(t, recorder.reply, tt.tool)
+			var opts []ServerOption
+			if tt.mode != "" {
+				opts = append(opts, WithUnknownArguments(tt.mode))
+			}
+			s := newToolServer(t, backend, opts...)
+
+			result := callTool(t, s, "search", map[string]interface{}{"query": "q", "extra": 1})
+			if tt.wantError != "" {
+				if !result.IsError || !strings.Contains(resultText(result), tt.wantError) {
+					t.Errorf("result = %q, want an error containing %q", resultText(result), tt.wantError)
+				}
+				if got := len(recorder.received()); got != 0 {
+					t.Errorf("backend received %d calls, want none", got)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("result = %q, want success", resultText(result))
+			}
+			bodies := recorder.received()
+			if len(bodies) != 1 {
+				t.Fatalf("backend received %d calls, want 1", len(bodies))
+			}
+			if bodies[0]["query"] != "q" {
+				t.Errorf("backend received %v, want the declared query", bodies[0])
+			}
+			if _, got := bodies[0]["extra"]; got != tt.wantExtra {
+				t.Errorf("backend received %v, want extra present %v", bodies[0], tt.wantExtra)
+			}
+		})
+	}
+}
+
+func TestArgumentRulesRenameAcrossGenerations(t *testing.T) {
+	var recorder bodyRecorder
+	var generation atomic.Int32
+	generation.Store(1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest" {
+			recorder.reply(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":%d,"tools":[`+
+			`{"name":"search","description":"Search","invoke_endpoints":{"json":"http://%s/search"}}]}}`, generation.Load(), r.Host)
+	}))
+	t.Cleanup(backend.Close)
+	s := newToolServer(t, backend, WithArgumentRules(ArgumentRule{Tool: "search", MinGeneration: 2, MaxGeneration: 3, Rename: map[string]string{"q": "query"}}))
+
+	steps := []struct {
+		generation int32
+		args       map[string]interface{}
+		want       string
+	}{
+		{generation: 1, args: map[string]interface{}{"q": "acme"}, want: "map[q:acme]"},
+		{generation: 2, args: map[string]interface{}{"q": "acme"}, want: "map[query:acme]"},
+		{generation: 2, args: map[string]interface{}{"q": "old", "query": "new"}, want: "map[query:new]"},
+		{generation: 3, args: map[string]interface{}{"q": "acme", "limit": 5}, want: "map[limit:5 query:acme]"},
+		{generation: 4, args: map[string]interface{}{"q": "acme"}, want: "map[q:acme]"},
+	}
+	for i, step := range steps {
+		generation.Store(step.generation)
+		if _, _, err := s.ReloadTools(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if result := callTool(t, s, "search", step.args); result.IsError {
+			t.Fatalf("generation %d call returned %q, want success", step.generation, resultText(result))
+		}
+		if got := fmt.Sprint(recorder.received()[i]); got != step.want {
+			t.Errorf("generation %d backend received %s, want %s", step.generation, got, step.want)
+		}
+	}
+}
+
+func TestLoadArgumentRules(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		wantErr string
+	}{
+		"valid":          {content: `[{"tool":"search","min_generation":3,"rename":{"q":"query"}}]`},
+		"missing tool":   {content: `[{"min_generation":3,"rename":{"q":"query"}}]`, wantErr: "has no tool"},
+		"inverted range": {content: `[{"tool":"search","min_generation":3,"max_generation":2}]`, wantErr: "below min_generation"},
+		"not json":       {content: `rename q to query`, wantErr: "failed to parse argument rules"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			writeFile(t, path, tt.content)
+
+			rules, err := LoadArgumentRules(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadArgumentRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(rules) != 1 || rules[0].Rename["q"] != "query" || rules[0].MinGeneration != 3 {
+				t.Errorf("LoadArgumentRules() = %+v, %v, want the search rename", rules, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxBenchmarkRate is the highest benchmark rate, one call per nanosecond, the finest interval a ticker can pace
+const MaxBenchmarkRate = float64(time.Second)
+
+// BenchmarkOptions configures a load-generation run against a single tool
+type BenchmarkOptions struct {
+	// Duration is how long the run keeps issuing calls
+	Duration time.Duration
+	// Concurrency is the number of workers issuing calls in parallel
+	Concurrency int
+	// Rate is the target number of calls per second across all workers; zero means unthrottled
+	Rate float64
+}
+
+// BenchmarkReport summarizes the outcome of a load-generation run
+type BenchmarkReport struct {
+	Tool       string
+	Calls      int
+	Errors     int
+	Elapsed    time.Duration
+	Throughput float64
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// ErrorRate returns the fraction of calls that failed
+func (r *BenchmarkReport) ErrorRate() float64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Calls)
+}
+
+// String formats the report for terminal output
+func (r *BenchmarkReport) String() string {
+	return fmt.Sprintf(
+		"tool=%s calls=%d errors=%d (%.2f%%) elapsed=%s throughput=%.2f/s p50=%s p90=%s p99=%s max=%s",
+		r.Tool, r.Calls, r.Errors, r.ErrorRate()*100, r.Elapsed.Round(time.Millisecond), r.Throughput,
+		r.P50, r.P90, r.P99, r.Max,
+	)
+}
+
+// RunBenchmark repeatedly invokes the tool through the client until the duration elapses or ctx is done; calls
+// still running when the duration elapses complete, while those running when ctx is done are canceled
+func RunBenchmark(ctx context.Context, client *APIClient, tool *Tool, input json.RawMessage, opts BenchmarkOptions) (*BenchmarkReport, error) {
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("benchmark duration must be positive")
+	}
+	if opts.Concurrency <= 0 {
+		return nil, fmt.Errorf("benchmark concurrency must be positive")
+	}
+	if opts.Rate < 0 || math.IsNaN(opts.Rate) {
+		return nil, fmt.Errorf("benchmark rate must not be negative")
+	}
+	if opts.Rate > MaxBenchmarkRate {
+		return nil, fmt.Errorf("benchmark rate must be at most %g calls per second", MaxBenchmarkRate)
+	}
+
+	// Calls outlive the run duration but not the caller's context
+	runCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	// Pace calls through a shared ticker when a rate is requested
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errCount  int
+		wg        sync.WaitGroup
+	)
+
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if tick != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				// Calls in flight when the duration ends complete so their latency is recorded
+				callStart := time.Now()
+				_, err := client.ExecuteToolRequest(runCtx, tool, input)
+				elapsed := time.Since(callStart)
+
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if err != nil {
+					errCount++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &BenchmarkReport{
+		Tool:    tool.Name,
+		Calls:   len(latencies),
+		Errors:  errCount,
+		Elapsed: time.Since(start),
+	}
+	if report.Elapsed > 0 {
+		report.Throughput = float64(report.Calls) / report.Elapsed.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 0.50)
+	report.P90 = percentile(latencies, 0.90)
+	report.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+
+	return report, nil
+}
+
+// percentile returns the value at quantile q of an ascending slice
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * q)
+	return sorted[idx]
+}
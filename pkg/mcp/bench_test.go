@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunBenchmarkRejectsRatesBeyondTickerResolution(t *testing.T) {
+	c := newTestClient("http://backend.invalid")
+	tool := &Tool{Name: "work", InvokeEndpoints: ToolInvokeEndpoints{JSON: "http://backend.invalid/work"}}
+
+	_, err := RunBenchmark(context.Background(), c, tool, []byte(`{}`), BenchmarkOptions{
+		Duration:    time.Second,
+		Concurrency: 1,
+		Rate:        2e9,
+	})
+	if err == nil {
+		t.Fatal("RunBenchmark() succeeded, want an error for a rate above one call per nanosecond")
+	}
+}
+
+func TestRunBenchmarkCancelsCallsWithContext(t *testing.T) {
+	// Calls hang until the test ends
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(backend.Close)
+	t.Cleanup(func() { close(release) })
+	c := newTestClient(backend.URL, WithTimeout(time.Minute))
+	tool := &Tool{Name: "work", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/work"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report, err := RunBenchmark(ctx, c, tool, []byte(`{}`), BenchmarkOptions{Duration: time.Minute, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("RunBenchmark() returned after %s, want the canceled context to end the calls in flight", elapsed)
+	}
+	if report.Calls != 2 || report.Errors != 2 {
+		t.Errorf("report has %d calls and %d errors, want 2 canceled calls", report.Calls, report.Errors)
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// binaryToolResult maps a raw tool response to MCP content by its content type, without parsing it as JSON
+func binaryToolResult(toolName string, data []byte, contentType string) *mcp.CallToolResult {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	summary := fmt.Sprintf("Binary response: %d bytes of %s", len(data), mediaType)
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return mcp.NewToolResultImage(summary, encoded, mediaType)
+	case strings.HasPrefix(mediaType, "audio/"):
+		return mcp.NewToolResultAudio(summary, encoded, mediaType)
+	case strings.HasPrefix(mediaType, "text/"):
+		return mcp.NewToolResultText(string(data))
+	default:
+		return mcp.NewToolResultResource(summary, mcp.BlobResourceContents{
+			URI:      RawResponseURIPrefix + toolName,
+			MIMEType: mediaType,
+			Blob:     encoded,
+		})
+	}
+}
+
+// isMediaContent reports whether a response content type is an image or audio, returned as such for any tool
+func isMediaContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "audio/")
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// wavHeader starts a RIFF WAVE file
+var wavHeader = []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+
+func TestMediaResponsesBecomeMediaContent(t *testing.T) {
+	png := append(append([]byte{}, pngHeader...), 0, 0, 0, 13, 'I', 'H', 'D', 'R')
+	tests := map[string]struct {
+		contentType string
+		body        []byte
+		check       func(t *testing.T, content mcp.Content)
+	}{
+		"png": {contentType: "image/png", body: png, check: func(t *testing.T, content mcp.Content) {
+			image, ok := content.(mcp.ImageContent)
+			if !ok || image.MIMEType != "image/png" || image.Data != base64.StdEncoding.EncodeToString(png) {
+				t.Errorf("content = %+v, want the PNG as image content", content)
+			}
+		}},
+		"wav": {contentType: "audio/wav", body: wavHeader, check: func(t *testing.T, content mcp.Content) {
+			audio, ok := content.(mcp.AudioContent)
+			if !ok || audio.MIMEType != "audio/wav" || audio.Data != base64.StdEncoding.EncodeToString(wavHeader) {
+				t.Errorf("content = %+v, want the WAV as audio content", content)
+			}
+		}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}, searchTool)
+			s := newToolServer(t, backend)
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if result.IsError || len(result.Content) != 2 {
+				t.Fatalf("result = %+v, want a summary and the media", result)
+			}
+			if _, ok := result.Content[0].(mcp.TextContent); !ok {
+				t.Errorf("first content = %+v, want a text summary", result.Content[0])
+			}
+			tt.check(t, result.Content[1])
+		})
+	}
+}
+
+func TestJSONResponsesStayText(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`, "Content-Type", "application/json"), searchTool)
+	s := newToolServer(t, backend)
+
+	result := callTool(t, s, "search", map[string]interface{}{})
+	if _, ok := result.Content[0].(mcp.TextContent); !ok || len(result.Content) != 1 {
+		t.Errorf("result = %+v, want text content only", result)
+	}
+}
+
+// zipArchive returns a zip archive holding one file
+func zipArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	f, err := archive.Create("report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("id,name\n1,alice\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBinaryToolsReturnZipsAsResources(t *testing.T) {
+	archive := zipArchive(t)
+	const flaggedTool = `{"name":"export","description":"Export","binary_output":true,"invoke_endpoints":{"json":"$BACKEND/export"}}`
+	const plainTool = `{"name":"export","description":"Export","invoke_endpoints":{"json":"$BACKEND/export"}}`
+	tests := map[string]struct {
+		tool        string
+		contentType string
+		opts        []ServerOption
+	}{
+		"manifest flag":          {tool: flaggedTool, contentType: "application/zip"},
+		"configured":             {tool: plainTool, contentType: "application/zip", opts: []ServerOption{WithBinaryOutput("export")}},
+		"sniffed without a type": {tool: flaggedTool},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var accept string
+			backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				} else {
+					w.Header()["Content-Type"] = nil
+				}
+				_, _ = w.Write(archive)
+			}, tt.tool)
+			s := newToolServer(t, backend, tt.opts...)
+
+			result := callTool(t, s, "export", map[string]interface{}{})
+			if result.IsError || len(result.Content) != 2 {
+				t.Fatalf("result = %+v, want a summary and the archive", result)
+			}
+			if accept != "*/*" {
+				t.Errorf("backend received Accept %q, want */*", accept)
+			}
+			summary, ok := result.Content[0].(mcp.TextContent)
+			if !ok || !strings.Contains(summary.Text, "application/zip") {
+				t.Errorf("first content = %+v, want a summary naming the zip", result.Content[0])
+			}
+			embedded, ok := result.Content[1].(mcp.EmbeddedResource)
+			if !ok {
+				t.Fatalf("second content = %T, want an embedded resource", result.Content[1])
+			}
+			blob, ok := embedded.Resource.(mcp.BlobResourceContents)
+			if !ok || blob.MIMEType != "application/zip" || blob.URI != RawResponseURIPrefix+"export" {
+				t.Fatalf("resource = %+v, want the zip as a blob", embedded.Resource)
+			}
+			data, err := base64.StdEncoding.DecodeString(blob.Blob)
+			if err != nil || !bytes.Equal(data, archive) {
+				t.Errorf("blob decodes to %d bytes (%v), want the %d archive bytes unchanged", len(data), err, len(archive))
+			}
+		})
+	}
+}
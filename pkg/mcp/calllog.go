@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallLogEntry records a single tool call kept for post-mortem debugging
+type CallLogEntry struct {
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	Arguments  string    `json:"arguments"`
+	Response   string    `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// callLog retains the most recent tool calls in a fixed-size ring buffer
+type callLog struct {
+	mu      sync.Mutex
+	entries []CallLogEntry
+	next    int
+	full    bool
+	bodyCap int
+	secrets func() []string
+}
+
+// newCallLog creates a ring buffer holding up to size entries with bodies capped at bodyCap bytes,
+// redacting the secrets returned by secrets
+func newCallLog(size, bodyCap int, secrets func() []string) *callLog {
+	return &callLog{
+		entries: make([]CallLogEntry, size),
+		bodyCap: bodyCap,
+		secrets: secrets,
+	}
+}
+
+// record adds a call to the buffer, evicting the oldest entry when full
+func (l *callLog) record(tool string, args, response []byte, err error, duration time.Duration) {
+	if l == nil || len(l.entries) == 0 {
+		return
+	}
+
+	entry := CallLogEntry{
+		Time:       time.Now(),
+		Tool:       tool,
+		Arguments:  l.sanitize(string(args)),
+		Response:   l.sanitize(string(response)),
+		DurationMS: duration.Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = l.sanitize(err.Error())
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// sanitize redacts secrets and caps the size of a logged body
+func (l *callLog) sanitize(s string) string {
+	s = redact(s, l.secrets()...)
+	if l.bodyCap > 0 && len(s) > l.bodyCap {
+		return fmt.Sprintf("%s...[%d bytes omitted]", s[:l.bodyCap], len(s)-l.bodyCap)
+	}
+	return s
+}
+
+// snapshot returns the retained entries from oldest to newest
+func (l *callLog) snapshot() []CallLogEntry {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]CallLogEntry(nil), l.entries[:l.next]...)
+	}
+	out := make([]CallLogEntry, 0, len(l.entries))
+	out = append(out, l.entries[l.next:]...)
+	return append(out, l.entries[:l.next]...)
+}
+
+// dump writes the retained entries to logger
+func (l *callLog) dump(logger *slog.Logger) {
+	entries := l.snapshot()
+	logger.Info("Dumping recent tool calls", "calls", len(entries))
+	for _, e := range entries {
+		logger.Info("Recent tool call", "time", e.Time, "tool", e.Tool, "duration_ms", e.DurationMS,
+			"arguments", e.Arguments, "response", e.Response, "error", e.Error)
+	}
+}
+
+// RecentCalls returns the retained recent tool calls, oldest first
+func (s *Server) RecentCalls() []CallLogEntry {
+	return s.callLog.snapshot()
+}
+
+// DebugHandler serves the retained recent tool calls as JSON for HTTP transports
+func (s *Server) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		entries := s.RecentCalls()
+		if entries == nil {
+			entries = []CallLogEntry{}
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package mcp
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// dumpCallLogOnSignal dumps the recent tool calls whenever the process receives SIGUSR1
+func (s *Server) dumpCallLogOnSignal() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	go func() {
+		for range sigCh {
+			s.callLog.dump(s.logger)
+		}
+	}()
+}
@@ -0,0 +1,6 @@
+//go:build windows
+
+package mcp
+
+// dumpCallLogOnSignal is a no-op on Windows, which has no SIGUSR1
+func (s *Server) dumpCallLogOnSignal() {}
@@ -0,0 +1,29 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"time"
+)
+
+// ToolErrorTimeout is the structured error code of calls that exceeded their deadline
+const ToolErrorTimeout = "timeout"
+
+// WithCallTimeout bounds each tool call as a whole, retries included, independently of the HTTP client timeout
+// bounding each backend request; zero disables the limit
+func WithCallTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.callTimeout = d
+	}
+}
+
+// withCallDeadline returns a context bounded by the tool's own timeout or else the call timeout, if any, along
+// with that timeout
+func (s *Server) withCallDeadline(ctx context.Context, tool Tool) (context.Context, context.CancelFunc, time.Duration) {
+	timeout := cmp.Or(tool.Timeout, s.callTimeout)
+	if timeout <= 0 {
+		return ctx, func() {}, 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, timeout
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replyAfter returns a handler answering tool calls after delay, or giving up when the client goes away
+func replyAfter(delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a client going away once the request body is consumed
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-time.After(delay):
+			_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+		case <-r.Context().Done():
+		}
+	}
+}
+
+func TestCallDeadlineFires(t *testing.T) {
+	backend := newToolBackend(t, replyAfter(5*time.Second), searchTool)
+	s := newToolServer(t, backend, WithCallTimeout(50*time.Millisecond), WithStructuredErrors(true))
+
+	start := time.Now()
+	result := callTool(t, s, "search", map[string]interface{}{})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("call took %s, want the 50ms deadline to stop it", elapsed)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "Tool call exceeded deadline of 50ms") ||
+		!strings.Contains(resultText(result), `"code":"`+ToolErrorTimeout+`"`) {
+		t.Errorf("result = %q, want a timeout error", resultText(result))
+	}
+}
+
+func TestCallsWithinTheDeadlineSucceed(t *testing.T) {
+	backend := newToolBackend(t, replyAfter(10*time.Millisecond), searchTool)
+	s := newToolServer(t, backend, WithCallTimeout(5*time.Second))
+
+	if result := callTool(t, s, "search", map[string]interface{}{}); result.IsError {
+		t.Errorf("result = %q, want success within the deadline", resultText(result))
+	}
+}
+
+func TestPerToolTimeoutsOverrideTheCallTimeout(t *testing.T) {
+	backend := newToolBackend(t, replyAfter(200*time.Millisecond),
+		`{"name":"lookup","description":"Lookup","timeout_seconds":0.05,"invoke_endpoints":{"json":"$BACKEND/lookup"}}`,
+		`{"name":"report","description":"Report","timeout_seconds":5,"invoke_endpoints":{"json":"$BACKEND/report"}}`,
+		`{"name":"plain","description":"Plain","invoke_endpoints":{"json":"$BACKEND/plain"}}`,
+	)
+	s := newToolServer(t, backend, WithCallTimeout(100*time.Millisecond))
+
+	tests := map[string]string{
+		"lookup": "Tool call exceeded deadline of 50ms",
+		"report": "",
+		"plain":  "Tool call exceeded deadline of 100ms",
+	}
+	for tool, wantErr := range tests {
+		t.Run(tool, func(t *testing.T) {
+			result := callTool(t, s, tool, map[string]interface{}{})
+			switch {
+			case wantErr == "" && result.IsError:
+				t.Errorf("result = %q, want its own longer timeout to let the call finish", resultText(result))
+			case wantErr != "" && !strings.Contains(resultText(result), wantErr):
+				t.Errorf("result = %q, want %q", resultText(result), wantErr)
+			}
+		})
+	}
+}
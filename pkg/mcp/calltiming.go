@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// callTiming is the start of an in-flight tool call as recorded by the before-call hook
+type callTiming struct {
+	start        time.Time
+	requestBytes int
+}
+
+// callTimings tracks in-flight tool calls by session and request ID so the after-call hook can report durations
+type callTimings struct {
+	calls sync.Map
+}
+
+// timingKey identifies a request; request IDs are only unique within a session
+func timingKey(ctx context.Context, id any) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return fmt.Sprintf("%s/%v", session.SessionID(), id)
+	}
+	return fmt.Sprint(id)
+}
+
+// begin records the start of a tool call
+func (t *callTimings) begin(ctx context.Context, id any, requestBytes int) {
+	t.calls.Store(timingKey(ctx, id), callTiming{start: time.Now(), requestBytes: requestBytes})
+}
+
+// finish removes and returns the timing of a tool call, reporting whether one was recorded
+func (t *callTimings) finish(ctx context.Context, id any) (callTiming, bool) {
+	timing, ok := t.calls.LoadAndDelete(timingKey(ctx, id))
+	if !ok {
+		return callTiming{}, false
+	}
+	return timing.(callTiming), true
+}
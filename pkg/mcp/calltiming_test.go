@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallDurationsAreReported(t *testing.T) {
+	delay := 20 * time.Millisecond
+	backend := newToolBackend(t, replyAfter(delay), searchTool)
+	s := newToolServer(t, backend, WithCallLog(10, DefaultCallLogBodyCap))
+
+	callTool(t, s, "search", map[string]interface{}{"query": "q"})
+	// Calls the server rejects end in the error hook rather than the after-call hook
+	s.mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing"}}`))
+
+	recorder := httptest.NewRecorder()
+	s.DebugHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DebugCallsPath, nil))
+	var entries []CallLogEntry
+	if err := json.Unmarshal(recorder.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].DurationMS < delay.Milliseconds() {
+		t.Errorf("%s = %+v, want one call lasting at least %dms", DebugCallsPath, entries, delay.Milliseconds())
+	}
+
+	inFlight := 0
+	s.callTimings.calls.Range(func(key, value any) bool {
+		inFlight++
+		return true
+	})
+	if inFlight != 0 {
+		t.Errorf("%d call timings remain after the calls finished, want none", inFlight)
+	}
+}
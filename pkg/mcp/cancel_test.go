@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stallingBackend is a fake Asgard backend serving a one-tool manifest until stall is set, after which every
+// request hangs until the client gives up, signaling entered when it arrives
+type stallingBackend struct {
+	*httptest.Server
+	stall   atomic.Bool
+	entered chan struct{}
+}
+
+func newStallingBackend(t *testing.T) *stallingBackend {
+	t.Helper()
+	b := &stallingBackend{entered: make(chan struct{}, 1)}
+	release := make(chan struct{})
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b.stall.Load() {
+			select {
+			case b.entered <- struct{}{}:
+			default:
+			}
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[`+
+			`{"name":"search","description":"Search","invoke_endpoints":{"json":"http://%s/search"}}]}}`, r.Host)
+	}))
+	t.Cleanup(b.Close)
+	t.Cleanup(func() { close(release) })
+	return b
+}
+
+// cancelOnceEntered cancels the returned context as soon as a manifest request reaches the backend
+func (b *stallingBackend) cancelOnceEntered(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		<-b.entered
+		cancel()
+	}()
+	return ctx
+}
+
+// withinSecond fails the test when f does not return within a second, returning its error otherwise
+func withinSecond(t *testing.T, f func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- f() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("canceled request did not return")
+		return nil
+	}
+}
+
+func TestFetchToolsetManifestCanceledMidFlight(t *testing.T) {
+	backend := newStallingBackend(t)
+	backend.stall.Store(true)
+	c := newTestClient(backend.URL)
+
+	ctx := backend.cancelOnceEntered(t)
+	err := withinSecond(t, func() error {
+		_, err := c.FetchToolsetManifest(ctx)
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchToolsetManifest() = %v, want context.Canceled", err)
+	}
+}
+
+func TestReloadToolsCanceledMidFlightKeepsTools(t *testing.T) {
+	backend := newStallingBackend(t)
+	s, err := NewServer(backend.URL, "test-key", WithLogger(discardLogger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.stall.Store(true)
+
+	ctx := backend.cancelOnceEntered(t)
+	err = withinSecond(t, func() error {
+		_, _, err := s.ReloadTools(ctx)
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ReloadTools() = %v, want context.Canceled", err)
+	}
+	if got := listTools(t, s); fmt.Sprint(got) != "[search]" {
+		t.Errorf("tools/list = %v, want the current tools kept", got)
+	}
+}
+
+func TestStartupCanceledMidFlight(t *testing.T) {
+	backend := newStallingBackend(t)
+	backend.stall.Store(true)
+
+	ctx := backend.cancelOnceEntered(t)
+	err := withinSecond(t, func() error {
+		_, err := NewServerWithStartupRetry(ctx, backend.URL, "test-key", time.Minute, WithLogger(discardLogger))
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("NewServerWithStartupRetry() = %v, want context.Canceled", err)
+	}
+}
+
+func TestExecuteToolRequestCanceledMidFlight(t *testing.T) {
+	backend := newStallingBackend(t)
+	backend.stall.Store(true)
+	c := newTestClient(backend.URL)
+	tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+
+	ctx := backend.cancelOnceEntered(t)
+	err := withinSecond(t, func() error {
+		_, err := c.ExecuteToolRequest(ctx, tool, []byte(`{}`))
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExecuteToolRequest() = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBackendUnavailable is returned without calling the backend while its circuit breaker is open
+var ErrBackendUnavailable = errors.New("backend unavailable")
+
+// ToolErrorBackendUnavailable is the structured error code of calls short-circuited by an open circuit breaker
+const ToolErrorBackendUnavailable = "backend_unavailable"
+
+// DefaultCircuitBreakerCooldown is how long an open circuit breaker fails calls before probing the backend again
+const DefaultCircuitBreakerCooldown = 30 * time.Second
+
+// WithCircuitBreaker fails tool calls fast once failures consecutive calls could not reach the backend or got a
+// 5xx response, for cooldown; then a single call probes the backend, closing the breaker when it succeeds and
+// opening it for another cooldown when it fails. Zero failures disables the breaker
+func WithCircuitBreaker(failures int, cooldown time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.breaker = nil
+		if failures > 0 {
+			c.breaker = &circuitBreaker{name: c.baseURL, threshold: failures, cooldown: cooldown}
+		}
+	}
+}
+
+// circuitState is the state of a circuit breaker
+type circuitState int
+
+const (
+	// circuitClosed lets every call through
+	circuitClosed circuitState = iota
+	// circuitOpen fails calls until the cooldown is over
+	circuitOpen
+	// circuitHalfOpen lets a single probe through and fails other calls until it completes
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks consecutive backend failures of one endpoint; a nil breaker allows every call
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	// openedAt is when the breaker opened or last let a probe through
+	openedAt time.Time
+}
+
+// allow returns an error wrapping ErrBackendUnavailable when the call must not reach the backend
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitClosed {
+		return nil
+	}
+	// A probe that never completes is replaced by another one after the cooldown
+	if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+		return fmt.Errorf("%w: %d consecutive calls failed, retrying in %s", ErrBackendUnavailable, b.failures, wait.Round(time.Second))
+	}
+	b.state = circuitHalfOpen
+	b.openedAt = time.Now()
+	b.logger.Info("Circuit breaker probing the endpoint", "endpoint", redactURL(b.name), "cooldown", b.cooldown)
+	return nil
+}
+
+// record counts the outcome of a backend request, opening or closing the breaker
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		if b.state != circuitClosed {
+			b.logger.Info("Circuit breaker closed, the endpoint recovered", "endpoint", redactURL(b.name))
+		}
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || (b.state == circuitClosed && b.failures >= b.threshold) {
+		b.logger.Warn("Circuit breaker opened, failing calls fast", "endpoint", redactURL(b.name), "failures", b.failures, "cooldown", b.cooldown)
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// backendFailed reports whether a backend request outcome counts against the circuit breaker: the backend could
+// not be reached or answered with a server error
+func backendFailed(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestBreaker returns a breaker opening after threshold failures for cooldown
+func newTestBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{name: "http://backend.invalid", threshold: threshold, cooldown: cooldown, logger: discardLogger}
+}
+
+// expectAllowed fails the test unless the breaker lets a call through as wanted
+func expectAllowed(t *testing.T, b *circuitBreaker, want bool, step string) {
+	t.Helper()
+	err := b.allow()
+	if want && err != nil {
+		t.Fatalf("%s: allow() = %v, want the call let through", step, err)
+	}
+	if !want && !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("%s: allow() = %v, want ErrBackendUnavailable", step, err)
+	}
+}
+
+func TestCircuitBreakerTripsCoolsDownAndRecovers(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	b := newTestBreaker(2, cooldown)
+
+	expectAllowed(t, b, true, "closed")
+	b.record(true)
+	expectAllowed(t, b, true, "one failure below the threshold")
+	b.record(true)
+	expectAllowed(t, b, false, "tripped")
+
+	time.Sleep(cooldown)
+	expectAllowed(t, b, true, "probe after the cooldown")
+	expectAllowed(t, b, false, "second call while the probe runs")
+	b.record(false)
+	expectAllowed(t, b, true, "recovered")
+
+	// A single failure after recovery does not trip the breaker again
+	b.record(true)
+	expectAllowed(t, b, true, "failure count reset by the recovery")
+}
+
+func TestCircuitBreakerReopensWhenTheProbeFails(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	b := newTestBreaker(1, cooldown)
+	b.record(true)
+	expectAllowed(t, b, false, "tripped")
+
+	time.Sleep(cooldown)
+	expectAllowed(t, b, true, "probe")
+	b.record(true)
+	expectAllowed(t, b, false, "reopened by the failed probe")
+
+	time.Sleep(cooldown)
+	expectAllowed(t, b, true, "next probe after another cooldown")
+}
+
+func TestCircuitBreakerReplacesStaleProbes(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	b := newTestBreaker(1, cooldown)
+	b.record(true)
+
+	time.Sleep(cooldown)
+	expectAllowed(t, b, true, "probe that never completes")
+	expectAllowed(t, b, false, "while the probe may still complete")
+
+	time.Sleep(cooldown)
+	expectAllowed(t, b, true, "replacement probe after a cooldown without an outcome")
+}
+
+func TestCircuitBreakerShortCircuitsToolCalls(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	backend := newFlakyBackend(t, http.StatusBadGateway, 2)
+	c := newTestClient(backend.URL, WithCircuitBreaker(2, cooldown))
+	tool := backend.tool("search")
+
+	for range 2 {
+		if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err == nil {
+			t.Fatal("ExecuteToolRequest() succeeded, want the 502")
+		}
+	}
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("ExecuteToolRequest() = %v, want ErrBackendUnavailable once tripped", err)
+	}
+	if got := backend.attemptsOf(http.MethodPost); got != 2 {
+		t.Errorf("backend received %d calls, want the short-circuited call to stay local", got)
+	}
+
+	time.Sleep(cooldown)
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+		t.Fatalf("ExecuteToolRequest() = %v, want the probe to reach the recovered backend", err)
+	}
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+		t.Errorf("ExecuteToolRequest() = %v, want calls through the closed breaker", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	backend := newFlakyBackend(t, http.StatusNotFound, 5)
+	c := newTestClient(backend.URL, WithCircuitBreaker(1, time.Hour))
+
+	for range 3 {
+		if _, err := c.ExecuteToolRequest(context.Background(), backend.tool("search"), []byte(`{}`)); errors.Is(err, ErrBackendUnavailable) {
+			t.Fatalf("ExecuteToolRequest() = %v, want 4xx responses not to trip the breaker", err)
+		}
+	}
+	if got := backend.attemptsOf(http.MethodPost); got != 3 {
+		t.Errorf("backend received %d calls, want 3", got)
+	}
+}
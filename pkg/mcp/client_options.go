@@ -0,0 +1,341 @@
+package mcp
+
+import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net"
+	"time"
+)
+
+// APIClientOption configures optional behavior of the API client
+type APIClientOption func(*APIClient)
+
+// DuplicateFileNameMode controls how uploaded files sharing a base name are named in multipart bodies
+type DuplicateFileNameMode string
+
+const (
+	// DuplicateFileNameKeep sends the base name unchanged and logs a warning on collision
+	DuplicateFileNameKeep DuplicateFileNameMode = "keep"
+	// DuplicateFileNameIndex appends an index to colliding base names (report.csv, report-2.csv)
+	DuplicateFileNameIndex DuplicateFileNameMode = "index"
+	// DuplicateFileNamePath uses each file's path relative to the common parent directory
+	DuplicateFileNamePath DuplicateFileNameMode = "path"
+)
+
+// InvalidToolsMode controls what happens to manifest tools missing required fields
+type InvalidToolsMode string
+
+const (
+	// InvalidToolsSkip drops incomplete tools with a warning
+	InvalidToolsSkip InvalidToolsMode = "skip"
+	// InvalidToolsError fails the manifest fetch on the first incomplete tool
+	InvalidToolsError InvalidToolsMode = "error"
+)
+
+// WithDuplicateFileNames sets how colliding upload file names are disambiguated
+func WithDuplicateFileNames(mode DuplicateFileNameMode) APIClientOption {
+	return func(c *APIClient) {
+		c.duplicateFileNames = mode
+	}
+}
+
+// WithTimeout bounds each backend request as a whole, including reading the response body; zero disables the limit
+func WithTimeout(d time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.client.Timeout = d
+	}
+}
+
+// WithConnectTimeout bounds how long establishing a connection to the backend may take; zero leaves it to the OS
+func WithConnectTimeout(d time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.dialer.Timeout = d
+	}
+}
+
+// WithConnectionPool sizes the pool of idle keep-alive connections reused across backend requests: maxIdle in total,
+// maxIdlePerHost per host, each closed after idling for idleTimeout. Zero maxIdle or idleTimeout is unlimited,
+// while zero maxIdlePerHost falls back to net/http's default of 2
+func WithConnectionPool(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.transport.MaxIdleConns = maxIdle
+		c.transport.MaxIdleConnsPerHost = maxIdlePerHost
+		c.transport.IdleConnTimeout = idleTimeout
+	}
+}
+
+// WithResponseHeaderTimeout bounds how long to wait for the backend's response headers after sending a request
+func WithResponseHeaderTimeout(d time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.transport.ResponseHeaderTimeout = d
+	}
+}
+
+// WithBodyReadTimeout aborts a response whose body stalls for longer than d between reads
+func WithBodyReadTimeout(d time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.bodyReadTimeout = d
+	}
+}
+
+// WithMaxConcurrentCalls bounds the number of tool calls executing at once; zero means unlimited. A server shares
+// the limit of its primary client with the clients of all its endpoints
+func WithMaxConcurrentCalls(n int) APIClientOption {
+	return func(c *APIClient) {
+		c.limiter = nil
+		if n > 0 {
+			c.limiter = newCallLimiter(n)
+		}
+	}
+}
+
+// WithToolPriorities sets per-tool priorities for the concurrency queue, overriding priority:* manifest tags
+func WithToolPriorities(priorities map[string]int) APIClientOption {
+	return func(c *APIClient) {
+		if c.toolPriorities == nil {
+			c.toolPriorities = make(map[string]int, len(priorities))
+		}
+		for name, p := range priorities {
+			c.toolPriorities[name] = p
+		}
+	}
+}
+
+// WithManifestPublicKey requires manifests to carry a valid signature made with the matching private key
+func WithManifestPublicKey(key ed25519.PublicKey) APIClientOption {
+	return func(c *APIClient) {
+		c.manifestKey = key
+	}
+}
+
+// WithRetryPolicy retries transient backend failures with exponential backoff
+func WithRetryPolicy(policy RetryPolicy) APIClientOption {
+	return func(c *APIClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRetryClassifier overrides which failed responses are retried; nil restores DefaultRetryClassifier
+func WithRetryClassifier(classifier RetryClassifier) APIClientOption {
+	return func(c *APIClient) {
+		if classifier == nil {
+			classifier = DefaultRetryClassifier
+		}
+		c.retryClassifier = classifier
+	}
+}
+
+// WithInvalidTools sets whether manifest tools missing a name or invoke endpoint are skipped or rejected
+func WithInvalidTools(mode InvalidToolsMode) APIClientOption {
+	return func(c *APIClient) {
+		c.invalidTools = mode
+	}
+}
+
+// WithMaxRedirects limits how many redirects a request follows before failing; zero refuses all redirects
+func WithMaxRedirects(n int) APIClientOption {
+	return func(c *APIClient) {
+		c.maxRedirects = n
+	}
+}
+
+// WithDNSPinning resolves backend hosts once and reuses the answer until refresh elapses, keeping the last
+// good answer while DNS lookups fail; zero disables pinning
+func WithDNSPinning(refresh time.Duration) APIClientOption {
+	return func(c *APIClient) {
+		c.dnsRefresh = refresh
+	}
+}
+
+// WithResolver sets the resolver used for DNS pinning
+func WithResolver(resolver *net.Resolver) APIClientOption {
+	return func(c *APIClient) {
+		c.resolver = resolver
+	}
+}
+
+// WithAPIKeyFile reads the API key from path and adopts updates to the file without a restart;
+// requests rejected with 401 during a rotation are retried with the other key
+func WithAPIKeyFile(path string) APIClientOption {
+	return func(c *APIClient) {
+		c.apiKeyFile = path
+	}
+}
+
+// WithRequestIDHeader sets the response header the backend's request ID is captured from; empty disables capturing
+func WithRequestIDHeader(name string) APIClientOption {
+	return func(c *APIClient) {
+		c.requestIDHeader = name
+	}
+}
+
+// WithTLSMinVersion sets the lowest TLS version accepted from backends, such as tls.VersionTLS12
+func WithTLSMinVersion(version uint16) APIClientOption {
+	return func(c *APIClient) {
+		c.tlsConfig().MinVersion = version
+	}
+}
+
+// WithCipherSuites restricts the cipher suites offered for TLS 1.2 and below; TLS 1.3 suites are not configurable
+func WithCipherSuites(suites ...uint16) APIClientOption {
+	return func(c *APIClient) {
+		c.tlsConfig().CipherSuites = suites
+	}
+}
+
+// WithProxy routes backend requests through a fixed proxy URL, or connects directly for ProxyDirect; empty keeps
+// the default of honoring HTTP_PROXY, HTTPS_PROXY, and NO_PROXY. Invalid settings are logged and ignored
+func WithProxy(proxy string) APIClientOption {
+	return func(c *APIClient) {
+		proxyFunc, err := ParseProxy(proxy)
+		if err != nil {
+			c.logger.Warn("Ignoring proxy setting", "error", err)
+			return
+		}
+		c.transport.Proxy = proxyFunc
+	}
+}
+
+// WithRootCAs verifies backend certificates against the given pool instead of the system roots
+func WithRootCAs(pool *x509.CertPool) APIClientOption {
+	return func(c *APIClient) {
+		c.tlsConfig().RootCAs = pool
+	}
+}
+
+// WithClientCertificate presents the certificate to backends requiring mutual TLS
+func WithClientCertificate(cert tls.Certificate) APIClientOption {
+	return func(c *APIClient) {
+		c.tlsConfig().Certificates = []tls.Certificate{cert}
+	}
+}
+
+// WithInsecureSkipVerify disables verification of backend certificates, exposing the API key to anyone able to
+// intercept the connection; meant for local testing only
+func WithInsecureSkipVerify() APIClientOption {
+	return func(c *APIClient) {
+		c.logger.Warn("Backend certificate verification is DISABLED, connections can be intercepted and the credentials stolen")
+		c.tlsConfig().InsecureSkipVerify = true //nolint:gosec
+	}
+}
+
+// WithUploadsDisabled makes every tool call that passes files fail instead of reading them
+func WithUploadsDisabled() APIClientOption {
+	return func(c *APIClient) {
+		c.uploadsDisabled = true
+	}
+}
+
+// WithUploadFieldName sets the argument the files to upload are read from, _uploaded_file_paths by default
+func WithUploadFieldName(name string) APIClientOption {
+	return func(c *APIClient) {
+		c.uploadField = name
+	}
+}
+
+// WithUploadRoots restricts local file uploads to paths inside the given directories once symlinks are resolved;
+// without roots any readable file may be uploaded. URL uploads are not covered, see WithUploadURLAllowlist
+func WithUploadRoots(roots ...string) APIClientOption {
+	return func(c *APIClient) {
+		c.uploadRoots = append(c.uploadRoots, roots...)
+	}
+}
+
+// WithBestEffortUploads uploads the readable files of a call and leaves out local files that cannot be opened,
+// listing them with the reason under _skipped_uploads in the JSON payload, instead of failing the whole call
+func WithBestEffortUploads(enabled bool) APIClientOption {
+	return func(c *APIClient) {
+		c.bestEffortUploads = enabled
+	}
+}
+
+// WithUploadBaseDir resolves relative local upload paths against dir instead of the working directory; absolute
+// paths are left alone, and the upload roots apply to the resolved path
+func WithUploadBaseDir(dir string) APIClientOption {
+	return func(c *APIClient) {
+		c.uploadBaseDir = dir
+	}
+}
+
+// WithUploadLimits bounds the bytes uploaded per file and per request, failing the call before the backend is
+// contacted when a file exceeds them; zero leaves a bound unlimited
+func WithUploadLimits(maxFileSize, maxTotalSize int64) APIClientOption {
+	return func(c *APIClient) {
+		c.maxUploadFileSize = maxFileSize
+		c.maxUploadTotalSize = maxTotalSize
+	}
+}
+
+// WithMaxBodySize fails manifest fetches and tool calls whose buffered response body exceeds n bytes; zero reads
+// bodies of any size. Streamed responses are bounded by the response size limit instead
+func WithMaxBodySize(n int64) APIClientOption {
+	return func(c *APIClient) {
+		c.maxBodySize = n
+	}
+}
+
+// WithGzipUploads gzip-encodes text file parts of at least minSize bytes for tools whose manifest entry sets
+// accept_gzip_uploads; zero disables compression
+func WithGzipUploads(minSize int64) APIClientOption {
+	return func(c *APIClient) {
+		c.gzipUploadsMin = minSize
+	}
+}
+
+// WithGzipRequests gzip-encodes JSON request bodies of at least minSize bytes for tools whose manifest entry sets
+// accept_gzip_body; zero disables compression
+func WithGzipRequests(minSize int64) APIClientOption {
+	return func(c *APIClient) {
+		c.gzipRequestsMin = minSize
+	}
+}
+
+// WithoutResponseCompression stops asking the backend for gzip responses, for backends whose compression costs
+// more than the bandwidth it saves
+func WithoutResponseCompression() APIClientOption {
+	return func(c *APIClient) {
+		c.transport.DisableCompression = true
+	}
+}
+
+// WithBodyTemplates wraps the arguments of the named tools in a JSON request envelope, replacing every
+// "<args>" string in the template with the arguments object
+func WithBodyTemplates(templates map[string]json.RawMessage) APIClientOption {
+	return func(c *APIClient) {
+		if c.bodyTemplates == nil {
+			c.bodyTemplates = make(map[string]json.RawMessage, len(templates))
+		}
+		for name, template := range templates {
+			c.bodyTemplates[name] = template
+		}
+	}
+}
+
+// WithRetryableTools lets calls of the named tools be retried under the retry policy, in addition to tools
+// flagged retryable in the manifest. Other tool calls make a single attempt, since retrying a POST may repeat its
+// side effects
+func WithRetryableTools(toolNames ...string) APIClientOption {
+	return func(c *APIClient) {
+		if c.retryableTools == nil {
+			c.retryableTools = make(map[string]bool, len(toolNames))
+		}
+		for _, name := range toolNames {
+			c.retryableTools[name] = true
+		}
+	}
+}
+
+// WithNonRetryableTools makes the named tools attempt each call exactly once, overriding the retry policy,
+// in addition to tools flagged non_retryable in the manifest
+func WithNonRetryableTools(toolNames ...string) APIClientOption {
+	return func(c *APIClient) {
+		if c.nonRetryableTools == nil {
+			c.nonRetryableTools = make(map[string]bool, len(toolNames))
+		}
+		for _, name := range toolNames {
+			c.nonRetryableTools[name] = true
+		}
+	}
+}
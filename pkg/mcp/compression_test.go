@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gzipEchoBackend answers the manifest and echoes every tool call's JSON body back as its data, decompressing
+// gzip request bodies and compressing responses for clients that accept gzip
+type gzipEchoBackend struct {
+	*httptest.Server
+	mu              sync.Mutex
+	acceptEncoding  []string
+	contentEncoding []string
+}
+
+func newGzipEchoBackend(t *testing.T) *gzipEchoBackend {
+	t.Helper()
+	b := &gzipEchoBackend{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		b.acceptEncoding = append(b.acceptEncoding, r.Header.Get("Accept-Encoding"))
+		b.contentEncoding = append(b.contentEncoding, r.Header.Get("Content-Encoding"))
+		b.mu.Unlock()
+
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		data, _ := io.ReadAll(body)
+		response := `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`
+		if r.Method == http.MethodPost {
+			response = `{"isSuccess":true,"data":` + string(data) + `}`
+		}
+
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = io.WriteString(w, response)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = io.WriteString(zw, response)
+		_ = zw.Close()
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// encodings returns the Accept-Encoding and Content-Encoding headers of the requests received so far
+func (b *gzipEchoBackend) encodings() ([]string, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.acceptEncoding...), append([]string(nil), b.contentEncoding...)
+}
+
+func TestGzipResponsesRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		opts       []APIClientOption
+		wantAccept string
+	}{
+		"default":         {wantAccept: "gzip"},
+		"compression off": {opts: []APIClientOption{WithoutResponseCompression()}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newGzipEchoBackend(t)
+			c := newTestClient(backend.URL, tt.opts...)
+
+			if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+				t.Fatalf("FetchToolsetManifest() error = %v", err)
+			}
+			tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+			input := fmt.Sprintf(`{"query":%q}`, strings.Repeat("acme ", 1000))
+			data, err := c.ExecuteToolRequest(context.Background(), tool, []byte(input))
+			if err != nil || string(data) != input {
+				t.Fatalf("ExecuteToolRequest() = %.40s..., %v, want the echoed arguments", data, err)
+			}
+
+			accept, _ := backend.encodings()
+			for i, got := range accept {
+				if got != tt.wantAccept {
+					t.Errorf("request %d Accept-Encoding = %q, want %q", i, got, tt.wantAccept)
+				}
+			}
+		})
+	}
+}
+
+func TestGzipRequestBodiesRoundTrip(t *testing.T) {
+	large := fmt.Sprintf(`{"rows":%q}`, strings.Repeat("a,b,c\n", 200))
+	tests := map[string]struct {
+		acceptsGzip bool
+		input       string
+		wantGzip    bool
+	}{
+		"large body":            {acceptsGzip: true, input: large, wantGzip: true},
+		"small body":            {acceptsGzip: true, input: `{"rows":"a"}`},
+		"tool without the flag": {input: large},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newGzipEchoBackend(t)
+			c := newTestClient(backend.URL, WithGzipRequests(512))
+			tool := &Tool{Name: "ingest", AcceptGzipBody: tt.acceptsGzip, InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/ingest"}}
+
+			data, err := c.ExecuteToolRequest(context.Background(), tool, []byte(tt.input))
+			if err != nil || string(data) != tt.input {
+				t.Fatalf("ExecuteToolRequest() = %.40s..., %v, want the arguments echoed intact", data, err)
+			}
+			_, content := backend.encodings()
+			if got := len(content) == 1 && content[0] == "gzip"; got != tt.wantGzip {
+				t.Errorf("request Content-Encoding = %v, want gzip %v", content, tt.wantGzip)
+			}
+		})
+	}
+}
@@ -0,0 +1,812 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every option of the MCP asgard-mcp-server as loaded from a YAML or JSON file
+type Config struct {
+	Endpoint   string   `yaml:"endpoint"`
+	APIKey     string   `yaml:"api_key"`
+	APIKeyFile string   `yaml:"api_key_file"`
+	AuthMode   AuthMode `yaml:"auth_mode"`
+	// OAuth2 replaces the API key with tokens obtained through client credentials when its token URL is set
+	OAuth2 OAuth2Config `yaml:"oauth2"`
+
+	// Endpoints are served next to the primary endpoint
+	Endpoints []Endpoint `yaml:"endpoints"`
+
+	// Serving
+	Transport  Transport `yaml:"transport"`
+	ListenAddr string    `yaml:"listen_addr"`
+
+	// ProgressInterval sends progress heartbeats during tool calls when positive
+	ProgressInterval time.Duration `yaml:"progress_interval"`
+
+	// Manifest handling
+	InvalidTools     InvalidToolsMode `yaml:"invalid_tools"`
+	StartupRetry     time.Duration    `yaml:"startup_retry"`
+	StartupAttempts  int              `yaml:"startup_attempts"`
+	AllowEmpty       bool             `yaml:"allow_empty"`
+	LogManifestOrder bool             `yaml:"log_manifest_order"`
+	ManifestRefresh  time.Duration    `yaml:"manifest_refresh"`
+	AllowTools       []string         `yaml:"allow_tools"`
+	ToolPrefix       string           `yaml:"tool_prefix"`
+	DenyTools        []string         `yaml:"deny_tools"`
+
+	// Argument handling
+	UnknownArguments  UnknownArgumentsMode `yaml:"unknown_arguments"`
+	ResourceArguments bool                 `yaml:"resource_arguments"`
+	ValidateArguments bool                 `yaml:"validate_arguments"`
+
+	// Response handling
+	CollapseSingleField []string                       `yaml:"collapse_single_field"`
+	BinaryOutput        []string                       `yaml:"binary_output"`
+	ToolTags            map[string][]string            `yaml:"tool_tags"`
+	ToolDescriptions    map[string]DescriptionOverride `yaml:"tool_descriptions"`
+	ToolPrompts         PromptMode                     `yaml:"tool_prompts"`
+	ArgumentRules       []ArgumentRule                 `yaml:"argument_rules"`
+	NormalizeRules      []NormalizeRule                `yaml:"normalize_rules"`
+	BodyTemplates       map[string]string              `yaml:"body_templates"`
+	StructuredErrors    bool                           `yaml:"structured_errors"`
+	IncludeRawData      bool                           `yaml:"include_raw_data"`
+	RawResponses        bool                           `yaml:"raw_responses"`
+	ErrorDiagnostics    bool                           `yaml:"error_diagnostics"`
+	RequestIDMeta       bool                           `yaml:"request_id_meta"`
+	MaxResponseSize     int                            `yaml:"max_response_size"`
+	MaxBodySize         int64                          `yaml:"max_body_size"`
+
+	// Logging
+	LogLevel  string    `yaml:"log_level"`
+	LogFormat LogFormat `yaml:"log_format"`
+
+	// Debugging
+	CallLogSize    int           `yaml:"call_log_size"`
+	CallLogBodyCap int           `yaml:"call_log_body_cap"`
+	MaxLogSize     int           `yaml:"max_log_size"`
+	CallSummary    bool          `yaml:"call_summary"`
+	MetricsAddr    string        `yaml:"metrics_addr"`
+	HealthAddr     string        `yaml:"health_addr"`
+	ReadyMaxAge    time.Duration `yaml:"ready_max_age"`
+
+	// Uploads
+	DuplicateFileNames DuplicateFileNameMode `yaml:"duplicate_file_names"`
+	NoUploads          UploadSafeMode        `yaml:"no_uploads"`
+	UploadPathsSchema  bool                  `yaml:"upload_paths_schema"`
+	UploadPathsField   string                `yaml:"upload_paths_field"`
+	GzipUploadsMin     int64                 `yaml:"gzip_uploads_min"`
+	GzipRequestsMin    int64                 `yaml:"gzip_requests_min"`
+	UploadRoots        []string              `yaml:"upload_roots"`
+	UploadURLAllowlist []string              `yaml:"upload_url_allowlist"`
+	UploadBaseDir      string                `yaml:"upload_base_dir"`
+	BestEffortUploads  bool                  `yaml:"best_effort_uploads"`
+	MaxUploadFileSize  int64                 `yaml:"max_upload_file_size"`
+	MaxUploadTotalSize int64                 `yaml:"max_upload_total_size"`
+
+	// Transport
+	Timeout               time.Duration     `yaml:"timeout"`
+	CallTimeout           time.Duration     `yaml:"call_timeout"`
+	ConnectTimeout        time.Duration     `yaml:"connect_timeout"`
+	MaxIdleConns          int               `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost   int               `yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout       time.Duration     `yaml:"idle_conn_timeout"`
+	ResponseHeaderTimeout time.Duration     `yaml:"response_header_timeout"`
+	BodyReadTimeout       time.Duration     `yaml:"body_read_timeout"`
+	MaxRedirects          int               `yaml:"max_redirects"`
+	DNSRefresh            time.Duration     `yaml:"dns_refresh"`
+	RequestIDHeader       string            `yaml:"request_id_header"`
+	ProxyURL              string            `yaml:"proxy_url"`
+	NoResponseCompression bool              `yaml:"no_response_compression"`
+	Headers               map[string]string `yaml:"headers"`
+
+	// Retries
+	RetryMaxAttempts int           `yaml:"retry_max_attempts"`
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay"`
+	RetryJitter      float64       `yaml:"retry_jitter"`
+	Retryable        []string      `yaml:"retryable"`
+	NonRetryable     []string      `yaml:"non_retryable"`
+
+	// Security
+	ManifestPublicKey string   `yaml:"manifest_public_key"`
+	TLSMinVersion     string   `yaml:"tls_min_version"`
+	TLSCipherSuites   []string `yaml:"tls_cipher_suites"`
+	TLSCAFile         string   `yaml:"tls_ca_file"`
+	TLSClientCert     string   `yaml:"tls_client_cert"`
+	TLSClientKey      string   `yaml:"tls_client_key"`
+	// TLSInsecureSkipVerify disables certificate verification and is meant for local testing only
+	TLSInsecureSkipVerify bool `yaml:"tls_insecure_skip_verify"`
+
+	// Concurrency
+	MaxConcurrentCalls int            `yaml:"max_concurrent_calls"`
+	ToolPriorities     map[string]int `yaml:"tool_priorities"`
+
+	// Rate limiting
+	RateLimits    map[string]string `yaml:"rate_limits"`
+	RateLimitWait time.Duration     `yaml:"rate_limit_wait"`
+
+	// Circuit breaking
+	CircuitBreakerFailures int           `yaml:"circuit_breaker_failures"`
+	CircuitBreakerCooldown time.Duration `yaml:"circuit_breaker_cooldown"`
+}
+
+// DefaultConfig returns a config populated with the default value of every option
+func DefaultConfig() Config {
+	return Config{
+		Transport:              TransportStdio,
+		ListenAddr:             DefaultListenAddr,
+		AuthMode:               AuthModeAPIKey,
+		InvalidTools:           InvalidToolsSkip,
+		AllowEmpty:             true,
+		UnknownArguments:       UnknownArgumentsPass,
+		ValidateArguments:      true,
+		ToolPrompts:            PromptModeOff,
+		DuplicateFileNames:     DuplicateFileNameKeep,
+		NoUploads:              UploadSafeModeOff,
+		UploadPathsSchema:      true,
+		UploadPathsField:       UploadedFilePathsFieldName,
+		LogLevel:               DefaultLogLevel,
+		LogFormat:              LogFormatText,
+		CallLogSize:            DefaultCallLogSize,
+		CallLogBodyCap:         DefaultCallLogBodyCap,
+		MaxLogSize:             DefaultMaxLogSize,
+		MaxResponseSize:        DefaultMaxResponseSize,
+		MaxBodySize:            DefaultMaxBodySize,
+		Timeout:                DefaultTimeout,
+		ConnectTimeout:         DefaultConnectTimeout,
+		MaxIdleConns:           DefaultMaxIdleConns,
+		MaxIdleConnsPerHost:    DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:        DefaultIdleConnTimeout,
+		MaxRedirects:           DefaultMaxRedirects,
+		RequestIDHeader:        DefaultRequestIDHeader,
+		TLSMinVersion:          "1.2",
+		RetryMaxAttempts:       1,
+		RetryBaseDelay:         DefaultRetryBaseDelay,
+		RetryMaxDelay:          DefaultRetryMaxDelay,
+		CircuitBreakerCooldown: DefaultCircuitBreakerCooldown,
+	}
+}
+
+// LoadConfig reads a YAML or JSON config file on top of the defaults, rejecting unknown keys. Files ending in
+// .json are held to JSON syntax; both formats share the same keys and write durations as strings such as "30s"
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if data, err = jsonToYAML(data); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	// One strict decoder maps both formats onto the config
+	cfg := DefaultConfig()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// jsonToYAML parses a JSON document and re-encodes it as YAML, so JSON config files are parsed as JSON while
+// sharing the key names, unknown key checks, and duration parsing of YAML files
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return yaml.Marshal(jsonNumbers(doc))
+}
+
+// jsonNumbers replaces the json.Number values of a decoded JSON document with integers where they fit and floats
+// otherwise
+func jsonNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonNumbers(value)
+		}
+	}
+	return v
+}
+
+// Validate checks types, ranges, and URL formats, returning every problem found at once
+func (c *Config) Validate() error {
+	var errs []error
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	// Endpoint and credentials
+	if c.Endpoint == "" {
+		addErr("endpoint", "is required")
+	} else if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		addErr("endpoint", "must be an absolute http or https URL, got %q", c.Endpoint)
+	}
+	if c.APIKey == "" && c.APIKeyFile == "" && !c.OAuth2.Enabled() {
+		addErr("api_key", "is required unless api_key_file or oauth2 is set")
+	}
+	checkOAuth2 := func(field string, o OAuth2Config) {
+		if o.Enabled() {
+			if u, err := url.Parse(o.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				addErr(field+".token_url", "must be an absolute http or https URL, got %q", o.TokenURL)
+			}
+			if o.ClientID == "" {
+				addErr(field+".client_id", "is required with %s.token_url", field)
+			}
+		} else if o.ClientID != "" || o.ClientSecret != "" || len(o.Scopes) > 0 {
+			addErr(field+".token_url", "is required with the other %s settings", field)
+		}
+	}
+	checkOAuth2("oauth2", c.OAuth2)
+	if c.APIKeyFile != "" {
+		if _, err := readAPIKeyFile(c.APIKeyFile); err != nil {
+			addErr("api_key_file", "%v", err)
+		}
+	}
+	prefixes := map[string]bool{c.ToolPrefix: true}
+	for i, e := range c.Endpoints {
+		field := fmt.Sprintf("endpoints[%d]", i)
+		if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addErr(field+".url", "must be an absolute http or https URL, got %q", e.URL)
+		}
+		// Endpoints never inherit the primary endpoint's credentials
+		if e.APIKey == "" && e.APIKeyFile == "" && !e.OAuth2.Enabled() {
+			addErr(field+".api_key", "is required unless api_key_file or oauth2 is set")
+		}
+		checkOAuth2(field+".oauth2", e.OAuth2)
+		switch e.AuthMode {
+		case "", AuthModeAPIKey, AuthModeBearer:
+		default:
+			addErr(field+".auth_mode", "must be one of api-key, bearer, got %q", e.AuthMode)
+		}
+		for name, value := range e.Headers {
+			if err := validateHeader(name, value); err != nil {
+				addErr(fmt.Sprintf("%s.headers[%s]", field, name), "%v", err)
+			}
+		}
+		if e.APIKeyFile != "" {
+			if _, err := readAPIKeyFile(e.APIKeyFile); err != nil {
+				addErr(field+".api_key_file", "%v", err)
+			}
+		}
+		if _, err := ParseProxy(e.Proxy); err != nil {
+			addErr(field+".proxy", "%v", err)
+		}
+		if prefixes[e.Prefix] {
+			addErr(field+".prefix", "must differ from the prefixes of the other endpoints, got %q", e.Prefix)
+		}
+		prefixes[e.Prefix] = true
+	}
+
+	// Enumerations
+	switch c.Transport {
+	case TransportStdio, TransportSSE, TransportStreamableHTTP:
+	default:
+		addErr("transport", "must be one of stdio, sse, streamable-http, got %q", c.Transport)
+	}
+	switch c.AuthMode {
+	case AuthModeAPIKey, AuthModeBearer:
+	default:
+		addErr("auth_mode", "must be one of api-key, bearer, got %q", c.AuthMode)
+	}
+	switch c.ToolPrompts {
+	case PromptModeOff, PromptModeBoth, PromptModeOnly:
+	default:
+		addErr("tool_prompts", "must be one of off, both, only, got %q", c.ToolPrompts)
+	}
+	switch c.InvalidTools {
+	case InvalidToolsSkip, InvalidToolsError:
+	default:
+		addErr("invalid_tools", "must be one of skip, error, got %q", c.InvalidTools)
+	}
+	switch c.UnknownArguments {
+	case UnknownArgumentsPass, UnknownArgumentsStrip, UnknownArgumentsReject:
+	default:
+		addErr("unknown_arguments", "must be one of pass, strip, reject, got %q", c.UnknownArguments)
+	}
+	switch c.DuplicateFileNames {
+	case DuplicateFileNameKeep, DuplicateFileNameIndex, DuplicateFileNamePath:
+	default:
+		addErr("duplicate_file_names", "must be one of keep, index, path, got %q", c.DuplicateFileNames)
+	}
+
+	if c.TLSMinVersion != "" {
+		if _, err := ParseTLSVersion(c.TLSMinVersion); err != nil {
+			addErr("tls_min_version", "%v", err)
+		}
+	}
+	if _, err := ParseCipherSuites(c.TLSCipherSuites); err != nil {
+		addErr("tls_cipher_suites", "%v", err)
+	}
+	if _, err := ParseProxy(c.ProxyURL); err != nil {
+		addErr("proxy_url", "%v", err)
+	}
+	if c.TLSCAFile != "" {
+		if _, err := LoadCABundle(c.TLSCAFile); err != nil {
+			addErr("tls_ca_file", "%v", err)
+		}
+	}
+	if (c.TLSClientCert == "") != (c.TLSClientKey == "") {
+		addErr("tls_client_cert/tls_client_key", "must be set together")
+	} else if c.TLSClientCert != "" {
+		if _, err := LoadClientCertificate(c.TLSClientCert, c.TLSClientKey); err != nil {
+			addErr("tls_client_cert/tls_client_key", "%v", err)
+		}
+	}
+	if _, err := ParseLogLevel(c.LogLevel); err != nil {
+		addErr("log_level", "%v", err)
+	}
+	switch c.LogFormat {
+	case LogFormatText, LogFormatJSON:
+	default:
+		addErr("log_format", "must be one of text, json, got %q", c.LogFormat)
+	}
+	switch c.NoUploads {
+	case UploadSafeModeOff, UploadSafeModeSkip, UploadSafeModeReject:
+	default:
+		addErr("no_uploads", "must be one of off, skip, reject, got %q", c.NoUploads)
+	}
+	if c.UploadPathsField == "" {
+		addErr("upload_paths_field", "must not be empty")
+	}
+
+	// Ranges
+	if c.GzipUploadsMin < 0 {
+		addErr("gzip_uploads_min", "must not be negative")
+	}
+	if c.GzipRequestsMin < 0 {
+		addErr("gzip_requests_min", "must not be negative")
+	}
+	if c.MaxUploadFileSize < 0 {
+		addErr("max_upload_file_size", "must not be negative")
+	}
+	if c.MaxUploadTotalSize < 0 {
+		addErr("max_upload_total_size", "must not be negative")
+	}
+	if c.StartupRetry < 0 {
+		addErr("startup_retry", "must not be negative")
+	}
+	if c.StartupAttempts < 0 {
+		addErr("startup_attempts", "must not be negative")
+	}
+	if c.ReadyMaxAge < 0 {
+		addErr("ready_max_age", "must not be negative")
+	}
+	if c.ProgressInterval < 0 {
+		addErr("progress_interval", "must not be negative")
+	}
+	if c.ManifestRefresh < 0 {
+		addErr("manifest_refresh", "must not be negative")
+	}
+	if c.Timeout < 0 {
+		addErr("timeout", "must not be negative")
+	}
+	if c.CallTimeout < 0 {
+		addErr("call_timeout", "must not be negative")
+	}
+	if c.ConnectTimeout < 0 {
+		addErr("connect_timeout", "must not be negative")
+	}
+	if c.MaxIdleConns < 0 {
+		addErr("max_idle_conns", "must not be negative")
+	}
+	if c.MaxIdleConnsPerHost < 0 {
+		addErr("max_idle_conns_per_host", "must not be negative")
+	}
+	if c.IdleConnTimeout < 0 {
+		addErr("idle_conn_timeout", "must not be negative")
+	}
+	if c.ResponseHeaderTimeout < 0 {
+		addErr("response_header_timeout", "must not be negative")
+	}
+	if c.BodyReadTimeout < 0 {
+		addErr("body_read_timeout", "must not be negative")
+	}
+	if c.DNSRefresh < 0 {
+		addErr("dns_refresh", "must not be negative")
+	}
+	if c.MaxRedirects < 0 {
+		addErr("max_redirects", "must not be negative")
+	}
+	if c.RetryMaxAttempts < 0 {
+		addErr("retry_max_attempts", "must not be negative")
+	}
+	if c.RetryBaseDelay < 0 {
+		addErr("retry_base_delay", "must not be negative")
+	}
+	if c.RetryMaxDelay < 0 {
+		addErr("retry_max_delay", "must not be negative")
+	}
+	if c.RetryJitter < 0 || c.RetryJitter > 1 {
+		addErr("retry_jitter", "must be between 0 and 1")
+	}
+	if c.MaxConcurrentCalls < 0 {
+		addErr("max_concurrent_calls", "must not be negative")
+	}
+	if c.RateLimitWait < 0 {
+		addErr("rate_limit_wait", "must not be negative")
+	}
+	if c.CircuitBreakerFailures < 0 {
+		addErr("circuit_breaker_failures", "must not be negative")
+	}
+	if c.CircuitBreakerCooldown < 0 {
+		addErr("circuit_breaker_cooldown", "must not be negative")
+	}
+	if c.CallLogSize < 0 {
+		addErr("call_log_size", "must not be negative")
+	}
+	if c.MaxResponseSize < 0 {
+		addErr("max_response_size", "must not be negative")
+	}
+	if c.MaxBodySize < 0 {
+		addErr("max_body_size", "must not be negative")
+	}
+	if c.MaxLogSize < 0 {
+		addErr("max_log_size", "must not be negative")
+	}
+	if c.CallLogBodyCap < 0 {
+		addErr("call_log_body_cap", "must not be negative")
+	}
+	for i, entry := range c.UploadURLAllowlist {
+		if err := ValidateUploadURLAllowlistEntry(entry); err != nil {
+			addErr(fmt.Sprintf("upload_url_allowlist[%d]", i), "%v", err)
+		}
+	}
+
+	// Referenced files
+	for i, root := range c.UploadRoots {
+		if info, err := os.Stat(root); err != nil {
+			addErr(fmt.Sprintf("upload_roots[%d]", i), "%v", err)
+		} else if !info.IsDir() {
+			addErr(fmt.Sprintf("upload_roots[%d]", i), "%s is not a directory", root)
+		}
+	}
+	if c.UploadBaseDir != "" {
+		if info, err := os.Stat(c.UploadBaseDir); err != nil {
+			addErr("upload_base_dir", "%v", err)
+		} else if !info.IsDir() {
+			addErr("upload_base_dir", "%s is not a directory", c.UploadBaseDir)
+		}
+	}
+	if c.ManifestPublicKey != "" {
+		if _, err := LoadManifestPublicKey(c.ManifestPublicKey); err != nil {
+			addErr("manifest_public_key", "%v", err)
+		}
+	}
+
+	// Per-tool settings
+	if err := (ToolFilter{Allow: c.AllowTools, Deny: c.DenyTools}).Validate(); err != nil {
+		addErr("allow_tools/deny_tools", "%v", err)
+	}
+	for i, rule := range c.ArgumentRules {
+		if rule.Tool == "" {
+			addErr(fmt.Sprintf("argument_rules[%d]", i), "tool is required")
+		}
+		if rule.MaxGeneration != 0 && rule.MaxGeneration < rule.MinGeneration {
+			addErr(fmt.Sprintf("argument_rules[%d]", i), "max_generation must not be below min_generation")
+		}
+	}
+	for name, template := range c.BodyTemplates {
+		if _, err := ParseBodyTemplate(template); err != nil {
+			addErr(fmt.Sprintf("body_templates[%s]", name), "%v", err)
+		}
+	}
+	for name, override := range c.ToolDescriptions {
+		if err := override.Validate(); err != nil {
+			addErr(fmt.Sprintf("tool_descriptions[%s]", name), "%v", err)
+		}
+	}
+	for name, value := range c.Headers {
+		if err := validateHeader(name, value); err != nil {
+			addErr(fmt.Sprintf("headers[%s]", name), "%v", err)
+		}
+	}
+	for name, limit := range c.RateLimits {
+		if _, err := ParseRateLimit(limit); err != nil {
+			addErr("rate_limits."+name, "%v", err)
+		}
+	}
+	for i, rule := range c.NormalizeRules {
+		if rule.Tool == "" {
+			addErr(fmt.Sprintf("normalize_rules[%d]", i), "tool is required")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateTools checks that every tool referenced by per-tool settings exists in the given tools by manifest name
+func (c *Config) ValidateTools(tools []Tool) error {
+	known := make(map[string]bool, len(tools))
+	exposed := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		known[tool.Name] = true
+		if tool.exposed != "" {
+			exposed[tool.exposed] = tool.Name
+		}
+	}
+
+	var errs []error
+	check := func(field, name string) {
+		switch {
+		case known[name]:
+		case exposed[name] != "":
+			errs = append(errs, fmt.Errorf("%s: tool %q is the prefixed name clients see, per-tool settings use the manifest name %q",
+				field, name, exposed[name]))
+		default:
+			errs = append(errs, fmt.Errorf("%s: tool %q does not exist in the toolset", field, name))
+		}
+	}
+	for _, name := range c.CollapseSingleField {
+		check("collapse_single_field", name)
+	}
+	for _, name := range c.BinaryOutput {
+		check("binary_output", name)
+	}
+	for name := range c.ToolTags {
+		check("tool_tags", name)
+	}
+	for name := range c.ToolDescriptions {
+		check("tool_descriptions", name)
+	}
+	for name := range c.ToolPriorities {
+		check("tool_priorities", name)
+	}
+	for name := range c.RateLimits {
+		check("rate_limits", name)
+	}
+	for i, rule := range c.ArgumentRules {
+		check(fmt.Sprintf("argument_rules[%d]", i), rule.Tool)
+	}
+	for name := range c.BodyTemplates {
+		check("body_templates", name)
+	}
+	for _, name := range c.Retryable {
+		check("retryable", name)
+	}
+	for _, name := range c.NonRetryable {
+		check("non_retryable", name)
+	}
+	for i, rule := range c.NormalizeRules {
+		check(fmt.Sprintf("normalize_rules[%d]", i), rule.Tool)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckTools fetches the manifest of every endpoint and checks the tools the config references against their
+// merged tools, failing when any manifest cannot be fetched
+func (c *Config) CheckTools() error {
+	opts, err := c.ServerOptions()
+	if err != nil {
+		return err
+	}
+	s := newServer(c.Endpoint, c.APIKey, opts...)
+	defer s.closeEndpoints()
+
+	tools, _, err := s.fetchTools(context.Background(), nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch toolset manifest: %w", err)
+	}
+	if err := s.fetchErrors(); err != nil {
+		return err
+	}
+	return c.ValidateTools(tools)
+}
+
+// ServerOptions converts the config into options for NewServer, loading any referenced files
+func (c *Config) ServerOptions() ([]ServerOption, error) {
+	clientOpts, err := c.APIClientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []ServerOption{
+		WithTransport(c.Transport, c.ListenAddr),
+		WithToolPrompts(c.ToolPrompts),
+		WithUnknownArguments(c.UnknownArguments),
+		WithResourceArguments(c.ResourceArguments),
+		WithArgumentValidation(c.ValidateArguments),
+		WithAllowEmptyManifest(c.AllowEmpty),
+		WithLogManifestOrder(c.LogManifestOrder),
+		WithManifestRefresh(c.ManifestRefresh),
+		WithProgressHeartbeat(c.ProgressInterval),
+		WithCallTimeout(c.CallTimeout),
+		WithToolFilter(ToolFilter{Allow: c.AllowTools, Deny: c.DenyTools}),
+		WithToolPrefix(c.ToolPrefix),
+		WithEndpoints(c.Endpoints...),
+		WithNoUploads(c.NoUploads),
+		WithUploadPathsSchema(c.UploadPathsSchema),
+		WithUploadPathsField(c.UploadPathsField),
+		WithStructuredErrors(c.StructuredErrors),
+		WithRawData(c.IncludeRawData),
+		WithRawResponses(c.RawResponses),
+		WithErrorDiagnostics(c.ErrorDiagnostics),
+		WithRequestIDMeta(c.RequestIDMeta),
+		WithCallLog(c.CallLogSize, c.CallLogBodyCap),
+		WithResponseLimits(c.MaxResponseSize, c.MaxLogSize),
+		WithCallSummary(c.CallSummary),
+		WithMetrics(c.MetricsAddr),
+		WithHealthCheck(c.HealthAddr, c.ReadyMaxAge),
+		WithAPIClientOptions(clientOpts...),
+	}
+	if len(c.CollapseSingleField) > 0 {
+		opts = append(opts, WithCollapseSingleField(c.CollapseSingleField...))
+	}
+	if len(c.BinaryOutput) > 0 {
+		opts = append(opts, WithBinaryOutput(c.BinaryOutput...))
+	}
+	if len(c.ToolTags) > 0 {
+		opts = append(opts, WithToolTags(c.ToolTags))
+	}
+	if len(c.ToolDescriptions) > 0 {
+		opts = append(opts, WithDescriptionOverrides(c.ToolDescriptions))
+	}
+	if len(c.RateLimits) > 0 {
+		limits := make(map[string]RateLimit, len(c.RateLimits))
+		for name, value := range c.RateLimits {
+			limit, err := ParseRateLimit(value)
+			if err != nil {
+				return nil, err
+			}
+			limits[name] = limit
+		}
+		opts = append(opts, WithRateLimits(limits, c.RateLimitWait))
+	}
+	if len(c.ArgumentRules) > 0 {
+		opts = append(opts, WithArgumentRules(c.ArgumentRules...))
+	}
+	if len(c.NormalizeRules) > 0 {
+		opts = append(opts, WithNormalizeRules(c.NormalizeRules...))
+	}
+	return opts, nil
+}
+
+// NewServerFromConfig validates the config and creates a server with every option it holds, retrying
+// initialization for up to StartupRetry and StartupAttempts
+func NewServerFromConfig(cfg Config) (*Server, error) {
+	return NewServerFromConfigWithContext(context.Background(), cfg)
+}
+
+// NewServerFromConfigWithContext is NewServerFromConfig with the options in extra applied after those of the
+// config; canceling ctx stops retrying initialization and aborts the running attempt
+func NewServerFromConfigWithContext(ctx context.Context, cfg Config, extra ...ServerOption) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	opts, err := cfg.ServerOptions()
+	if err != nil {
+		return nil, err
+	}
+	return NewServerWithStartupAttempts(ctx, cfg.Endpoint, cfg.APIKey, cfg.StartupRetry, cfg.StartupAttempts, append(opts, extra...)...)
+}
+
+// APIClientOptions converts the config into options for NewAPIClientWithOptions, loading any referenced files
+func (c *Config) APIClientOptions() ([]APIClientOption, error) {
+	opts := []APIClientOption{
+		WithAuthMode(c.AuthMode),
+		WithOAuth2(c.OAuth2),
+		WithInvalidTools(c.InvalidTools),
+		WithDuplicateFileNames(c.DuplicateFileNames),
+		WithGzipUploads(c.GzipUploadsMin),
+		WithGzipRequests(c.GzipRequestsMin),
+		WithUploadRoots(c.UploadRoots...),
+		WithUploadURLAllowlist(c.UploadURLAllowlist...),
+		WithUploadBaseDir(c.UploadBaseDir),
+		WithBestEffortUploads(c.BestEffortUploads),
+		WithUploadLimits(c.MaxUploadFileSize, c.MaxUploadTotalSize),
+		WithMaxBodySize(c.MaxBodySize),
+		WithTimeout(c.Timeout),
+		WithConnectTimeout(c.ConnectTimeout),
+		WithConnectionPool(c.MaxIdleConns, c.MaxIdleConnsPerHost, c.IdleConnTimeout),
+		WithResponseHeaderTimeout(c.ResponseHeaderTimeout),
+		WithBodyReadTimeout(c.BodyReadTimeout),
+		WithMaxRedirects(c.MaxRedirects),
+		WithDNSPinning(c.DNSRefresh),
+		WithRequestIDHeader(c.RequestIDHeader),
+		WithExtraHeaders(c.Headers),
+		WithProxy(c.ProxyURL),
+		WithMaxConcurrentCalls(c.MaxConcurrentCalls),
+		WithCircuitBreaker(c.CircuitBreakerFailures, c.CircuitBreakerCooldown),
+		WithToolPriorities(c.ToolPriorities),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts: c.RetryMaxAttempts,
+			BaseDelay:   c.RetryBaseDelay,
+			MaxDelay:    c.RetryMaxDelay,
+			Jitter:      c.RetryJitter,
+		}),
+	}
+	if c.TLSMinVersion != "" {
+		version, err := ParseTLSVersion(c.TLSMinVersion)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLSMinVersion(version))
+	}
+	if len(c.TLSCipherSuites) > 0 {
+		suites, err := ParseCipherSuites(c.TLSCipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithCipherSuites(suites...))
+	}
+	if c.TLSCAFile != "" {
+		pool, err := LoadCABundle(c.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRootCAs(pool))
+	}
+	if c.TLSClientCert != "" {
+		cert, err := LoadClientCertificate(c.TLSClientCert, c.TLSClientKey)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithClientCertificate(cert))
+	}
+	if c.NoResponseCompression {
+		opts = append(opts, WithoutResponseCompression())
+	}
+	if c.TLSInsecureSkipVerify {
+		opts = append(opts, WithInsecureSkipVerify())
+	}
+	if len(c.Retryable) > 0 {
+		opts = append(opts, WithRetryableTools(c.Retryable...))
+	}
+	if len(c.NonRetryable) > 0 {
+		opts = append(opts, WithNonRetryableTools(c.NonRetryable...))
+	}
+	if len(c.BodyTemplates) > 0 {
+		templates := make(map[string]json.RawMessage, len(c.BodyTemplates))
+		for name, template := range c.BodyTemplates {
+			parsed, err := ParseBodyTemplate(template)
+			if err != nil {
+				return nil, fmt.Errorf("body template for tool %s: %w", name, err)
+			}
+			templates[name] = parsed
+		}
+		opts = append(opts, WithBodyTemplates(templates))
+	}
+	if c.APIKeyFile != "" {
+		opts = append(opts, WithAPIKeyFile(c.APIKeyFile))
+	}
+	if c.ManifestPublicKey != "" {
+		key, err := LoadManifestPublicKey(c.ManifestPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithManifestPublicKey(key))
+	}
+	return opts, nil
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkToolsConfig returns a config serving primary and, with the b_ prefix, extra
+func checkToolsConfig(primary, extra string) Config {
+	cfg := DefaultConfig()
+	cfg.Endpoint = primary
+	cfg.APIKey = "test-key"
+	cfg.Endpoints = []Endpoint{{URL: extra, APIKey: "other-key", Prefix: "b_"}}
+	return cfg
+}
+
+func TestCheckToolsUsesEveryEndpoint(t *testing.T) {
+	primary := newManifestBackend(t, `{"name":"search","description":"Search","invoke_endpoints":{"json":"http://backend.invalid/search"}}`)
+	extra := newManifestBackend(t, `{"name":"export","description":"Export","invoke_endpoints":{"json":"http://backend.invalid/export"}}`)
+
+	cfg := checkToolsConfig(primary.URL, extra.URL)
+	cfg.CollapseSingleField = []string{"search", "export"}
+	if err := cfg.CheckTools(); err != nil {
+		t.Errorf("CheckTools() = %v, want tools of both endpoints to be known", err)
+	}
+
+	cfg.CollapseSingleField = []string{"b_export", "missing"}
+	err := cfg.CheckTools()
+	if err == nil || !strings.Contains(err.Error(), `manifest name "export"`) || !strings.Contains(err.Error(), `"missing" does not exist`) {
+		t.Errorf("CheckTools() = %v, want errors for the prefixed and the unknown name", err)
+	}
+}
+
+func TestCheckToolsFailsWhenAnEndpointIsDown(t *testing.T) {
+	primary := newManifestBackend(t, `{"name":"search","description":"Search","invoke_endpoints":{"json":"http://backend.invalid/search"}}`)
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+
+	cfg := checkToolsConfig(primary.URL, down.URL)
+	cfg.CollapseSingleField = []string{"search"}
+	if err := cfg.CheckTools(); err == nil || !strings.Contains(err.Error(), down.URL) {
+		t.Errorf("CheckTools() = %v, want an error naming %s", err, down.URL)
+	}
+}
+
+// sampleConfigYAML and sampleConfigJSON describe the same config in both formats
+const (
+	sampleConfigYAML = `
+endpoint: https://asgard.example.com/manifest
+api_key_file: /run/secrets/asgard
+transport: streamable-http
+timeout: 45s
+manifest_refresh: 5m
+retry_max_attempts: 3
+retry_jitter: 0.2
+max_upload_total_size: 10000000000
+allow_tools: [crm_*, search]
+headers:
+  X-Tenant-ID: acme
+tool_priorities:
+  search: 1
+tool_descriptions:
+  search:
+    description: Search the CRM
+    mode: append
+endpoints:
+  - url: https://billing.example.com/manifest
+    api_key: billing-key
+    prefix: billing_
+`
+	sampleConfigJSON = `{
+  "endpoint": "https://asgard.example.com/manifest",
+  "api_key_file": "/run/secrets/asgard",
+  "transport": "streamable-http",
+  "timeout": "45s",
+  "manifest_refresh": "5m",
+  "retry_max_attempts": 3,
+  "retry_jitter": 0.2,
+  "max_upload_total_size": 10000000000,
+  "allow_tools": ["crm_*", "search"],
+  "headers": {"X-Tenant-ID": "acme"},
+  "tool_priorities": {"search": 1},
+  "tool_descriptions": {"search": {"description": "Search the CRM", "mode": "append"}},
+  "endpoints": [{"url": "https://billing.example.com/manifest", "api_key": "billing-key", "prefix": "billing_"}]
+}`
+)
+
+// loadConfigText writes a config file with the given name and content and loads it
+func loadConfigText(t *testing.T, name, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeFile(t, path, content)
+	return LoadConfig(path)
+}
+
+// marshalConfig returns the YAML encoding of a config
+func marshalConfig(t *testing.T, cfg *Config) string {
+	t.Helper()
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	cfg, err := loadConfigText(t, "config.yaml", sampleConfigYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 45*time.Second || cfg.ManifestRefresh != 5*time.Minute || cfg.MaxUploadTotalSize != 10000000000 || len(cfg.Endpoints) != 1 || cfg.MaxRedirects != DefaultMaxRedirects {
+		t.Fatalf("LoadConfig() = %+v, want the file's values on top of the defaults", cfg)
+	}
+
+	written := marshalConfig(t, cfg)
+	reloaded, err := loadConfigText(t, "written.yaml", written)
+	if err != nil {
+		t.Fatalf("LoadConfig() of the written config = %v", err)
+	}
+	if got := marshalConfig(t, reloaded); got != written {
+		t.Errorf("config changed across a round trip:\n%s\nwant:\n%s", got, written)
+	}
+
+	fromJSON, err := loadConfigText(t, "config.json", sampleConfigJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := marshalConfig(t, fromJSON); got != written {
+		t.Errorf("JSON config differs from the YAML one:\n%s\nwant:\n%s", got, written)
+	}
+}
+
+func TestLoadConfigHoldsJSONFilesToJSON(t *testing.T) {
+	tests := map[string]string{
+		"yaml syntax":   "endpoint: https://asgard.example.com/manifest\n",
+		"trailing data": `{"endpoint": "https://asgard.example.com/manifest"} {}`,
+		"unknown key":   `{"endpont": "https://asgard.example.com/manifest"}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadConfigText(t, "config.json", content); err == nil {
+				t.Error("LoadConfig() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DescriptionMode controls how a local description override combines with the manifest description
+type DescriptionMode string
+
+const (
+	// DescriptionReplace uses the local description instead of the manifest description
+	DescriptionReplace DescriptionMode = "replace"
+	// DescriptionAppend adds the local description after the manifest description as extra guidance
+	DescriptionAppend DescriptionMode = "append"
+)
+
+// DescriptionOverride adjusts how a tool is described to clients without changing the manifest
+type DescriptionOverride struct {
+	Description string          `yaml:"description"`
+	Mode        DescriptionMode `yaml:"mode"`
+	// Title replaces the tool's title when set
+	Title string `yaml:"title"`
+}
+
+// Validate checks that the override has a known mode and changes something
+func (o DescriptionOverride) Validate() error {
+	switch o.Mode {
+	case "", DescriptionReplace, DescriptionAppend:
+	default:
+		return fmt.Errorf("mode must be one of replace, append, got %q", o.Mode)
+	}
+	if o.Description == "" && o.Title == "" {
+		return fmt.Errorf("description or title is required")
+	}
+	return nil
+}
+
+// apply returns the tool with its description and title overridden
+func (o DescriptionOverride) apply(tool Tool) Tool {
+	switch {
+	case o.Description == "":
+	case o.Mode == DescriptionAppend && strings.TrimSpace(tool.Description) != "":
+		tool.Description = strings.TrimRight(tool.Description, "\n") + "\n\n" + o.Description
+	default:
+		tool.Description = o.Description
+	}
+	if o.Title != "" {
+		tool.Title = o.Title
+	}
+	return tool
+}
+
+// WithDescriptionOverrides replaces or extends the descriptions and titles of tools by manifest name
+func WithDescriptionOverrides(overrides map[string]DescriptionOverride) ServerOption {
+	return func(s *Server) {
+		if s.descriptionOverrides == nil {
+			s.descriptionOverrides = make(map[string]DescriptionOverride, len(overrides))
+		}
+		for name, o := range overrides {
+			s.descriptionOverrides[name] = o
+		}
+	}
+}
+
+// overrideDescription applies the local description override of the tool, if any
+func (s *Server) overrideDescription(tool Tool) Tool {
+	o, ok := s.descriptionOverrides[tool.Name]
+	if !ok {
+		return tool
+	}
+	return o.apply(tool)
+}
+
+// logDescriptionOverrides logs once which tools have their description overridden, warning about overrides
+// naming no tool of the manifests
+func (s *Server) logDescriptionOverrides(tools []Tool) {
+	if len(s.descriptionOverrides) == 0 {
+		return
+	}
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Name] = true
+	}
+
+	names := slices.Sorted(maps.Keys(s.descriptionOverrides))
+	for _, name := range names {
+		o := s.descriptionOverrides[name]
+		if !known[name] {
+			s.logger.Warn("Description override names an unknown tool", "tool", name)
+			continue
+		}
+		s.logger.Info("Overriding tool description", "tool", name, "mode", cmp.Or(o.Mode, DescriptionReplace), "title", o.Title != "")
+	}
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDescriptionOverridesReplaceAndAppend(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`),
+		`{"name":"search","title":"Search","description":"Search","invoke_endpoints":{"json":"$BACKEND/search"}}`,
+		`{"name":"export","description":"Export rows\n","invoke_endpoints":{"json":"$BACKEND/export"}}`,
+		`{"name":"report","description":"","invoke_endpoints":{"json":"$BACKEND/report"}}`,
+		`{"name":"plain","description":"Untouched","invoke_endpoints":{"json":"$BACKEND/plain"}}`,
+	)
+	s := newToolServer(t, backend, WithDescriptionOverrides(map[string]DescriptionOverride{
+		"search": {Description: "Search the CRM by company name", Title: "CRM search"},
+		"export": {Description: "Use for CSV exports only.", Mode: DescriptionAppend},
+		"report": {Description: "Builds the weekly report.", Mode: DescriptionAppend},
+	}))
+
+	want := map[string]struct{ description, title string }{
+		"search": {"Search the CRM by company name", "CRM search"},
+		"export": {"Export rows\n\nUse for CSV exports only.", "Export"},
+		"report": {"Builds the weekly report.", "Report"},
+		"plain":  {"Untouched", "Plain"},
+	}
+	tools := listToolDefinitions(t, s)
+	if len(tools) != len(want) {
+		t.Fatalf("tools/list returned %d tools, want %d", len(tools), len(want))
+	}
+	for _, tool := range tools {
+		w := want[tool.Name]
+		if tool.Description != w.description {
+			t.Errorf("%s description = %q, want %q", tool.Name, tool.Description, w.description)
+		}
+		if tool.Annotations.Title != w.title {
+			t.Errorf("%s title = %q, want %q", tool.Name, tool.Annotations.Title, w.title)
+		}
+	}
+}
+
+func TestDescriptionOverrideValidate(t *testing.T) {
+	tests := map[string]struct {
+		override DescriptionOverride
+		wantErr  bool
+	}{
+		"replace":      {override: DescriptionOverride{Description: "d"}},
+		"append":       {override: DescriptionOverride{Description: "d", Mode: DescriptionAppend}},
+		"title only":   {override: DescriptionOverride{Title: "t"}},
+		"unknown mode": {override: DescriptionOverride{Description: "d", Mode: "prepend"}, wantErr: true},
+		"empty":        {override: DescriptionOverride{Mode: DescriptionAppend}, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tt.override.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToolTagsAppearInDescriptions(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`),
+		`{"name":"search","description":"Search","tags":["finance","reports"],"invoke_endpoints":{"json":"$BACKEND/search"}}`,
+		`{"name":"export","description":"Export rows","invoke_endpoints":{"json":"$BACKEND/export"}}`,
+		`{"name":"plain","description":"Untouched","invoke_endpoints":{"json":"$BACKEND/plain"}}`,
+	)
+	s := newToolServer(t, backend, WithToolTags(map[string][]string{
+		"search": {"reports", "beta"},
+		"export": {"reports"},
+	}))
+
+	want := map[string]string{
+		"search": "Search\n\nTags: finance, reports, beta",
+		"export": "Export rows\n\nTags: reports",
+		"plain":  "Untouched",
+	}
+	tools := listToolDefinitions(t, s)
+	if len(tools) != len(want) {
+		t.Fatalf("tools/list returned %d tools, want %d", len(tools), len(want))
+	}
+	for _, tool := range tools {
+		if tool.Description != want[tool.Name] {
+			t.Errorf("%s description = %q, want %q", tool.Name, tool.Description, want[tool.Name])
+		}
+	}
+
+	exposed, _ := s.ExposedTools()
+	for _, tool := range exposed {
+		if tool.Description != want[tool.Name] {
+			t.Errorf("exposed %s description = %q, want %q", tool.Name, tool.Description, want[tool.Name])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// pinnedResolver caches host resolutions and keeps using the last good answer while DNS is failing
+type pinnedResolver struct {
+	resolver *net.Resolver
+	refresh  time.Duration
+	dialer   *net.Dialer
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]pinnedHost
+}
+
+// pinnedHost is the last successful resolution of a host
+type pinnedHost struct {
+	addrs      []string
+	resolvedAt time.Time
+}
+
+// newPinnedResolver creates a resolver that re-resolves hosts after refresh has elapsed and connects through dialer
+func newPinnedResolver(resolver *net.Resolver, dialer *net.Dialer, refresh time.Duration, logger *slog.Logger) *pinnedResolver {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &pinnedResolver{
+		resolver: resolver,
+		refresh:  refresh,
+		dialer:   dialer,
+		logger:   logger,
+		cache:    make(map[string]pinnedHost),
+	}
+}
+
+// lookup returns the pinned addresses of host, refreshing them when stale and falling back to stale ones on failure
+func (r *pinnedResolver) lookup(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	pinned, ok := r.cache[host]
+	r.mu.Unlock()
+	if ok && time.Since(pinned.resolvedAt) < r.refresh {
+		return pinned.addrs, nil
+	}
+
+	addrs, err := r.resolver.LookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if ok {
+			r.logger.Warn("Failed to refresh DNS, keeping pinned addresses", "host", host, "error", err)
+			return pinned.addrs, nil
+		}
+		if err == nil {
+			err = errors.New("no addresses found")
+		}
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	r.mu.Lock()
+	r.cache[host] = pinnedHost{addrs: addrs, resolvedAt: time.Now()}
+	r.mu.Unlock()
+	return addrs, nil
+}
+
+// dialContext dials the pinned addresses of the target host in turn
+func (r *pinnedResolver) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return r.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := r.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// warm resolves the host of rawURL ahead of the first request
+func (r *pinnedResolver) warm(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || net.ParseIP(u.Hostname()) != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if addrs, err := r.lookup(ctx, u.Hostname()); err != nil {
+		r.logger.Warn("Failed to warm DNS", "host", u.Hostname(), "error", err)
+	} else {
+		r.logger.Info("Pinned DNS", "host", u.Hostname(), "addresses", addrs)
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDNS answers A queries for every name with 127.0.0.1, answering with SERVFAIL instead while failing is set,
+// and counts the A queries it receives
+type fakeDNS struct {
+	queries atomic.Int32
+	failing atomic.Bool
+}
+
+// resolver returns a pure Go resolver whose every lookup is answered by d
+func (d *fakeDNS) resolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go d.serve(server)
+			return client, nil
+		},
+	}
+}
+
+// serve answers one length-prefixed DNS query on conn
+func (d *fakeDNS) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	var size uint16
+	if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+		return
+	}
+	query := make([]byte, size)
+	if _, err := io.ReadFull(conn, query); err != nil || len(query) < 12 {
+		return
+	}
+
+	// Skip the labels of the question name to find its type
+	end := 12
+	for end < len(query) && query[end] != 0 {
+		end += int(query[end]) + 1
+	}
+	end += 5
+	if end > len(query) {
+		return
+	}
+	question := query[12:end]
+	isA := binary.BigEndian.Uint16(query[end-4:]) == 1
+	if isA {
+		d.queries.Add(1)
+	}
+
+	response := append([]byte{query[0], query[1], 0x81, 0x80, 0, 1, 0, 0, 0, 0, 0, 0}, question...)
+	switch {
+	case d.failing.Load():
+		response[3] |= 2
+	case isA:
+		response[7] = 1
+		response = append(response, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 127, 0, 0, 1)
+	}
+	_ = binary.Write(conn, binary.BigEndian, uint16(len(response)))
+	_, _ = conn.Write(response)
+}
+
+func TestDNSPinningResolvesThroughTheCustomResolver(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`), searchTool)
+	_, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	var dns fakeDNS
+	c := newTestClient("http://backend.test:"+port+"/manifest",
+		WithDNSPinning(time.Hour), WithResolver(dns.resolver()), WithProxy(ProxyDirect))
+	if got := dns.queries.Load(); got != 1 {
+		t.Fatalf("resolver received %d queries at startup, want the host resolved once", got)
+	}
+
+	manifest, err := c.FetchToolsetManifest(context.Background())
+	if err != nil {
+		t.Fatalf("FetchToolsetManifest() error = %v, want backend.test resolved by the custom resolver", err)
+	}
+	for range 3 {
+		// Drop the connection so every call dials the pinned address again
+		c.transport.CloseIdleConnections()
+		if _, err := c.ExecuteToolRequest(context.Background(), &manifest.Tools[0], []byte(`{}`)); err != nil {
+			t.Fatalf("ExecuteToolRequest() error = %v", err)
+		}
+	}
+	if got := dns.queries.Load(); got != 1 {
+		t.Errorf("resolver received %d queries, want the pinned answer reused", got)
+	}
+}
+
+func TestDNSPinningKeepsTheLastAnswerWhileLookupsFail(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`), searchTool)
+	_, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	var dns fakeDNS
+	c := newTestClient("http://backend.test:"+port+"/manifest",
+		WithDNSPinning(time.Nanosecond), WithResolver(dns.resolver()), WithProxy(ProxyDirect))
+
+	dns.failing.Store(true)
+	before := dns.queries.Load()
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Fatalf("FetchToolsetManifest() error = %v, want the pinned address used while DNS fails", err)
+	}
+	if dns.queries.Load() == before {
+		t.Error("resolver received no queries, want the stale answer refreshed")
+	}
+}
+
+func TestDNSPinningFailsWithoutAnyAnswer(t *testing.T) {
+	var dns fakeDNS
+	dns.failing.Store(true)
+	c := newTestClient("http://backend.test:1/manifest",
+		WithDNSPinning(time.Hour), WithResolver(dns.resolver()), WithProxy(ProxyDirect))
+
+	_, err := c.FetchToolsetManifest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve backend.test") {
+		t.Errorf("FetchToolsetManifest() error = %v, want the lookup failure", err)
+	}
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Endpoint is an additional Asgard toolset endpoint whose tools are served next to those of the primary endpoint
+type Endpoint struct {
+	URL        string `yaml:"url"`
+	APIKey     string `yaml:"api_key"`
+	APIKeyFile string `yaml:"api_key_file"`
+	// Prefix is prepended to the names of the endpoint's tools, keeping them apart from tools of other endpoints
+	Prefix string `yaml:"prefix"`
+	// Proxy overrides the proxy setting for this endpoint, "direct" bypassing any proxy
+	Proxy string `yaml:"proxy"`
+
+	// AuthMode, OAuth2, and Headers authenticate requests to this endpoint alone; the primary endpoint's auth mode,
+	// OAuth2 client credentials, and extra headers are never sent to it
+	AuthMode AuthMode          `yaml:"auth_mode"`
+	OAuth2   OAuth2Config      `yaml:"oauth2"`
+	Headers  map[string]string `yaml:"headers"`
+}
+
+// endpoint is a served endpoint and the client calling it
+type endpoint struct {
+	Endpoint
+	client *APIClient
+
+	// resources are those of the endpoint's manifest at startup, prompts those of its latest fetch
+	resources []Resource
+	prompts   []PromptTemplate
+
+	// fetchErr is the error of the latest manifest fetch, written under reloadMu once the server is running
+	fetchErr error
+}
+
+// connectEndpoints creates the primary endpoint around s.apiClient followed by a client for each additional endpoint
+func (s *Server) connectEndpoints() {
+	s.endpoints = []*endpoint{{
+		Endpoint: Endpoint{URL: s.endpointURL, APIKey: s.apiKey, Prefix: s.toolPrefix},
+		client:   s.apiClient,
+	}}
+	for _, e := range s.extraEndpoints {
+		// Each endpoint inherits the transport settings but authenticates with its own credentials only
+		opts := append(append([]APIClientOption{}, s.clientOpts...), withoutCredentials(),
+			WithAPIKeyFile(e.APIKeyFile), WithOAuth2(e.OAuth2), WithExtraHeaders(e.Headers))
+		if e.AuthMode != "" {
+			opts = append(opts, WithAuthMode(e.AuthMode))
+		}
+		if e.Proxy != "" {
+			opts = append(opts, WithProxy(e.Proxy))
+		}
+		// Calls to every endpoint count against the same concurrency limit
+		opts = append(opts, withCallLimiter(s.apiClient.limiter))
+		s.endpoints = append(s.endpoints, &endpoint{
+			Endpoint: e,
+			client:   NewAPIClientWithOptions(e.URL, e.APIKey, opts...),
+		})
+	}
+}
+
+// withoutCredentials drops the auth mode, key file, OAuth2 client credentials, and extra headers set by earlier
+// options, so a client for another endpoint starts from the API key alone
+func withoutCredentials() APIClientOption {
+	return func(c *APIClient) {
+		c.authMode = AuthModeAPIKey
+		c.apiKeyFile = ""
+		c.oauth = nil
+		c.extraHeaders = nil
+	}
+}
+
+// fetchTools fetches the manifest of every endpoint and merges their tools, keeping the previous tools of an
+// endpoint whose fetch fails; it fails only when no endpoint could be fetched
+func (s *Server) fetchTools(ctx context.Context, previous []Tool, generation int) ([]Tool, int, error) {
+	var tools []Tool
+	var errs []error
+	seen := make(map[string]bool)
+	for i, ep := range s.endpoints {
+		manifest, err := ep.client.FetchToolsetManifest(ctx)
+		if err == nil {
+			err = s.checkEmptyManifest(ep, manifest)
+		}
+		ep.fetchErr = err
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", redactURL(ep.URL, s.apiKeys()...), err))
+			if len(s.endpoints) > 1 {
+				s.logger.Warn("Failed to fetch the manifest, keeping the endpoint's current tools", "endpoint", redactURL(ep.URL, s.apiKeys()...), "error", err)
+			}
+			for _, tool := range previous {
+				if tool.endpoint == ep {
+					tools = append(tools, tool)
+					seen[ep.Prefix+tool.Name] = true
+				}
+			}
+			continue
+		}
+
+		// Resource templates cannot be unregistered, so reloads keep the resources of the first fetch
+		if ep.resources == nil {
+			ep.resources = manifest.Resources
+		}
+		ep.prompts = manifest.Prompts
+
+		// Argument rules follow the generation of the primary endpoint
+		if i == 0 {
+			generation = manifest.Generation
+		}
+		for _, tool := range manifest.Tools {
+			tool.endpoint = ep
+			name := ep.Prefix + tool.Name
+			if seen[name] {
+				s.logger.Warn("Skipping tool, the name is already taken by another endpoint", "tool", name, "endpoint", redactURL(ep.URL))
+				continue
+			}
+			seen[name] = true
+			tools = append(tools, tool)
+		}
+	}
+
+	if len(errs) == len(s.endpoints) {
+		return nil, 0, errors.Join(errs...)
+	}
+	s.assignExposedNames(tools)
+
+	// Keep logs, reload diffs, and listings stable however the backend orders its manifest
+	if !s.logManifestOrder {
+		slices.SortStableFunc(tools, func(a, b Tool) int {
+			return strings.Compare(a.exposed, b.exposed)
+		})
+	}
+	return tools, generation, nil
+}
+
+// assignExposedNames gives every newly fetched tool the name clients see: its prefixed manifest name, sanitized
+// and suffixed when it collides with another tool. Tools kept from before and names that need no sanitizing are
+// served first, so a rewritten name never takes over a valid one; the backend is still called with the manifest name
+func (s *Server) assignExposedNames(tools []Tool) {
+	taken := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if tool.exposed != "" {
+			taken[tool.exposed] = true
+		}
+	}
+	for i, tool := range tools {
+		name := tool.endpoint.Prefix + tool.Name
+		if tool.exposed == "" && !taken[name] && SanitizeToolName(name) == name {
+			tools[i].exposed = name
+			taken[name] = true
+		}
+	}
+	for i, tool := range tools {
+		if tool.exposed != "" {
+			continue
+		}
+		name := tool.endpoint.Prefix + tool.Name
+		tools[i].exposed = uniqueToolName(SanitizeToolName(name), taken)
+		taken[tools[i].exposed] = true
+		s.logger.Warn("Tool name may be rejected by MCP clients, exposing a sanitized name",
+			"tool", name, "endpoint", redactURL(tool.endpoint.URL), "exposed", tools[i].exposed)
+	}
+}
+
+// checkEmptyManifest warns about a manifest declaring no tools, which usually points to a wrong endpoint or
+// namespace, and fails it unless empty manifests are allowed
+func (s *Server) checkEmptyManifest(ep *endpoint, manifest *ToolsetManifest) error {
+	if len(manifest.Tools) > 0 {
+		return nil
+	}
+	if !s.allowEmptyManifest {
+		return fmt.Errorf("manifest %s/%s declares no tools, check the endpoint URL and namespace", manifest.Namespace, manifest.Name)
+	}
+	s.logger.Warn("Manifest declares no tools, check the endpoint URL and namespace",
+		"namespace", manifest.Namespace, "toolset", manifest.Name, "endpoint", redactURL(ep.URL))
+	return nil
+}
+
+// clientFor returns the client calling the endpoint the tool belongs to
+func (s *Server) clientFor(tool Tool) *APIClient {
+	if tool.endpoint == nil {
+		return s.apiClient
+	}
+	return tool.endpoint.client
+}
+
+// exposedName returns the name clients see for the tool, prefixed for its endpoint and sanitized
+func (s *Server) exposedName(tool Tool) string {
+	if tool.exposed != "" {
+		return tool.exposed
+	}
+	if tool.endpoint == nil {
+		return s.toolPrefix + tool.Name
+	}
+	return tool.endpoint.Prefix + tool.Name
+}
+
+// apiKeys returns the API keys of every endpoint, for redaction
+func (s *Server) apiKeys() []string {
+	var keys []string
+	for _, ep := range s.endpoints {
+		keys = append(keys, ep.client.apiKeys()...)
+	}
+	return keys
+}
+
+// fetchErrors joins the errors of the endpoints whose latest manifest fetch failed
+func (s *Server) fetchErrors() error {
+	var errs []error
+	for _, ep := range s.endpoints {
+		if ep.fetchErr != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch toolset manifest from %s: %w", redactURL(ep.URL, s.apiKeys()...), ep.fetchErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeEndpoints releases the resources of every endpoint client
+func (s *Server) closeEndpoints() {
+	for _, ep := range s.endpoints {
+		_ = ep.client.Close()
+	}
+}
@@ -0,0 +1,277 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// headerBackend is a fake Asgard backend recording the headers of the manifest requests it receives per path,
+// with an OAuth2 token endpoint at /token
+type headerBackend struct {
+	*httptest.Server
+	mu      sync.Mutex
+	headers map[string]http.Header
+}
+
+// newHeaderBackend starts a backend serving an empty manifest at every other path
+func newHeaderBackend(t *testing.T) *headerBackend {
+	t.Helper()
+	b := &headerBackend{headers: make(map[string]http.Header)}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"access_token":"primary-token","token_type":"bearer","expires_in":3600}`)
+			return
+		}
+		b.mu.Lock()
+		b.headers[r.URL.Path] = r.Header.Clone()
+		b.mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// received returns the headers of the last manifest request for path
+func (b *headerBackend) received(path string) http.Header {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.headers[path]
+}
+
+func TestEndpointsDoNotInheritPrimaryCredentials(t *testing.T) {
+	backend := newHeaderBackend(t)
+	_, err := NewServer(backend.URL+"/a", "primary-key",
+		WithLogger(discardLogger),
+		WithAPIClientOptions(
+			WithAuthMode(AuthModeBearer),
+			WithOAuth2(OAuth2Config{TokenURL: backend.URL + "/token", ClientID: "primary"}),
+			WithExtraHeaders(map[string]string{"X-Tenant-ID": "primary"}),
+		),
+		WithEndpoints(
+			Endpoint{URL: backend.URL + "/b", APIKey: "b-key", Prefix: "b_"},
+			Endpoint{URL: backend.URL + "/c", APIKey: "c-key", Prefix: "c_", AuthMode: AuthModeBearer,
+				Headers: map[string]string{"X-Tenant-ID": "other"}},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, apiKey, authorization, tenant string
+	}{
+		{path: "/a", authorization: "Bearer primary-token", tenant: "primary"},
+		{path: "/b", apiKey: "b-key"},
+		{path: "/c", authorization: "Bearer c-key", tenant: "other"},
+	}
+	for _, tt := range tests {
+		h := backend.received(tt.path)
+		if h == nil {
+			t.Errorf("%s: manifest was not fetched", tt.path)
+			continue
+		}
+		if got := h.Get("X-API-KEY"); got != tt.apiKey {
+			t.Errorf("%s: X-API-KEY = %q, want %q", tt.path, got, tt.apiKey)
+		}
+		if got := h.Get("Authorization"); got != tt.authorization {
+			t.Errorf("%s: Authorization = %q, want %q", tt.path, got, tt.authorization)
+		}
+		if got := h.Get("X-Tenant-ID"); got != tt.tenant {
+			t.Errorf("%s: X-Tenant-ID = %q, want %q", tt.path, got, tt.tenant)
+		}
+	}
+}
+
+// routingBackend is a fake Asgard backend serving a manifest of the given tools at /manifest and recording the
+// tools called on it
+type routingBackend struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []string
+}
+
+func newRoutingBackend(t *testing.T, tools ...string) *routingBackend {
+	t.Helper()
+	b := &routingBackend{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/manifest" {
+			var entries []string
+			for _, name := range tools {
+				entries = append(entries, fmt.Sprintf(`{"name":%q,"description":"Tool","invoke_endpoints":{"json":"http://%s/call/%s"}}`, name, r.Host, name))
+			}
+			_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[%s]}}`, strings.Join(entries, ","))
+			return
+		}
+		b.mu.Lock()
+		b.calls = append(b.calls, strings.TrimPrefix(r.URL.Path, "/call/"))
+		b.mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// called returns the tools called on the backend so far
+func (b *routingBackend) called() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.calls...)
+}
+
+// listToolDefinitions returns the tools the server lists to MCP clients
+func listToolDefinitions(t *testing.T, s *Server) []mcp.Tool {
+	t.Helper()
+	response, ok := s.mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("tools/list did not return a result")
+	}
+	result, ok := response.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("tools/list returned %T, want a tool list", response.Result)
+	}
+	return result.Tools
+}
+
+// listTools returns the names of the tools the server lists to MCP clients
+func listTools(t *testing.T, s *Server) []string {
+	t.Helper()
+	var names []string
+	for _, tool := range listToolDefinitions(t, s) {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestEndpointsMergeToolsAndRouteCalls(t *testing.T) {
+	primary := newRoutingBackend(t, "search", "export")
+	extra := newRoutingBackend(t, "search")
+	s, err := NewServer(primary.URL+"/manifest", "test-key",
+		WithLogger(discardLogger),
+		WithEndpoints(Endpoint{URL: extra.URL + "/manifest", APIKey: "other-key", Prefix: "b_"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := listTools(t, s); fmt.Sprint(got) != "[b_search export search]" {
+		t.Errorf("tools/list = %v, want the tools of both endpoints", got)
+	}
+	for _, name := range []string{"search", "b_search", "export"} {
+		if result := callTool(t, s, name, map[string]interface{}{}); result.IsError {
+			t.Fatalf("%s: call failed: %s", name, resultText(result))
+		}
+	}
+	if got := primary.called(); fmt.Sprint(got) != "[search export]" {
+		t.Errorf("primary backend called with %v, want its own tools", got)
+	}
+	if got := extra.called(); fmt.Sprint(got) != "[search]" {
+		t.Errorf("extra backend called with %v, want the prefixed tool only", got)
+	}
+}
+
+func TestFailingEndpointDoesNotStopTheOthers(t *testing.T) {
+	primary := newRoutingBackend(t, "search")
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+	s, err := NewServer(primary.URL+"/manifest", "test-key",
+		WithLogger(discardLogger),
+		WithEndpoints(Endpoint{URL: down.URL, APIKey: "other-key", Prefix: "down_"}),
+	)
+	if err != nil {
+		t.Fatalf("NewServer() = %v, want the reachable endpoint served", err)
+	}
+	if got := listTools(t, s); fmt.Sprint(got) != "[search]" {
+		t.Errorf("tools/list = %v, want the tools of the reachable endpoint", got)
+	}
+}
+
+func TestEndpointFetchErrorsRedactURLs(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+	u, err := url.Parse(down.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewServer("http://user:hunter2@"+u.Host+"/manifest?access_token=tok-123", "test-key",
+		WithLogger(discardLogger),
+		WithEndpoints(Endpoint{URL: down.URL + "/manifest?key=other-key", APIKey: "other-key", Prefix: "b_"}),
+	)
+	if err == nil {
+		t.Fatal("NewServer() succeeded, want an error when no endpoint is reachable")
+	}
+	for _, secret := range []string{"hunter2", "tok-123", "other-key"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("NewServer() error %q carries %q", err, secret)
+		}
+	}
+}
+
+func TestToolsAreRegisteredSortedByName(t *testing.T) {
+	backend := newRoutingBackend(t, "search", "archive", "export")
+	for _, tt := range []struct {
+		logManifestOrder bool
+		want             string
+	}{
+		{want: "[archive export search]"},
+		{logManifestOrder: true, want: "[search archive export]"},
+	} {
+		s, err := NewServer(backend.URL+"/manifest", "test-key", WithLogger(discardLogger), WithLogManifestOrder(tt.logManifestOrder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var registered []string
+		for _, tool := range s.tools {
+			registered = append(registered, s.exposedName(tool))
+		}
+		if fmt.Sprint(registered) != tt.want {
+			t.Errorf("log manifest order %t: registered %v, want %s", tt.logManifestOrder, registered, tt.want)
+		}
+		if got := listTools(t, s); fmt.Sprint(got) != "[archive export search]" {
+			t.Errorf("log manifest order %t: tools/list = %v, want it sorted by name", tt.logManifestOrder, got)
+		}
+	}
+}
+
+func TestEmptyManifests(t *testing.T) {
+	tests := map[string]struct {
+		allow   bool
+		wantErr bool
+	}{
+		"warn by default": {allow: true},
+		"fail when empty manifests are not allowed": {allow: false, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			backend := newHeaderBackend(t)
+			s, err := NewServer(backend.URL+"/manifest", "test-key",
+				WithLogger(slog.New(slog.NewTextHandler(&logs, nil))), WithAllowEmptyManifest(tt.allow))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "manifest ns/ts declares no tools") {
+					t.Errorf("NewServer() error = %v, want the empty manifest named", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tools := listTools(t, s); len(tools) != 0 {
+				t.Errorf("tools/list = %v, want no tools", tools)
+			}
+			if !strings.Contains(logs.String(), "Manifest declares no tools") || !strings.Contains(logs.String(), "namespace=ns toolset=ts") {
+				t.Errorf("logs = %q, want a warning naming the manifest", logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// APIError describes a failed request to the Asgard backend; manifest fetches and tool calls return it for
+// non-200 responses and for responses with isSuccess set to false, so callers can inspect it with errors.As
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Code is the backend errorCode, if any
+	Code string
+	// Message is the backend error message for responses with isSuccess set to false
+	Message string
+	// Body is the response body with the API key and credential values redacted
+	Body string
+	// Header holds the response headers, used for diagnostics
+	Header http.Header
+	// RequestID is the backend's request ID, if any
+	RequestID string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return apiErrorText(e.Code, e.Message)
+	}
+	if hint := statusHint(e.StatusCode); hint != "" {
+		return fmt.Sprintf("unexpected status code: %d (%s), body: %s", e.StatusCode, hint, e.Body)
+	}
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// statusHint explains what a common backend status code usually means, or returns empty
+func statusHint(status int) string {
+	switch {
+	case status == http.StatusBadRequest:
+		return "the backend rejected the request"
+	case status == http.StatusUnauthorized:
+		return "the API key or token was rejected"
+	case status == http.StatusForbidden:
+		return "the credentials do not grant access"
+	case status == http.StatusNotFound:
+		return "not found, the tool or toolset may have been removed"
+	case status == http.StatusRequestEntityTooLarge:
+		return "the request is too large"
+	case status == http.StatusTooManyRequests:
+		return "throttled by the backend, retry later"
+	case status == http.StatusGatewayTimeout:
+		return "the backend timed out"
+	case status >= 500:
+		return "backend failure"
+	}
+	return ""
+}
+
+// apiErrorText formats a backend error message, prefixed with its errorCode when there is one
+func apiErrorText(code, message string) string {
+	if code != "" {
+		return fmt.Sprintf("API error [%s]: %s", code, message)
+	}
+	return fmt.Sprintf("API error: %s", message)
+}
+
+// errorCodeOf extracts the errorCode from an Asgard error body, or returns empty for other bodies
+func errorCodeOf(body []byte) string {
+	var response struct {
+		ErrorCode *string `json:"errorCode"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.ErrorCode == nil {
+		return ""
+	}
+	return *response.ErrorCode
+}
+
+// DefaultDiagnosticsBodyCap is the default number of body bytes included in error diagnostics
+const DefaultDiagnosticsBodyCap = 1024
+
+// Diagnostics renders the status, headers, and a body snippet of at most bodyCap bytes with secrets redacted
+func (e *APIError) Diagnostics(bodyCap int, secrets ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "status: %d %s\n", e.StatusCode, http.StatusText(e.StatusCode))
+
+	// Headers in a stable order
+	header := redactHeaders(e.Header, secrets...)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(header[name], ", "))
+	}
+
+	body := redact(e.Body, secrets...)
+	if bodyCap > 0 && len(body) > bodyCap {
+		body = fmt.Sprintf("%s...[%d bytes omitted]", body[:bodyCap], len(body)-bodyCap)
+	}
+	fmt.Fprintf(&b, "\n%s", body)
+	return b.String()
+}
+
+// Error codes used in structured tool errors that do not originate from the backend
+const (
+	ToolErrorInvalidArguments = "invalid_arguments"
+	ToolErrorExecutionFailed  = "execution_failed"
+	ToolErrorInvalidResponse  = "invalid_response"
+)
+
+// ToolError is the structured form of a failed tool call
+type ToolError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Status    int    `json:"status,omitempty"`
+	Tool      string `json:"tool"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// toolError builds the result for a failed tool call as plain text or, when enabled, structured JSON
+func (s *Server) toolError(toolName, code, text string, err error) *mcp.CallToolResult {
+	var apiErr *APIError
+	isAPIErr := errors.As(err, &apiErr)
+
+	// Append backend response details in debug mode
+	if s.errorDiagnostics && isAPIErr {
+		text = fmt.Sprintf("%s\n\nBackend response:\n%s", text, apiErr.Diagnostics(DefaultDiagnosticsBodyCap, s.apiKeys()...))
+	}
+
+	if !s.structuredErrors {
+		result := mcp.NewToolResultError(text)
+		if isAPIErr {
+			s.withRequestID(withErrorCode(result, apiErr.Code), apiErr.RequestID)
+		}
+		return result
+	}
+
+	payload := ToolError{
+		Code:    code,
+		Message: text,
+		Tool:    toolName,
+	}
+
+	// Carry over backend details when available
+	if isAPIErr {
+		payload.Status = apiErr.StatusCode
+		payload.RequestID = apiErr.RequestID
+		if apiErr.Code != "" {
+			payload.Code = apiErr.Code
+		}
+	}
+
+	data, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return mcp.NewToolResultError(text)
+	}
+	result := mcp.NewToolResultError(string(data))
+	if isAPIErr {
+		s.withRequestID(withErrorCode(result, apiErr.Code), apiErr.RequestID)
+	}
+	return result
+}
+
+// withErrorCode records the backend's errorCode in the result metadata so clients can branch on it
+func withErrorCode(result *mcp.CallToolResult, code string) *mcp.CallToolResult {
+	if code == "" {
+		return result
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]any)
+	}
+	result.Meta["errorCode"] = code
+	return result
+}
+
+// withRequestID records the backend's request ID in the result metadata when enabled
+func (s *Server) withRequestID(result *mcp.CallToolResult, requestID string) *mcp.CallToolResult {
+	if !s.requestIDMeta || requestID == "" {
+		return result
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]any)
+	}
+	result.Meta["requestId"] = requestID
+	return result
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// searchTool declares a tool named search for newToolBackend
+const searchTool = `{"name":"search","description":"Search","invoke_endpoints":{"json":"$BACKEND/search"}}`
+
+// replyWith returns a handler answering with the given status, headers, and body
+func replyWith(status int, body string, header ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestStructuredErrorShape(t *testing.T) {
+	tests := map[string]struct {
+		reply http.HandlerFunc
+		want  ToolError
+	}{
+		"backend error code": {
+			reply: replyWith(http.StatusUnprocessableEntity, `{"isSuccess":false,"error":"bad query","errorCode":"INVALID_QUERY"}`, "X-Request-ID", "req-1"),
+			want:  ToolError{Code: "INVALID_QUERY", Status: http.StatusUnprocessableEntity, Tool: "search", RequestID: "req-1"},
+		},
+		"plain 4xx": {
+			reply: replyWith(http.StatusNotFound, "no such tool"),
+			want:  ToolError{Code: ToolErrorExecutionFailed, Status: http.StatusNotFound, Tool: "search"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, tt.reply, searchTool)
+			s := newToolServer(t, backend, WithStructuredErrors(true))
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if !result.IsError {
+				t.Fatalf("result is not flagged as an error: %+v", result)
+			}
+			var got ToolError
+			if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+				t.Fatalf("error text %q is not a structured error: %v", resultText(result), err)
+			}
+			if got.Message == "" {
+				t.Error("structured error has no message")
+			}
+			got.Message = ""
+			if got != tt.want {
+				t.Errorf("structured error = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainErrorsAreText(t *testing.T) {
+	backend := newToolBackend(t, replyWith(http.StatusBadRequest, `{"isSuccess":false,"errorCode":"BAD"}`), searchTool)
+	s := newToolServer(t, backend)
+
+	result := callTool(t, s, "search", map[string]interface{}{})
+	if !result.IsError {
+		t.Fatalf("result is not flagged as an error: %+v", result)
+	}
+	if json.Valid([]byte(resultText(result))) {
+		t.Errorf("error text %q is JSON, want plain text without structured errors", resultText(result))
+	}
+	if got := result.Meta; got["errorCode"] != "BAD" {
+		t.Errorf("result _meta = %+v, want errorCode BAD", got)
+	}
+}
+
+func TestBackendErrorCodes(t *testing.T) {
+	tests := map[string]struct {
+		status   int
+		body     string
+		wantCode string
+		wantText string
+	}{
+		"populated code": {status: http.StatusOK, body: `{"isSuccess":false,"error":"quota used up","errorCode":"QUOTA_EXCEEDED"}`,
+			wantCode: "QUOTA_EXCEEDED", wantText: "API error [QUOTA_EXCEEDED]: quota used up"},
+		"null code": {status: http.StatusOK, body: `{"isSuccess":false,"error":"quota used up","errorCode":null}`,
+			wantText: "API error: quota used up"},
+		"missing message": {status: http.StatusOK, body: `{"isSuccess":false,"errorCode":"E1"}`,
+			wantCode: "E1", wantText: "API error [E1]: unknown error"},
+		"malformed body": {status: http.StatusInternalServerError, body: `{"errorCode":`,
+			wantText: `unexpected status code: 500 (backend failure), body: {"errorCode":`},
+		"code of a wrong type": {status: http.StatusBadRequest, body: `{"isSuccess":false,"errorCode":42}`,
+			wantText: `unexpected status code: 400 (the backend rejected the request), body: {"isSuccess":false,"errorCode":42}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(tt.status, tt.body), searchTool)
+			s := newToolServer(t, backend)
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if !result.IsError {
+				t.Fatalf("result = %q, want an error", resultText(result))
+			}
+			if want := "Tool execution failed: " + tt.wantText; resultText(result) != want {
+				t.Errorf("error text = %q, want %q", resultText(result), want)
+			}
+			code, ok := result.Meta["errorCode"]
+			if tt.wantCode == "" && ok {
+				t.Errorf("result _meta errorCode = %v, want none", code)
+			}
+			if tt.wantCode != "" && code != tt.wantCode {
+				t.Errorf("result _meta errorCode = %v, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAPIErrorsCarryTheStatusCode(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusTooManyRequests, http.StatusBadGateway} {
+		t.Run(fmt.Sprint(status), func(t *testing.T) {
+			backend := httptest.NewServer(replyWith(status, "nope", "X-Request-ID", "req-9"))
+			t.Cleanup(backend.Close)
+			c := newTestClient(backend.URL)
+
+			_, manifestErr := c.FetchToolsetManifest(context.Background())
+			tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+			_, callErr := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`))
+			for name, err := range map[string]error{"FetchToolsetManifest": manifestErr, "ExecuteToolRequest": callErr} {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Errorf("%s() error = %v, want an *APIError", name, err)
+					continue
+				}
+				if apiErr.StatusCode != status || apiErr.Body != "nope" || apiErr.RequestID != "req-9" {
+					t.Errorf("%s() APIError has status %d, body %q, and request ID %q, want %d, \"nope\", and req-9",
+						name, apiErr.StatusCode, apiErr.Body, apiErr.RequestID, status)
+				}
+				if want := statusHint(status); want == "" || !strings.Contains(err.Error(), want) {
+					t.Errorf("%s() error = %v, want the hint %q", name, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorDiagnosticsArePopulated(t *testing.T) {
+	body := `{"error":"boom","key":"test-key","trace":"` + strings.Repeat("x", 2*DefaultDiagnosticsBodyCap) + `"}`
+	reply := replyWith(http.StatusInternalServerError, body, "X-Trace-ID", "trace-7", "Set-Cookie", "session=secret")
+	backend := newToolBackend(t, reply, searchTool)
+
+	// The client error carries the raw material for diagnostics
+	c := newTestClient(backend.URL)
+	tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+	_, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ExecuteToolRequest() error = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusInternalServerError || apiErr.Header.Get("X-Trace-ID") != "trace-7" || !strings.HasPrefix(apiErr.Body, `{"error":"boom"`) {
+		t.Errorf("APIError = status %d, headers %v, body %.40q, want the failed response", apiErr.StatusCode, apiErr.Header, apiErr.Body)
+	}
+
+	diagnostics := apiErr.Diagnostics(64, "test-key")
+	for _, want := range []string{"status: 500 Internal Server Error\n", "X-Trace-Id: trace-7\n", "Set-Cookie: " + redactedPlaceholder + "\n", `"key":"` + redactedPlaceholder + `"`, "bytes omitted]"} {
+		if !strings.Contains(diagnostics, want) {
+			t.Errorf("Diagnostics() = %q, want it to contain %q", diagnostics, want)
+		}
+	}
+	for _, secret := range []string{"test-key", "session=secret"} {
+		if strings.Contains(diagnostics, secret) {
+			t.Errorf("Diagnostics() = %q, want %q redacted", diagnostics, secret)
+		}
+	}
+
+	// Tool errors include the diagnostics only when enabled
+	for _, enabled := range []bool{false, true} {
+		s := newToolServer(t, backend, WithErrorDiagnostics(enabled))
+		result := callTool(t, s, "search", map[string]interface{}{})
+		text := resultText(result)
+		if !result.IsError || strings.Contains(text, "Backend response:\nstatus: 500") != enabled {
+			t.Errorf("with diagnostics %v, result = %q", enabled, text)
+		}
+		if strings.Contains(text, "session=secret") {
+			t.Errorf("with diagnostics %v, result = %q, want cookies redacted", enabled, text)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package mcp
+
+// ExposedTool is a tool as MCP clients see it
+type ExposedTool struct {
+	// Name is the name clients call the tool by, prefixed for its endpoint and sanitized
+	Name string
+	// Description is the description clients see, with any override and tags applied
+	Description      string
+	AllowUploadFiles bool
+	// Endpoint is the manifest URL of the endpoint serving the tool
+	Endpoint string
+}
+
+// ExposedTools returns the tools registered with MCP clients in registration order, leaving out tools hidden by
+// the filters, skipped by upload safe mode, or served only as prompts; the error names the endpoints whose latest
+// manifest fetch failed, whose tools are missing or stale
+func (s *Server) ExposedTools() ([]ExposedTool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.mutex.RLock()
+	tools := s.tools
+	s.mutex.RUnlock()
+
+	exposed := make([]ExposedTool, 0, len(tools))
+	for _, tool := range tools {
+		if s.skipsUploadTool(tool) || s.promptOnly(tool) {
+			continue
+		}
+		tool = s.overrideDescription(tool)
+		exposed = append(exposed, ExposedTool{
+			Name:             s.exposedName(tool),
+			Description:      describeWithTags(tool.Description, s.mergedTags(tool)),
+			AllowUploadFiles: tool.AllowUploadFiles,
+			Endpoint:         redactURL(s.clientFor(tool).baseURL, s.apiKeys()...),
+		})
+	}
+	return exposed, s.fetchErrors()
+}
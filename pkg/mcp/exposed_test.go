@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newManifestBackend starts a backend serving the given manifest tools, as JSON objects, at every path
+func newManifestBackend(t *testing.T, tools ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[%s]}}`,
+			strings.Join(tools, ","))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExposedToolsFollowRegistration(t *testing.T) {
+	backend := newManifestBackend(t,
+		`{"name":"search","description":"Search","invoke_endpoints":{"json":"http://backend.invalid/search"}}`,
+		`{"name":"a b","description":"Spaced","invoke_endpoints":{"json":"http://backend.invalid/ab"}}`,
+		`{"name":"a_b","description":"Underscored","invoke_endpoints":{"json":"http://backend.invalid/ab"}}`,
+		`{"name":"ingest","description":"Ingest","allow_upload_files":true,"invoke_endpoints":{"form":"http://backend.invalid/ingest"}}`,
+		`{"name":"summarize","description":"Summarize","prompt":true,"invoke_endpoints":{"json":"http://backend.invalid/summarize"}}`,
+		`{"name":"hidden","description":"Hidden","invoke_endpoints":{"json":"http://backend.invalid/hidden"}}`,
+	)
+	s, err := NewServer(backend.URL, "test-key",
+		WithLogger(discardLogger),
+		WithToolFilter(ToolFilter{Deny: []string{"hidden"}}),
+		WithNoUploads(UploadSafeModeSkip),
+		WithToolPrompts(PromptModeOnly),
+		WithDescriptionOverrides(map[string]DescriptionOverride{"search": {Description: "Search the CRM"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := s.ExposedTools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExposedTool{
+		{Name: "a_b", Description: "Underscored", Endpoint: backend.URL},
+		{Name: "a_b_2", Description: "Spaced", Endpoint: backend.URL},
+		{Name: "search", Description: "Search the CRM", Endpoint: backend.URL},
+	}
+	if fmt.Sprint(tools) != fmt.Sprint(want) {
+		t.Errorf("ExposedTools() = %+v, want %+v", tools, want)
+	}
+}
+
+func TestExposedToolsReportFailedEndpoints(t *testing.T) {
+	backend := newManifestBackend(t, `{"name":"search","description":"Search","invoke_endpoints":{"json":"http://backend.invalid/search"}}`)
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+	s, err := NewServer(backend.URL, "test-key",
+		WithLogger(discardLogger),
+		WithEndpoints(Endpoint{URL: down.URL, APIKey: "other-key", Prefix: "down_"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := s.ExposedTools()
+	if err == nil || !strings.Contains(err.Error(), down.URL) {
+		t.Errorf("ExposedTools() error = %v, want an error naming %s", err, down.URL)
+	}
+	if len(tools) != 1 || tools[0].Name != "search" {
+		t.Errorf("ExposedTools() = %+v, want the tools of the reachable endpoint", tools)
+	}
+}
+
+func TestAssignExposedNames(t *testing.T) {
+	tests := map[string]struct {
+		prefix string
+		names  []string
+		want   []string
+	}{
+		"safe names are kept":                {names: []string{"search", "get-item"}, want: []string{"search", "get-item"}},
+		"spaces, dots, and slashes":          {names: []string{"find customer", "crm.lookup", "files/list"}, want: []string{"find_customer", "crm_lookup", "files_list"}},
+		"safe names win over sanitized ones": {names: []string{"a b", "a_b"}, want: []string{"a_b_2", "a_b"}},
+		"sanitized names collide":            {names: []string{"a b", "a.b", "a/b"}, want: []string{"a_b", "a_b_2", "a_b_3"}},
+		"suffixes skip taken names":          {names: []string{"a b", "a.b", "a_b_2"}, want: []string{"a_b", "a_b_3", "a_b_2"}},
+		"prefixes are sanitized too":         {prefix: "crm.", names: []string{"search"}, want: []string{"crm_search"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{logger: discardLogger}
+			ep := &endpoint{Endpoint: Endpoint{URL: "http://backend.invalid", Prefix: tt.prefix}}
+			tools := make([]Tool, len(tt.names))
+			for i, name := range tt.names {
+				tools[i] = Tool{Name: name, endpoint: ep}
+			}
+
+			s.assignExposedNames(tools)
+			got := make([]string, len(tools))
+			for i, tool := range tools {
+				got[i] = tool.exposed
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("exposed names of %q = %q, want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizedNamesCallTheOriginalTool(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	},
+		`{"name":"a b","description":"Spaced","invoke_endpoints":{"json":"$BACKEND/spaced"}}`,
+		`{"name":"a_b","description":"Underscored","invoke_endpoints":{"json":"$BACKEND/underscored"}}`,
+	)
+	s := newToolServer(t, backend)
+
+	for _, name := range []string{"a_b_2", "a_b"} {
+		if result := callTool(t, s, name, nil); result.IsError {
+			t.Fatalf("tools/call %s failed: %s", name, resultText(result))
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"/spaced", "/underscored"}; fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("backend received calls to %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+)
+
+// ToolFilter selects the manifest tools exposed to clients by name, using glob patterns where * matches any run
+// of characters
+type ToolFilter struct {
+	// Allow lists the patterns a tool must match to be exposed; empty exposes every tool
+	Allow []string
+	// Deny lists the patterns of tools never exposed, taking precedence over Allow
+	Deny []string
+}
+
+// Validate checks that every pattern is well formed
+func (f ToolFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Allow...), f.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Exposes reports whether the named tool passes the filter
+func (f ToolFilter) Exposes(name string) bool {
+	if matchAny(f.Deny, name) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchAny(f.Allow, name)
+}
+
+// apply returns the tools passing the filter and the tools filtered out
+func (f ToolFilter) apply(tools []Tool) (exposed, filtered []Tool) {
+	if len(f.Allow) == 0 && len(f.Deny) == 0 {
+		return tools, nil
+	}
+
+	exposed = make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		if !f.Exposes(tool.Name) {
+			filtered = append(filtered, tool)
+			continue
+		}
+		exposed = append(exposed, tool)
+	}
+	return exposed, filtered
+}
+
+// logFilteredTools logs the names of the tools hidden by the filter
+func (s *Server) logFilteredTools(filtered []Tool) {
+	if len(filtered) == 0 {
+		return
+	}
+	names := make([]string, len(filtered))
+	for i, tool := range filtered {
+		names[i] = s.exposedName(tool)
+	}
+	s.logger.Info("Filtered out tools", "count", len(filtered), "tools", names)
+}
+
+// matchAny reports whether name matches any of the patterns, ignoring malformed ones
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
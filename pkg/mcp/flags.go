@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read for the endpoint and credentials when neither a flag nor the config file sets them
+const (
+	EnvEndpoint = "ASGARD_MCP_ENDPOINT"
+	EnvAPIKey   = "ASGARD_MCP_API_KEY"
+
+	EnvOAuth2ClientSecret = "ASGARD_MCP_OAUTH2_CLIENT_SECRET"
+)
+
+// ConfigFlags defines every server option as a flag defaulting to the value of the config file named by -config,
+// so flags given alongside the file take precedence over it
+type ConfigFlags struct {
+	cfg    Config
+	finish func() (Config, error)
+}
+
+// NewConfigFlags loads the config file named by the -config flag in args, if any, on top of the defaults and
+// defines the -config flag and a flag for every option on fs
+func NewConfigFlags(fs *flag.FlagSet, args []string) (*ConfigFlags, error) {
+	f := &ConfigFlags{cfg: DefaultConfig()}
+	if path := configPath(args); path != "" {
+		loaded, err := LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		f.cfg = *loaded
+	}
+	cfg := &f.cfg
+	fs.String("config", "", "Path to a YAML or JSON config file holding any of these options; flags override its values")
+
+	// Define flags for endpoint URL and API key
+	fs.StringVar(&cfg.Endpoint, "endpoint", cfg.Endpoint, "The endpoint URL for the MCP asgard-mcp-server (default $"+EnvEndpoint+")")
+	apiKey := fs.String("api-key", "", "The API key for authentication (default $"+EnvAPIKey+")")
+	fs.StringVar(&cfg.APIKeyFile, "api-key-file", cfg.APIKeyFile, "Read the API key from this file and adopt changes to it without a restart")
+
+	fs.StringVar(&cfg.OAuth2.TokenURL, "oauth2-token-url", cfg.OAuth2.TokenURL, "Obtain bearer tokens from this OAuth2 token endpoint with client credentials instead of sending the API key")
+	fs.StringVar(&cfg.OAuth2.ClientID, "oauth2-client-id", cfg.OAuth2.ClientID, "OAuth2 client ID")
+	oauth2ClientSecret := fs.String("oauth2-client-secret", "", "OAuth2 client secret (default $"+EnvOAuth2ClientSecret+")")
+	oauth2Scopes := fs.String("oauth2-scopes", strings.Join(cfg.OAuth2.Scopes, ","), "Comma-separated OAuth2 scopes to request")
+	authMode := fs.String("auth-mode", string(cfg.AuthMode), "How the API key is sent: api-key (X-API-KEY header) or bearer (Authorization: Bearer)")
+
+	// Define optional flags
+	transport := fs.String("transport", string(cfg.Transport), "How clients connect: stdio, sse, or streamable-http")
+	fs.StringVar(&cfg.ListenAddr, "listen", cfg.ListenAddr, "Address the HTTP transports listen on")
+	invalidTools := fs.String("invalid-tools", string(cfg.InvalidTools), "What to do with manifest tools missing a name or invoke endpoint: skip or error")
+	fs.DurationVar(&cfg.StartupRetry, "startup-retry", cfg.StartupRetry, "Keep retrying initialization with backoff for up to this long before exiting (0 to fail fast)")
+	fs.IntVar(&cfg.StartupAttempts, "startup-attempts", cfg.StartupAttempts, "Give up initialization after this many attempts, whichever of this and -startup-retry comes first (0 for no limit)")
+	fs.DurationVar(&cfg.ProgressInterval, "progress-interval", cfg.ProgressInterval, "Send a progress notification at this interval while a tool call is running, to clients that request progress (0 to disable)")
+	fs.BoolVar(&cfg.LogManifestOrder, "log-manifest-order", cfg.LogManifestOrder, "Keep tools in manifest order in the startup log, reload diffs, and -list-tools instead of sorting them by name; tools/list is always sorted")
+	fs.BoolVar(&cfg.AllowEmpty, "allow-empty", cfg.AllowEmpty, "Serve a manifest declaring no tools with a warning (-allow-empty=false to fail instead)")
+	fs.DurationVar(&cfg.ManifestRefresh, "manifest-refresh", cfg.ManifestRefresh, "Reload the manifest at this interval, updating the tools of connected clients (0 to disable)")
+	allowTools := fs.String("allow-tools", strings.Join(cfg.AllowTools, ","), "Comma-separated tool name patterns to expose, with * wildcards (empty exposes every tool)")
+	denyTools := fs.String("deny-tools", strings.Join(cfg.DenyTools, ","), "Comma-separated tool name patterns never to expose, with * wildcards; deny wins over allow")
+	fs.StringVar(&cfg.ToolPrefix, "tool-prefix", cfg.ToolPrefix, "Prefix added to every tool and prompt name clients see, such as crm_ (empty for none)")
+	unknownArguments := fs.String("unknown-arguments", string(cfg.UnknownArguments), "How arguments not declared in a tool's schema are handled: pass, strip, or reject")
+	fs.BoolVar(&cfg.ValidateArguments, "validate-arguments", cfg.ValidateArguments, "Check tool arguments against the tool's input schema before calling the backend (-validate-arguments=false to trust the backend)")
+	fs.BoolVar(&cfg.ResourceArguments, "resource-arguments", cfg.ResourceArguments, `Serve each tool's latest response as a resource and resolve {"$resource": uri} arguments to resource content`)
+	collapseTools := fs.String("collapse-single-field", strings.Join(cfg.CollapseSingleField, ","), "Comma-separated tool names whose single-key responses are collapsed to the value")
+	fs.Int64Var(&cfg.GzipRequestsMin, "gzip-requests-min", cfg.GzipRequestsMin, "Gzip JSON request bodies of at least this many bytes for tools accepting gzip bodies (0 to disable)")
+	fs.Int64Var(&cfg.GzipUploadsMin, "gzip-uploads-min", cfg.GzipUploadsMin, "Gzip text file uploads of at least this many bytes for tools accepting gzip uploads (0 to disable)")
+	noUploads := fs.String("no-uploads", string(cfg.NoUploads), "Safe mode blocking all file uploads: off, skip (drop upload tools), or reject (reject calls passing files)")
+	fs.BoolVar(&cfg.UploadPathsSchema, "upload-paths-schema", cfg.UploadPathsSchema, "Add the _uploaded_file_paths argument to the schema of upload tools (-upload-paths-schema=false to expose the backend's schema as is)")
+	fs.StringVar(&cfg.UploadPathsField, "upload-paths-field", cfg.UploadPathsField, "Name of the argument carrying the files to upload, for toolsets whose schemas already use the default")
+	binaryTools := fs.String("binary-output", strings.Join(cfg.BinaryOutput, ","), "Comma-separated tool names whose responses are raw bytes mapped to image, audio, text, or resource content by content type")
+	var uploadRoots listFlag
+	fs.Var(&uploadRoots, "upload-root", "Directory local file uploads must stay within, after resolving .. and symlinks (repeatable; unset allows any file)")
+	var uploadURLAllow listFlag
+	fs.Var(&uploadURLAllow, "upload-url-allow", "Host or http(s) URL prefix that URL uploads may fetch, even from private addresses (repeatable; unset allows any public address)")
+	fs.StringVar(&cfg.UploadBaseDir, "upload-base-dir", cfg.UploadBaseDir, "Directory relative upload paths are resolved against (default the working directory)")
+	fs.BoolVar(&cfg.BestEffortUploads, "best-effort-uploads", cfg.BestEffortUploads, "Upload the readable files of a call and list unreadable local files under _skipped_uploads instead of failing the call")
+	fs.Int64Var(&cfg.MaxUploadFileSize, "max-upload-file-size", cfg.MaxUploadFileSize, "Maximum bytes of a single uploaded file (0 for unlimited)")
+	fs.Int64Var(&cfg.MaxUploadTotalSize, "max-upload-total-size", cfg.MaxUploadTotalSize, "Maximum bytes of all files uploaded by one tool call (0 for unlimited)")
+	duplicateFileNames := fs.String("duplicate-file-names", string(cfg.DuplicateFileNames), "How uploaded files sharing a base name are named: keep, index, or path")
+	fs.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "Maximum duration of a backend request including reading the response (0 to disable)")
+	fs.DurationVar(&cfg.CallTimeout, "call-timeout", cfg.CallTimeout, "Maximum duration of a tool call as a whole, retries included (0 to disable)")
+	fs.DurationVar(&cfg.ConnectTimeout, "connect-timeout", cfg.ConnectTimeout, "Maximum time to establish a connection to the backend (0 to disable)")
+	fs.IntVar(&cfg.MaxIdleConns, "max-idle-conns", cfg.MaxIdleConns, "Maximum idle keep-alive connections kept for reuse across all hosts (0 for no limit)")
+	fs.IntVar(&cfg.MaxIdleConnsPerHost, "max-idle-conns-per-host", cfg.MaxIdleConnsPerHost, "Maximum idle keep-alive connections kept for reuse per backend host")
+	fs.DurationVar(&cfg.IdleConnTimeout, "idle-conn-timeout", cfg.IdleConnTimeout, "Close keep-alive connections idle for longer than this (0 to keep them)")
+	fs.DurationVar(&cfg.ResponseHeaderTimeout, "response-header-timeout", cfg.ResponseHeaderTimeout, "Maximum time to wait for backend response headers (0 to disable)")
+	fs.DurationVar(&cfg.BodyReadTimeout, "body-read-timeout", cfg.BodyReadTimeout, "Abort a backend response whose body stalls for longer than this (0 to disable)")
+	fs.DurationVar(&cfg.DNSRefresh, "dns-refresh", cfg.DNSRefresh, "Pin backend DNS resolution and refresh it at this interval, keeping the last answer while DNS fails (0 to disable)")
+	fs.IntVar(&cfg.MaxRedirects, "max-redirects", cfg.MaxRedirects, "Maximum number of redirects followed per backend request (0 to refuse redirects)")
+	fs.IntVar(&cfg.RetryMaxAttempts, "retry-max-attempts", cfg.RetryMaxAttempts, "Total attempts for backend requests failing with transport errors, 429, or 5xx (1 disables retries)")
+	fs.DurationVar(&cfg.RetryBaseDelay, "retry-base-delay", cfg.RetryBaseDelay, "Delay before the first retry, doubled on each further retry")
+	fs.DurationVar(&cfg.RetryMaxDelay, "retry-max-delay", cfg.RetryMaxDelay, "Maximum delay between retries, also capping Retry-After")
+	fs.Float64Var(&cfg.RetryJitter, "retry-jitter", cfg.RetryJitter, "Randomize each retry delay by up to this fraction in either direction (0 to 1)")
+	retryable := fs.String("retryable", strings.Join(cfg.Retryable, ","), "Comma-separated tool names whose calls may be retried, such as idempotent lookups")
+	nonRetryable := fs.String("non-retryable", strings.Join(cfg.NonRetryable, ","), "Comma-separated tool names that are never retried, such as side-effecting tools")
+	fs.IntVar(&cfg.MaxConcurrentCalls, "max-concurrent-calls", cfg.MaxConcurrentCalls, "Maximum number of tool calls executing at once across all endpoints (0 for unlimited)")
+	fs.IntVar(&cfg.CircuitBreakerFailures, "circuit-breaker-failures", cfg.CircuitBreakerFailures, "Fail tool calls fast after this many consecutive calls could not reach an endpoint or got a 5xx (0 to disable)")
+	fs.DurationVar(&cfg.CircuitBreakerCooldown, "circuit-breaker-cooldown", cfg.CircuitBreakerCooldown, "How long calls fail fast once the circuit breaker trips, before a single call probes the endpoint again")
+	var rateLimits listFlag
+	fs.Var(&rateLimits, "rate-limit", "Rate limit of a tool as name=N/s, N/m, or N/h (repeatable)")
+	fs.DurationVar(&cfg.RateLimitWait, "rate-limit-wait", cfg.RateLimitWait, "How long a rate-limited call waits for capacity before failing (0 fails at once)")
+	var toolPriorities listFlag
+	fs.Var(&toolPriorities, "tool-priority", "Priority of a tool in the concurrency queue as name=N, higher first (repeatable)")
+	fs.StringVar(&cfg.ManifestPublicKey, "manifest-public-key", cfg.ManifestPublicKey, "Path to a PEM Ed25519 public key; refuse manifests without a valid signature")
+	fs.StringVar(&cfg.TLSMinVersion, "tls-min-version", cfg.TLSMinVersion, "Lowest TLS version accepted from the backend: 1.0, 1.1, 1.2, or 1.3")
+	fs.BoolVar(&cfg.NoResponseCompression, "no-response-compression", cfg.NoResponseCompression, "Do not request gzip-compressed responses from the backend")
+	fs.StringVar(&cfg.ProxyURL, "proxy-url", cfg.ProxyURL, "Proxy for backend requests as an http, https, or socks5 URL, or direct to bypass proxies (empty honors HTTP_PROXY, HTTPS_PROXY, and NO_PROXY)")
+	fs.StringVar(&cfg.TLSCAFile, "tls-ca-file", cfg.TLSCAFile, "PEM bundle of CA certificates trusted for backend connections in addition to the system roots")
+	fs.StringVar(&cfg.TLSClientCert, "tls-client-cert", cfg.TLSClientCert, "PEM client certificate presented to backends requiring mutual TLS")
+	fs.StringVar(&cfg.TLSClientKey, "tls-client-key", cfg.TLSClientKey, "PEM private key of the client certificate")
+	fs.BoolVar(&cfg.TLSInsecureSkipVerify, "tls-insecure-skip-verify", cfg.TLSInsecureSkipVerify, "DANGEROUS: do not verify backend certificates (local testing only)")
+	tlsCipherSuites := fs.String("tls-cipher-suites", strings.Join(cfg.TLSCipherSuites, ","), "Comma-separated IANA names of the cipher suites allowed for TLS 1.2 and below")
+	argRules := fs.String("arg-rules", "", "Path to a JSON file with generation-keyed argument rules")
+	normalizeRules := fs.String("normalize-rules", "", "Path to a JSON file with per-tool argument normalization rules")
+	var bodyTemplates listFlag
+	fs.Var(&bodyTemplates, "body-template", `JSON request envelope for a tool as name=template, with "<args>" replaced by the arguments (repeatable)`)
+	var toolTags listFlag
+	fs.Var(&toolTags, "tool-tags", "Local tags for a tool as name=tag1,tag2 (repeatable)")
+	fs.BoolVar(&cfg.IncludeRawData, "include-raw-data", cfg.IncludeRawData, "Attach the exact response bytes as an embedded JSON resource after the formatted text")
+	fs.BoolVar(&cfg.RawResponses, "raw-responses", cfg.RawResponses, "Return response bodies verbatim instead of pretty-printed, keeping key order and number formatting")
+	fs.IntVar(&cfg.MaxResponseSize, "max-response-size", cfg.MaxResponseSize, "Maximum bytes of tool result text returned to the client, truncating longer responses (0 for unlimited)")
+	fs.Int64Var(&cfg.MaxBodySize, "max-body-size", cfg.MaxBodySize, "Maximum bytes of a manifest page or buffered tool response read from the backend, failing larger ones (0 for unlimited)")
+	fs.BoolVar(&cfg.StructuredErrors, "structured-errors", cfg.StructuredErrors, "Return tool errors as JSON objects instead of plain text")
+	var headers listFlag
+	fs.Var(&headers, "header", `Extra header sent with every backend request as "Name: value", such as a gateway's tenant ID (repeatable)`)
+	fs.StringVar(&cfg.RequestIDHeader, "request-id-header", cfg.RequestIDHeader, "Response header carrying the backend's request ID, logged with each call (empty to disable)")
+	fs.BoolVar(&cfg.RequestIDMeta, "request-id-meta", cfg.RequestIDMeta, "Add the backend's request ID to tool result metadata as _meta.requestId")
+	fs.BoolVar(&cfg.ErrorDiagnostics, "error-diagnostics", cfg.ErrorDiagnostics, "Append the backend's status, headers, and a capped body snippet to tool errors")
+	fs.IntVar(&cfg.CallLogSize, "call-log-size", cfg.CallLogSize, "Number of recent tool calls kept in memory and dumped on SIGUSR1 (0 to disable)")
+	fs.IntVar(&cfg.MaxLogSize, "max-log-size", cfg.MaxLogSize, "Maximum bytes of response text written to the logs (0 for unlimited)")
+	fs.IntVar(&cfg.CallLogBodyCap, "call-log-body-cap", cfg.CallLogBodyCap, "Maximum bytes of each request/response body kept in the recent call log")
+	fs.BoolVar(&cfg.CallSummary, "call-summary", cfg.CallSummary, "Log per-tool call counts, errors, and latency percentiles on exit")
+	fs.StringVar(&cfg.HealthAddr, "health-addr", cfg.HealthAddr, "Address to serve /healthz and /readyz on, such as :8081 (empty to disable)")
+	fs.DurationVar(&cfg.ReadyMaxAge, "ready-max-age", cfg.ReadyMaxAge, "Fail /readyz when the last successful manifest fetch is older than this (0 to only probe the backend)")
+	fs.StringVar(&cfg.MetricsAddr, "metrics-addr", cfg.MetricsAddr, "Address to serve Prometheus metrics on at /metrics, such as :9090 (empty to disable)")
+	toolPrompts := fs.String("tool-prompts", string(cfg.ToolPrompts), "How prompt-flagged tools are exposed: off, both, or only")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Lowest level of logged records: debug, info, warn, or error")
+	logFormat := fs.String("log-format", string(cfg.LogFormat), "How log records are written: text or json")
+
+	f.finish = func() (Config, error) {
+		// Let -api-key and -oauth2-client-secret override the config file without printing its secrets as defaults
+		if *apiKey != "" {
+			cfg.APIKey = *apiKey
+		}
+		if *oauth2ClientSecret != "" {
+			cfg.OAuth2.ClientSecret = *oauth2ClientSecret
+		}
+		cfg.OAuth2.Scopes = splitList(*oauth2Scopes)
+
+		// Populate the remaining config from flags that need conversion
+		cfg.AuthMode = AuthMode(*authMode)
+		cfg.Transport = Transport(*transport)
+		cfg.InvalidTools = InvalidToolsMode(*invalidTools)
+		cfg.UnknownArguments = UnknownArgumentsMode(*unknownArguments)
+		cfg.ToolPrompts = PromptMode(*toolPrompts)
+		cfg.DuplicateFileNames = DuplicateFileNameMode(*duplicateFileNames)
+		cfg.NoUploads = UploadSafeMode(*noUploads)
+		cfg.LogFormat = LogFormat(*logFormat)
+		cfg.CollapseSingleField = splitList(*collapseTools)
+		cfg.BinaryOutput = splitList(*binaryTools)
+		cfg.TLSCipherSuites = splitList(*tlsCipherSuites)
+		cfg.Retryable = splitList(*retryable)
+		cfg.NonRetryable = splitList(*nonRetryable)
+		if len(uploadRoots) > 0 {
+			cfg.UploadRoots = uploadRoots
+		}
+		if len(uploadURLAllow) > 0 {
+			cfg.UploadURLAllowlist = uploadURLAllow
+		}
+		cfg.AllowTools = splitList(*allowTools)
+		cfg.DenyTools = splitList(*denyTools)
+		if len(toolTags) > 0 {
+			cfg.ToolTags = make(map[string][]string, len(toolTags))
+			for _, entry := range toolTags {
+				name, list, ok := strings.Cut(entry, "=")
+				if !ok || name == "" {
+					return Config{}, fmt.Errorf("invalid -tool-tags value %q, expected name=tag1,tag2", entry)
+				}
+				cfg.ToolTags[name] = append(cfg.ToolTags[name], splitList(list)...)
+			}
+		}
+		if len(bodyTemplates) > 0 {
+			cfg.BodyTemplates = make(map[string]string, len(bodyTemplates))
+			for _, entry := range bodyTemplates {
+				name, template, ok := strings.Cut(entry, "=")
+				if !ok || name == "" {
+					return Config{}, fmt.Errorf("invalid -body-template value %q, expected name=template", entry)
+				}
+				cfg.BodyTemplates[name] = template
+			}
+		}
+		if len(headers) > 0 {
+			if cfg.Headers == nil {
+				cfg.Headers = make(map[string]string, len(headers))
+			}
+			for _, entry := range headers {
+				name, value, err := ParseHeader(entry)
+				if err != nil {
+					return Config{}, fmt.Errorf("invalid -header value: %w", err)
+				}
+				cfg.Headers[name] = value
+			}
+		}
+		if len(toolPriorities) > 0 {
+			cfg.ToolPriorities = make(map[string]int, len(toolPriorities))
+			for _, entry := range toolPriorities {
+				name, value, ok := strings.Cut(entry, "=")
+				priority, err := strconv.Atoi(value)
+				if !ok || name == "" || err != nil {
+					return Config{}, fmt.Errorf("invalid -tool-priority value %q, expected name=N", entry)
+				}
+				cfg.ToolPriorities[name] = priority
+			}
+		}
+		if len(rateLimits) > 0 {
+			cfg.RateLimits = make(map[string]string, len(rateLimits))
+			for _, entry := range rateLimits {
+				name, value, ok := strings.Cut(entry, "=")
+				if !ok || name == "" {
+					return Config{}, fmt.Errorf("invalid -rate-limit value %q, expected name=N/s", entry)
+				}
+				cfg.RateLimits[name] = value
+			}
+		}
+		if *argRules != "" {
+			rules, err := LoadArgumentRules(*argRules)
+			if err != nil {
+				return Config{}, err
+			}
+			cfg.ArgumentRules = rules
+		}
+		if *normalizeRules != "" {
+			rules, err := LoadNormalizeRules(*normalizeRules)
+			if err != nil {
+				return Config{}, err
+			}
+			cfg.NormalizeRules = rules
+		}
+
+		cfg.ApplyEnvironment()
+		return *cfg, nil
+	}
+	return f, nil
+}
+
+// Config returns the options once fs has been parsed: flags over the config file over the defaults, with the
+// environment filling in the endpoint and credentials neither sets
+func (f *ConfigFlags) Config() (Config, error) {
+	return f.finish()
+}
+
+// ApplyEnvironment fills in the endpoint and credentials left unset from the ASGARD_MCP_* environment variables;
+// the API key is only read when no key file or OAuth2 client credentials are configured
+func (c *Config) ApplyEnvironment() {
+	if c.Endpoint == "" {
+		c.Endpoint = os.Getenv(EnvEndpoint)
+	}
+	if c.OAuth2.ClientSecret == "" {
+		c.OAuth2.ClientSecret = os.Getenv(EnvOAuth2ClientSecret)
+	}
+	if c.APIKey == "" && c.APIKeyFile == "" && !c.OAuth2.Enabled() {
+		c.APIKey = os.Getenv(EnvAPIKey)
+	}
+}
+
+// configPath returns the value of the -config flag from the raw arguments, which are parsed after the file is loaded
+func configPath(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// listFlag collects the values of a flag that may be repeated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
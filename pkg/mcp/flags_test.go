@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseConfigFlags parses args the way the server command does and returns the resulting config
+func parseConfigFlags(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	f, err := NewConfigFlags(fs, args)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return f.Config()
+}
+
+func TestConfigFlagsPrecedence(t *testing.T) {
+	t.Setenv(EnvEndpoint, "https://env.example.com/manifest")
+	t.Setenv(EnvAPIKey, "env-key")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, `
+endpoint: https://file.example.com/manifest
+tool_prefix: file_
+timeout: 5s
+allow_empty: false
+headers:
+  X-Tenant-ID: file
+`)
+
+	cfg, err := parseConfigFlags(t, "-config", path, "-tool-prefix", "flag_", "-allow-empty", "-header", "X-Trace: flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag over file", cfg.ToolPrefix, "flag_"},
+		{"flag over file for booleans", cfg.AllowEmpty, true},
+		{"file over default", cfg.Timeout, 5 * time.Second},
+		{"file over environment", cfg.Endpoint, "https://file.example.com/manifest"},
+		{"environment fills in", cfg.APIKey, "env-key"},
+		{"default", cfg.MaxRedirects, DefaultMaxRedirects},
+		{"flag headers merge into file headers", len(cfg.Headers), 2},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestConfigFlagsEnvironmentOnlyFillsUnsetCredentials(t *testing.T) {
+	t.Setenv(EnvEndpoint, "https://env.example.com/manifest")
+	t.Setenv(EnvAPIKey, "env-key")
+
+	cfg, err := parseConfigFlags(t, "-api-key-file", "/run/secrets/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "https://env.example.com/manifest" || cfg.APIKey != "" {
+		t.Errorf("Config() endpoint = %q, key = %q, want the environment endpoint and no key next to a key file", cfg.Endpoint, cfg.APIKey)
+	}
+
+	cfg, err = parseConfigFlags(t, "-endpoint", "https://flag.example.com/manifest", "-api-key", "flag-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "https://flag.example.com/manifest" || cfg.APIKey != "flag-key" {
+		t.Errorf("Config() endpoint = %q, key = %q, want the flags over the environment", cfg.Endpoint, cfg.APIKey)
+	}
+}
+
+func TestConfigFlagsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "endpont: https://typo.example.com\n")
+	if _, err := parseConfigFlags(t, "--config="+path); err == nil || !strings.Contains(err.Error(), "endpont") {
+		t.Errorf("unknown config key: error = %v, want it named", err)
+	}
+	if _, err := parseConfigFlags(t, "-tool-priority", "search"); err == nil || !strings.Contains(err.Error(), "-tool-priority") {
+		t.Errorf("malformed -tool-priority: error = %v, want it named", err)
+	}
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// reservedHeaders are set by the client itself on backend requests
+var reservedHeaders = map[string]bool{
+	"Accept":        true,
+	"Authorization": true,
+	"Content-Type":  true,
+	"X-Api-Key":     true,
+	"Traceparent":   true,
+}
+
+// WithExtraHeaders adds headers to every manifest and tool request sent to the backend, such as a tenant ID
+// required by a gateway; headers the client sets itself are overridden with a warning. Files fetched by URL
+// never receive them
+func WithExtraHeaders(headers map[string]string) APIClientOption {
+	return func(c *APIClient) {
+		if len(headers) == 0 {
+			return
+		}
+		if c.extraHeaders == nil {
+			c.extraHeaders = make(http.Header, len(headers))
+		}
+		for name, value := range headers {
+			if reservedHeaders[http.CanonicalHeaderKey(name)] {
+				c.logger.Warn("Extra header overrides the value the client sets itself", "header", name)
+			}
+			c.extraHeaders.Set(name, value)
+		}
+	}
+}
+
+// applyExtraHeaders sets the configured extra headers on a backend request
+func (c *APIClient) applyExtraHeaders(req *http.Request) {
+	for name, values := range c.extraHeaders {
+		req.Header[name] = append([]string(nil), values...)
+	}
+}
+
+// ParseHeader parses a "Name: value" header as given on the command line
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok {
+		return "", "", fmt.Errorf("invalid header %q, expected Name: value", header)
+	}
+	if err := validateHeader(name, value); err != nil {
+		return "", "", err
+	}
+	return name, value, nil
+}
+
+// validateHeader checks that a header name is a token and its value holds no line breaks
+func validateHeader(name, value string) error {
+	if name == "" || strings.ContainsAny(name, " \t\r\n:()<>@,;\\\"/[]?={}") {
+		return fmt.Errorf("invalid header name %q", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("invalid value for header %s: line breaks are not allowed", name)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// headerRecorder answers manifest and tool requests, recording the headers of each request by method
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers map[string]http.Header
+}
+
+func (h *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	if h.headers == nil {
+		h.headers = make(map[string]http.Header)
+	}
+	h.headers[r.Method] = r.Header.Clone()
+	h.mu.Unlock()
+	if r.Method == http.MethodGet {
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+		return
+	}
+	_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+}
+
+// of returns the headers of the latest request with the given method
+func (h *headerRecorder) of(method string) http.Header {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.headers[method]
+}
+
+func TestExtraHeadersReachTheBackend(t *testing.T) {
+	var recorder headerRecorder
+	backend := httptest.NewServer(&recorder)
+	t.Cleanup(backend.Close)
+	var fileHeaders http.Header
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fileHeaders = r.Header.Clone()
+		_, _ = fmt.Fprint(w, "a")
+	}))
+	t.Cleanup(files.Close)
+
+	var logs bytes.Buffer
+	c := newTestClient(backend.URL,
+		WithClientLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithExtraHeaders(map[string]string{"X-Tenant-ID": "acme", "x-org": "org-1", "Accept": "application/vnd.asgard+json"}),
+		WithUploadURLAllowlist(files.URL+"/"),
+	)
+
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	upload := &Tool{Name: "ingest", AllowUploadFiles: true, InvokeEndpoints: ToolInvokeEndpoints{Form: backend.URL + "/ingest"}}
+	if _, err := c.ExecuteToolRequest(context.Background(), upload, uploadArguments(t, files.URL+"/a.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		header := recorder.of(method)
+		if header.Get("X-Tenant-ID") != "acme" || header.Get("X-Org") != "org-1" || header.Get("X-API-KEY") != "test-key" {
+			t.Errorf("%s request headers = %v, want the extra headers next to the API key", method, header)
+		}
+		if got := header.Values("Accept"); len(got) != 1 || got[0] != "application/vnd.asgard+json" {
+			t.Errorf("%s request Accept = %v, want the configured override only", method, got)
+		}
+	}
+	if !strings.Contains(logs.String(), "header=Accept") {
+		t.Errorf("logs = %q, want a warning about overriding Accept", logs.String())
+	}
+	if fileHeaders == nil || fileHeaders.Get("X-Tenant-ID") != "" {
+		t.Errorf("file fetch headers = %v, want no extra headers on URL uploads", fileHeaders)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := map[string]struct {
+		header      string
+		name, value string
+		wantErr     bool
+	}{
+		"simple":            {header: "X-Tenant-ID: acme", name: "X-Tenant-ID", value: "acme"},
+		"colon in value":    {header: "X-Url:https://a.example", name: "X-Url", value: "https://a.example"},
+		"missing colon":     {header: "X-Tenant-ID acme", wantErr: true},
+		"space in the name": {header: "X Tenant: acme", wantErr: true},
+		"empty name":        {header: ": acme", wantErr: true},
+		"line break":        {header: "X-Tenant-ID: acme\r\nX-Evil: 1", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotName, gotValue, err := ParseHeader(tt.header)
+			if (err != nil) != tt.wantErr || gotName != tt.name || gotValue != tt.value {
+				t.Errorf("ParseHeader(%q) = %q, %q, %v", tt.header, gotName, gotValue, err)
+			}
+		})
+	}
+}
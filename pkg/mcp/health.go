@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Paths served by the health server
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// readyProbeTimeout bounds the backend probe of a readiness check
+const readyProbeTimeout = 5 * time.Second
+
+// WithHealthCheck serves /healthz and /readyz on addr while serving; /readyz fails when a backend is unreachable
+// or, when maxAge is positive, when its last successful manifest fetch is older than maxAge. An empty addr
+// disables the health server
+func WithHealthCheck(addr string, maxAge time.Duration) ServerOption {
+	return func(s *Server) {
+		s.healthAddr = addr
+		s.readyMaxAge = maxAge
+	}
+}
+
+// probe checks that the backend answers the manifest URL, with HEAD and then GET for backends refusing HEAD
+func (c *APIClient) probe(ctx context.Context) error {
+	status, err := c.probeWith(ctx, http.MethodHead)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.probeWith(ctx, http.MethodGet)
+	}
+	if err != nil {
+		return c.redactError(err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", status)
+	}
+	return nil
+}
+
+// probeWith requests the manifest URL with the given method and returns the response status
+func (c *APIClient) probeWith(ctx context.Context, method string) (int, error) {
+	req, err := http.NewRequestWithContext(withoutRetries(ctx), method, c.baseURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("accept", "application/json")
+	if err := c.authorize(req); err != nil {
+		return 0, err
+	}
+	c.applyExtraHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// ready reports why the server is not ready to serve tool calls, or nil when every endpoint is reachable and its
+// manifest fresh enough
+func (s *Server) ready(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readyProbeTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, ep := range s.endpoints {
+		if s.readyMaxAge > 0 {
+			if last := ep.client.lastManifestFetch(); last.IsZero() || time.Since(last) > s.readyMaxAge {
+				errs = append(errs, fmt.Errorf("%s: no successful manifest fetch within %s", redactURL(ep.URL), s.readyMaxAge))
+				continue
+			}
+		}
+		if err := ep.client.probe(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: backend unreachable: %w", redactURL(ep.URL), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// serveHealth serves the health endpoints in the background until the returned function is called
+func (s *Server) serveHealth() func() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc(ReadyzPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := s.ready(r.Context()); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintf(w, "not ready: %v\n", err)
+			return
+		}
+		_, _ = io.WriteString(w, "ready\n")
+	})
+	httpServer := &http.Server{
+		Addr:              s.healthAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		s.logger.Info("Serving health checks", "addr", s.healthAddr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Health server failed", "error", err)
+		}
+	}()
+	return func() { _ = httpServer.Close() }
+}
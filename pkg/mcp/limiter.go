@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"container/heap"
+	"context"
+	"strings"
+	"sync"
+)
+
+// Tool priorities derived from manifest tags
+const (
+	PriorityLow    = -1
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+// callLimiter bounds concurrent tool calls, handing freed slots to the highest-priority waiter first
+type callLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	seq     uint64
+	waiters waiterQueue
+}
+
+// newCallLimiter creates a limiter allowing up to limit concurrent calls
+func newCallLimiter(limit int) *callLimiter {
+	return &callLimiter{limit: limit}
+}
+
+// withCallLimiter makes the client take its call slots from limiter, shared with other clients; nil means unlimited
+func withCallLimiter(limiter *callLimiter) APIClientOption {
+	return func(c *APIClient) {
+		c.limiter = limiter
+	}
+}
+
+// acquire blocks until a slot is free or ctx is done
+func (l *callLimiter) acquire(ctx context.Context, priority int) error {
+	l.mu.Lock()
+	if l.inUse < l.limit && len(l.waiters) == 0 {
+		l.inUse++
+		l.mu.Unlock()
+		return nil
+	}
+
+	// Queue up behind other waiters
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// The slot was handed over while giving up; pass it on
+			l.releaseLocked()
+		} else {
+			heap.Remove(&l.waiters, w.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it directly to the next waiter if any
+func (l *callLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *callLimiter) releaseLocked() {
+	if len(l.waiters) > 0 {
+		w := heap.Pop(&l.waiters).(*waiter)
+		w.granted = true
+		close(w.ready)
+		return
+	}
+	l.inUse--
+}
+
+// waiter is a call queued for a slot
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	granted  bool
+	ready    chan struct{}
+}
+
+// waiterQueue orders waiters by descending priority, then arrival
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return w
+}
+
+// toolPriority returns the configured priority of a tool, falling back to its priority:* manifest tag
+func (c *APIClient) toolPriority(tool *Tool) int {
+	if p, ok := c.toolPriorities[tool.Name]; ok {
+		return p
+	}
+	for _, tag := range tool.Tags {
+		switch strings.ToLower(tag) {
+		case "priority:high":
+			return PriorityHigh
+		case "priority:low":
+			return PriorityLow
+		}
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyBackend is a fake Asgard backend serving a manifest per path and recording the highest number of
+// tool calls it handled at once
+type concurrencyBackend struct {
+	*httptest.Server
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+// newConcurrencyBackend starts a backend whose manifests at /a and /b declare a "work" tool taking a while to answer
+func newConcurrencyBackend(t *testing.T) *concurrencyBackend {
+	t.Helper()
+	b := &concurrencyBackend{}
+	mux := http.NewServeMux()
+	manifest := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[`+
+			`{"name":"work","description":"Work","input_schema":{"type":"object"},"invoke_endpoints":{"json":%q}}]}}`,
+			b.URL+"/work")
+	}
+	mux.HandleFunc("/a", manifest)
+	mux.HandleFunc("/b", manifest)
+	mux.HandleFunc("/work", func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		b.inFlight++
+		b.maxInFlight = max(b.maxInFlight, b.inFlight)
+		b.mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		b.mu.Lock()
+		b.inFlight--
+		b.mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	})
+	b.Server = httptest.NewServer(mux)
+	t.Cleanup(b.Close)
+	return b
+}
+
+func TestMaxConcurrentCallsIsSharedAcrossEndpoints(t *testing.T) {
+	const limit = 2
+	backend := newConcurrencyBackend(t)
+	s, err := NewServer(backend.URL+"/a", "test-key",
+		WithLogger(discardLogger),
+		WithAPIClientOptions(WithMaxConcurrentCalls(limit)),
+		WithEndpoints(Endpoint{URL: backend.URL + "/b", APIKey: "other-key", Prefix: "b_"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.tools) != 2 {
+		t.Fatalf("server has %d tools, want one per endpoint", len(s.tools))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, tool := range s.tools {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.clientFor(tool).ExecuteToolRequest(context.Background(), &tool, []byte(`{}`)); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if backend.maxInFlight > limit {
+		t.Errorf("backend handled %d calls at once, want at most %d", backend.maxInFlight, limit)
+	}
+}
+
+// waitForWaiters blocks until n calls are queued on the limiter
+func waitForWaiters(t *testing.T, l *callLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		l.mu.Lock()
+		queued := len(l.waiters)
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d calls queued, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCallLimiterRunsHighPriorityFirst(t *testing.T) {
+	l := newCallLimiter(1)
+	if err := l.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue the calls one by one so their arrival order is known
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	calls := []struct {
+		name     string
+		priority int
+	}{
+		{"low", PriorityLow},
+		{"normal", PriorityNormal},
+		{"high-1", PriorityHigh},
+		{"high-2", PriorityHigh},
+	}
+	for i, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.acquire(context.Background(), call.priority); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, call.name)
+			mu.Unlock()
+			l.release()
+		}()
+		waitForWaiters(t, l, i+1)
+	}
+
+	l.release()
+	wg.Wait()
+	if fmt.Sprint(order) != "[high-1 high-2 normal low]" {
+		t.Errorf("calls ran in order %v, want by priority and then arrival", order)
+	}
+}
+
+func TestCallLimiterDropsCanceledWaiters(t *testing.T) {
+	l := newCallLimiter(1)
+	if err := l.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.acquire(ctx, PriorityHigh) }()
+	waitForWaiters(t, l, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire() = %v, want context.Canceled", err)
+	}
+	waitForWaiters(t, l, 0)
+
+	// The slot goes back to the pool rather than to the canceled waiter
+	l.release()
+	if err := l.acquire(context.Background(), PriorityLow); err != nil {
+		t.Fatal(err)
+	}
+}
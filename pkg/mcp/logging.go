@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// LogFormat selects how log records are encoded
+type LogFormat string
+
+const (
+	// LogFormatText writes key=value records readable in a terminal
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per record for log pipelines
+	LogFormatJSON LogFormat = "json"
+)
+
+// DefaultLogLevel is the level logged when none is configured
+const DefaultLogLevel = "info"
+
+// ParseLogLevel parses a level name: debug, info, warn, or error
+func ParseLogLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "error":
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return 0, err
+		}
+		return l, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn, or error", level)
+}
+
+// NewLogger creates a structured logger writing records of at least the given level to w in the given format
+func NewLogger(w io.Writer, format LogFormat, level string) (*slog.Logger, error) {
+	l, err := ParseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: l}
+	switch format {
+	case LogFormatText, "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, expected text or json", format)
+}
+
+// WithLogger sets the logger for every record of the server and its API clients, slog.Default() by default
+func WithLogger(logger *slog.Logger) ServerOption {
+	return func(s *Server) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithClientLogger sets the logger for the client's manifest fetches, retries, and tool calls, slog.Default() by
+// default
+func WithClientLogger(logger *slog.Logger) APIClientOption {
+	return func(c *APIClient) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// emptyInputSchema is used for tools whose manifest entry has no input schema
+var emptyInputSchema = json.RawMessage(`{"type":"object","properties":{}}`)
+
+// validateTool checks that a manifest tool has the fields needed to register and invoke it
+func validateTool(tool *Tool) error {
+	var problems []error
+	if tool.Name == "" {
+		problems = append(problems, errors.New("missing name"))
+	}
+	if tool.AllowUploadFiles && tool.InvokeEndpoints.Form == "" {
+		problems = append(problems, errors.New("allows file uploads but has no form invoke endpoint"))
+	}
+	if !tool.AllowUploadFiles && tool.InvokeEndpoints.JSON == "" {
+		problems = append(problems, errors.New("missing JSON invoke endpoint"))
+	}
+	if len(tool.InputSchema) > 0 && !bytes.Equal(bytes.TrimSpace(tool.InputSchema), []byte("null")) && !json.Valid(tool.InputSchema) {
+		problems = append(problems, errors.New("input schema is not valid JSON"))
+	}
+	return errors.Join(problems...)
+}
+
+// validateManifestTools drops or rejects tools missing required fields according to the client's policy
+func (c *APIClient) validateManifestTools(tools []Tool) ([]Tool, error) {
+	valid := make([]Tool, 0, len(tools))
+	for i := range tools {
+		tool := tools[i]
+
+		if err := validateTool(&tool); err != nil {
+			label := tool.Name
+			if label == "" {
+				label = fmt.Sprintf("#%d", i)
+			}
+			if c.invalidTools == InvalidToolsError {
+				return nil, fmt.Errorf("invalid tool %s in manifest: %w", label, err)
+			}
+			c.logger.Warn("Skipping invalid manifest tool", "tool", label, "error", err)
+			continue
+		}
+
+		// A missing schema means the tool takes no arguments
+		if len(tool.InputSchema) == 0 || bytes.Equal(bytes.TrimSpace(tool.InputSchema), []byte("null")) {
+			c.logger.Info("Tool has no input schema, assuming no arguments", "tool", tool.Name)
+			tool.InputSchema = emptyInputSchema
+		}
+
+		valid = append(valid, tool)
+	}
+	return valid, nil
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"net/http"
+	"sync"
+)
+
+// manifestCache remembers the last manifest with its validators, so refreshes can be conditional requests
+type manifestCache struct {
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	manifest     *ToolsetManifest
+}
+
+// conditional adds If-None-Match and If-Modified-Since to a manifest request when a manifest is cached
+func (m *manifestCache) conditional(req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.manifest == nil {
+		return
+	}
+	if m.etag != "" {
+		req.Header.Set("If-None-Match", m.etag)
+	}
+	if m.lastModified != "" {
+		req.Header.Set("If-Modified-Since", m.lastModified)
+	}
+}
+
+// cached returns a copy of the cached manifest, or nil when none is cached
+func (m *manifestCache) cached() *ToolsetManifest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.manifest == nil {
+		return nil
+	}
+	manifest := *m.manifest
+	manifest.Tools = append([]Tool(nil), m.manifest.Tools...)
+	return &manifest
+}
+
+// store caches a freshly parsed manifest with the validators of its response; responses without validators are
+// not cached since they cannot be revalidated
+func (m *manifestCache) store(resp *http.Response, manifest *ToolsetManifest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.etag = resp.Header.Get("ETag")
+	m.lastModified = resp.Header.Get("Last-Modified")
+	m.manifest = nil
+	if m.etag != "" || m.lastModified != "" {
+		manifest := *manifest
+		manifest.Tools = append([]Tool(nil), manifest.Tools...)
+		m.manifest = &manifest
+	}
+}
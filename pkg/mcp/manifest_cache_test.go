@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// conditionalBackend serves a one-tool manifest with the given validators, answering 304 to requests that
+// revalidate them, and records the conditional headers of every request
+type conditionalBackend struct {
+	*httptest.Server
+	mu          sync.Mutex
+	conditional []string
+	notModified int
+}
+
+func newConditionalBackend(t *testing.T, etag, lastModified string) *conditionalBackend {
+	t.Helper()
+	b := &conditionalBackend{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch, ifModifiedSince := r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")
+		b.mu.Lock()
+		b.conditional = append(b.conditional, ifNoneMatch+"|"+ifModifiedSince)
+		b.mu.Unlock()
+
+		if (etag != "" && ifNoneMatch == etag) || (lastModified != "" && ifModifiedSince == lastModified) {
+			b.mu.Lock()
+			b.notModified++
+			b.mu.Unlock()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		if lastModified != "" {
+			w.Header().Set("Last-Modified", lastModified)
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":3,"tools":[`+
+			`{"name":"search","description":"Search","invoke_endpoints":{"json":"http://backend.invalid/search"}}]}}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+func TestNotModifiedReusesTheCachedManifest(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2026 15:04:05 GMT"
+	tests := map[string]struct {
+		etag, lastModified string
+		wantConditional    string
+	}{
+		"etag":          {etag: `"v1"`, wantConditional: `"v1"|`},
+		"last modified": {lastModified: lastModified, wantConditional: "|" + lastModified},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newConditionalBackend(t, tt.etag, tt.lastModified)
+			c := newTestClient(backend.URL)
+
+			first, err := c.FetchToolsetManifest(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := c.FetchToolsetManifest(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			backend.mu.Lock()
+			defer backend.mu.Unlock()
+			if backend.notModified != 1 {
+				t.Fatalf("backend answered %d requests with 304, want the refresh to", backend.notModified)
+			}
+			if want := []string{"|", tt.wantConditional}; fmt.Sprint(backend.conditional) != fmt.Sprint(want) {
+				t.Errorf("conditional headers = %q, want %q", backend.conditional, want)
+			}
+			if second.Generation != 3 || len(second.Tools) != 1 || second.Tools[0].Name != "search" {
+				t.Errorf("manifest after 304 = %+v, want the cached manifest", second)
+			}
+			if &second.Tools[0] == &first.Tools[0] {
+				t.Error("manifest after 304 shares its tools with the first fetch, want a copy")
+			}
+		})
+	}
+}
+
+func TestManifestsWithoutValidatorsAreRefetched(t *testing.T) {
+	backend := newConditionalBackend(t, "", "")
+	c := newTestClient(backend.URL)
+	for range 2 {
+		if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if want := []string{"|", "|"}; fmt.Sprint(backend.conditional) != fmt.Sprint(want) {
+		t.Errorf("conditional headers = %q, want none without validators", backend.conditional)
+	}
+}
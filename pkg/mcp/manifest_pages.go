@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxManifestPages bounds the pages followed for one manifest, guarding against a backend that never stops paging
+const maxManifestPages = 100
+
+// manifestPage is the data of one manifest response; large toolsets are split into pages linked by a next cursor
+// or page number
+type manifestPage struct {
+	Namespace  string           `json:"namespace"`
+	Name       string           `json:"name"`
+	Generation int              `json:"generation"`
+	Tools      []manifestTool   `json:"tools"`
+	Resources  []Resource       `json:"resources"`
+	Prompts    []PromptTemplate `json:"prompts"`
+
+	// NextCursor is sent back as the cursor query parameter to fetch the next page
+	NextCursor string `json:"next_cursor"`
+	// NextPage is sent as the page query parameter to fetch the next page, when there is no cursor
+	NextPage int `json:"next_page"`
+}
+
+// manifestTool is a tool as declared in the manifest
+type manifestTool struct {
+	Name              string          `json:"name"`
+	Title             string          `json:"title"`
+	DisplayName       string          `json:"display_name"`
+	Description       string          `json:"description"`
+	InputSchema       json.RawMessage `json:"input_schema"`
+	AllowUploadFiles  bool            `json:"allow_upload_files"`
+	Prompt            bool            `json:"prompt"`
+	Tags              []string        `json:"tags"`
+	AcceptGzipUploads bool            `json:"accept_gzip_uploads"`
+	AcceptGzipBody    bool            `json:"accept_gzip_body"`
+	Retryable         bool            `json:"retryable"`
+	NonRetryable      bool            `json:"non_retryable"`
+	BinaryOutput      bool            `json:"binary_output"`
+	Annotations       ToolAnnotations `json:"annotations"`
+	TimeoutSeconds    float64         `json:"timeout_seconds"`
+	InvokeEndpoints   struct {
+		JSON string `json:"json"`
+		Form string `json:"form"`
+	} `json:"invoke_endpoints"`
+}
+
+// tool converts the manifest declaration into a Tool
+func (t manifestTool) tool() Tool {
+	return Tool{
+		Name:              t.Name,
+		Title:             cmp.Or(t.Title, t.DisplayName),
+		Description:       t.Description,
+		InputSchema:       t.InputSchema,
+		AllowUploadFiles:  t.AllowUploadFiles,
+		Prompt:            t.Prompt,
+		Tags:              t.Tags,
+		AcceptGzipUploads: t.AcceptGzipUploads,
+		AcceptGzipBody:    t.AcceptGzipBody,
+		Retryable:         t.Retryable,
+		NonRetryable:      t.NonRetryable,
+		BinaryOutput:      t.BinaryOutput,
+		Annotations:       t.Annotations,
+		InvokeEndpoints: ToolInvokeEndpoints{
+			JSON: t.InvokeEndpoints.JSON,
+			Form: t.InvokeEndpoints.Form,
+		},
+		Timeout: max(0, time.Duration(t.TimeoutSeconds*float64(time.Second))),
+	}
+}
+
+// nextManifestPageURL returns the manifest URL of the page after the given one, or "" when it is the last page
+func nextManifestPageURL(baseURL string, page *manifestPage) (string, error) {
+	if page.NextCursor == "" && page.NextPage <= 0 {
+		return "", nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse manifest URL: %w", err)
+	}
+	query := u.Query()
+	if page.NextCursor != "" {
+		query.Set("cursor", page.NextCursor)
+	} else {
+		query.Set("page", strconv.Itoa(page.NextPage))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newPagedBackend starts a backend serving manifest pages chosen by page from the query of each request, and
+// recording the queries it receives
+func newPagedBackend(t *testing.T, page func(query url.Values) string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,%s}}`, page(r.URL.Query()))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), queries...)
+	}
+}
+
+// pageTools declares tools with the given names as a manifest tools field
+func pageTools(names ...string) string {
+	var tools []string
+	for _, name := range names {
+		tools = append(tools, fmt.Sprintf(`{"name":%q,"description":"Tool","invoke_endpoints":{"json":"http://backend.invalid/%s"}}`, name, name))
+	}
+	return `"tools":[` + strings.Join(tools, ",") + `]`
+}
+
+func TestManifestPagesAreAccumulated(t *testing.T) {
+	tests := map[string]struct {
+		page        func(query url.Values) string
+		wantQueries []string
+	}{
+		"cursor": {
+			page: func(query url.Values) string {
+				if !query.Has("cursor") {
+					return pageTools("a", "b") + `,"next_cursor":"c2"`
+				}
+				return pageTools("c")
+			},
+			wantQueries: []string{"namespace=ns", "cursor=c2&namespace=ns"},
+		},
+		"page number": {
+			page: func(query url.Values) string {
+				if !query.Has("page") {
+					return pageTools("a", "b") + `,"next_page":2`
+				}
+				return pageTools("c")
+			},
+			wantQueries: []string{"namespace=ns", "namespace=ns&page=2"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend, queries := newPagedBackend(t, tt.page)
+			c := newTestClient(backend.URL + "/manifest?namespace=ns")
+
+			manifest, err := c.FetchToolsetManifest(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, tool := range manifest.Tools {
+				names = append(names, tool.Name)
+			}
+			if fmt.Sprint(names) != "[a b c]" {
+				t.Errorf("manifest tools = %v, want the tools of both pages", names)
+			}
+			if fmt.Sprint(queries()) != fmt.Sprint(tt.wantQueries) {
+				t.Errorf("backend received queries %q, want %q", queries(), tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestManifestPaginationStopsOnMisbehavingBackends(t *testing.T) {
+	tests := map[string]struct {
+		page    func(query url.Values) string
+		wantErr string
+	}{
+		"loop": {
+			page: func(query url.Values) string {
+				return pageTools("a") + `,"next_cursor":"same"`
+			},
+			wantErr: "manifest pagination loops back to",
+		},
+		"endless": {
+			page: func(query url.Values) string {
+				next, _ := strconv.Atoi(query.Get("page"))
+				return pageTools(fmt.Sprintf("t%d", next)) + fmt.Sprintf(`,"next_page":%d`, next+1)
+			},
+			wantErr: fmt.Sprintf("manifest has more than %d pages", maxManifestPages),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend, queries := newPagedBackend(t, tt.page)
+			c := newTestClient(backend.URL)
+
+			_, err := c.FetchToolsetManifest(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FetchToolsetManifest() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := len(queries()); got > maxManifestPages {
+				t.Errorf("backend received %d requests, want at most %d", got, maxManifestPages)
+			}
+		})
+	}
+}
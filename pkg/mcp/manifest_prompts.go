@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// PromptTemplate is a reusable prompt of the toolset, exposed through the MCP prompts capability
+type PromptTemplate struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Arguments   []PromptArgument `json:"arguments"`
+	// Template is the prompt text; {{argument}} placeholders are replaced by the argument values
+	Template string `json:"template"`
+}
+
+// PromptArgument is an argument of a prompt template
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// promptPlaceholder matches the {{argument}} placeholders of a prompt template
+var promptPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// validatePromptTemplate checks that a manifest prompt can be registered
+func validatePromptTemplate(p PromptTemplate) error {
+	var problems []string
+	if p.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if p.Template == "" {
+		problems = append(problems, "template is required")
+	}
+	for i, arg := range p.Arguments {
+		if arg.Name == "" {
+			problems = append(problems, fmt.Sprintf("arguments[%d]: name is required", i))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateManifestPrompts drops prompts that cannot be registered, logging why
+func (c *APIClient) validateManifestPrompts(prompts []PromptTemplate) []PromptTemplate {
+	valid := make([]PromptTemplate, 0, len(prompts))
+	for i, p := range prompts {
+		if err := validatePromptTemplate(p); err != nil {
+			label := p.Name
+			if label == "" {
+				label = fmt.Sprintf("#%d", i)
+			}
+			c.logger.Warn("Skipping invalid manifest prompt", "prompt", label, "error", err)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	return valid
+}
+
+// Render fills the template with the given arguments, failing when a required argument is missing; placeholders
+// of omitted optional arguments render empty
+func (p PromptTemplate) Render(args map[string]string) (string, error) {
+	var missing []string
+	for _, arg := range p.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("prompt %s is missing required arguments: %s", p.Name, strings.Join(missing, ", "))
+	}
+
+	return promptPlaceholder.ReplaceAllStringFunc(p.Template, func(placeholder string) string {
+		return args[promptPlaceholder.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// newManifestPrompt maps a manifest prompt to an MCP prompt definition with the given name
+func newManifestPrompt(name string, p PromptTemplate) mcp.Prompt {
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(p.Description)}
+	for _, arg := range p.Arguments {
+		var argOpts []mcp.ArgumentOption
+		if arg.Description != "" {
+			argOpts = append(argOpts, mcp.ArgumentDescription(arg.Description))
+		}
+		if arg.Required {
+			argOpts = append(argOpts, mcp.RequiredArgument())
+		}
+		opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+	}
+	return mcp.NewPrompt(name, opts...)
+}
+
+// registerManifestPrompts registers the prompts of every endpoint's manifest
+func (s *Server) registerManifestPrompts() {
+	if prompts := s.buildManifestPrompts(); len(prompts) > 0 {
+		s.mcpServer.AddPrompts(prompts...)
+	}
+}
+
+// buildManifestPrompts stages the prompts of every endpoint's manifest under the endpoint's prefix, skipping
+// names already taken
+func (s *Server) buildManifestPrompts() []server.ServerPrompt {
+	var prompts []server.ServerPrompt
+	seen := make(map[string]bool)
+	for _, ep := range s.endpoints {
+		for _, p := range ep.prompts {
+			name := ep.Prefix + p.Name
+			if seen[name] {
+				s.logger.Warn("Skipping prompt, the name is already taken by another endpoint", "prompt", name, "endpoint", redactURL(ep.URL))
+				continue
+			}
+			seen[name] = true
+
+			template := p
+			prompts = append(prompts, server.ServerPrompt{
+				Prompt: newManifestPrompt(name, template),
+				Handler: func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+					text, err := template.Render(req.Params.Arguments)
+					if err != nil {
+						return nil, err
+					}
+					return mcp.NewGetPromptResult(template.Description, []mcp.PromptMessage{
+						mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+					}), nil
+				},
+			})
+		}
+	}
+	return prompts
+}
+
+// manifestPromptTemplates returns the prompt templates of every endpoint, for telling whether a reload changed them
+func (s *Server) manifestPromptTemplates() [][]PromptTemplate {
+	templates := make([][]PromptTemplate, len(s.endpoints))
+	for i, ep := range s.endpoints {
+		templates[i] = ep.prompts
+	}
+	return templates
+}
+
+// hasManifestPrompts reports whether any endpoint's manifest declares prompts
+func (s *Server) hasManifestPrompts() bool {
+	for _, ep := range s.endpoints {
+		if len(ep.prompts) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Resource is a read-only data source of the toolset, such as a document or dataset, exposed as an MCP resource
+type Resource struct {
+	// URI identifies a fixed resource; URITemplate describes parameterized resources as an RFC 6570 template
+	URI         string `json:"uri"`
+	URITemplate string `json:"uri_template"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mime_type"`
+	// ReadEndpoint is fetched to read the resource; {variable} placeholders are filled from the URI template
+	ReadEndpoint string `json:"read_endpoint"`
+}
+
+// validateResource checks that a manifest resource can be registered and read
+func validateResource(r Resource) error {
+	var problems []string
+	if r.URI == "" && r.URITemplate == "" {
+		problems = append(problems, "uri or uri_template is required")
+	}
+	if r.URI != "" && r.URITemplate != "" {
+		problems = append(problems, "uri and uri_template are mutually exclusive")
+	}
+	if r.ReadEndpoint == "" {
+		problems = append(problems, "read_endpoint is required")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateManifestResources drops resources that cannot be registered, logging why
+func (c *APIClient) validateManifestResources(resources []Resource) []Resource {
+	valid := make([]Resource, 0, len(resources))
+	for i, r := range resources {
+		if err := validateResource(r); err != nil {
+			label := r.Name
+			if label == "" {
+				label = fmt.Sprintf("#%d", i)
+			}
+			c.logger.Warn("Skipping invalid manifest resource", "resource", label, "error", err)
+			continue
+		}
+		if r.Name == "" {
+			r.Name = r.URI + r.URITemplate
+		}
+		valid = append(valid, r)
+	}
+	return valid
+}
+
+// ReadResource fetches a resource from its read endpoint, unwrapping responses in the Asgard format
+func (c *APIClient) ReadResource(ctx context.Context, endpoint string) (data []byte, contentType string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "*/*")
+	if err := c.authorize(req); err != nil {
+		return nil, "", err
+	}
+	c.applyExtraHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", c.redactError(fmt.Errorf("failed to execute request: %w", err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	requestID := c.requestID(resp)
+
+	body, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", &APIError{StatusCode: resp.StatusCode, Code: errorCodeOf(body), Body: c.redact(string(body)), Header: resp.Header, RequestID: requestID}
+	}
+
+	contentType = resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "json") {
+		return body, contentType, nil
+	}
+	parsed, err := c.parseAsgardResponse(resp, body, requestID)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsed.Data, contentType, nil
+}
+
+// expandReadEndpoint fills the {variable} placeholders of a read endpoint with the escaped template variables
+func expandReadEndpoint(endpoint string, variables map[string]any) string {
+	for name, value := range variables {
+		var text string
+		switch v := value.(type) {
+		case []string:
+			text = strings.Join(v, ",")
+		default:
+			text = fmt.Sprint(v)
+		}
+		endpoint = strings.ReplaceAll(endpoint, "{"+name+"}", url.PathEscape(text))
+	}
+	return endpoint
+}
+
+// resourceHandler reads a manifest resource through the client of the endpoint it came from
+func (s *Server) resourceHandler(ep *endpoint, res Resource) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		data, contentType, err := ep.client.ReadResource(ctx, expandReadEndpoint(res.ReadEndpoint, req.Params.Arguments))
+		if err != nil {
+			s.logger.Error("Resource read failed", "uri", req.Params.URI, "error", err)
+			return nil, fmt.Errorf("failed to read resource %s: %w", req.Params.URI, err)
+		}
+
+		mimeType := res.MimeType
+		if mimeType == "" {
+			mimeType = contentType
+		}
+		if mimeType == "" || textualMimeType(mimeType) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: mimeType, Text: string(data)}}, nil
+		}
+		return []mcp.ResourceContents{mcp.BlobResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		}}, nil
+	}
+}
+
+// registerManifestResources registers the resources of every endpoint's manifest, skipping URIs already taken
+func (s *Server) registerManifestResources() {
+	seen := make(map[string]bool)
+	for _, ep := range s.endpoints {
+		for _, res := range ep.resources {
+			key := res.URI + res.URITemplate
+			if seen[key] {
+				s.logger.Warn("Skipping resource, the URI is already taken by another endpoint", "uri", key, "endpoint", redactURL(ep.URL))
+				continue
+			}
+			seen[key] = true
+
+			if res.URITemplate != "" {
+				template := mcp.NewResourceTemplate(res.URITemplate, res.Name,
+					mcp.WithTemplateDescription(res.Description),
+					mcp.WithTemplateMIMEType(res.MimeType),
+				)
+				s.mcpServer.AddResourceTemplate(template, s.resourceHandler(ep, res))
+				continue
+			}
+			resource := mcp.NewResource(res.URI, res.Name,
+				mcp.WithResourceDescription(res.Description),
+				mcp.WithMIMEType(res.MimeType),
+			)
+			s.mcpServer.AddResource(resource, server.ResourceHandlerFunc(s.resourceHandler(ep, res)))
+		}
+	}
+}
+
+// hasManifestResources reports whether any endpoint's manifest declares resources
+func (s *Server) hasManifestResources() bool {
+	for _, ep := range s.endpoints {
+		if len(ep.resources) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// handbookResources declares a fixed JSON resource, a templated dataset resource, and a binary logo for
+// newManifestSectionBackend, with $BACKEND standing for the backend's URL
+const handbookResources = `"resources":[` +
+	`{"uri":"asgard://docs/handbook","name":"Handbook","description":"Team handbook","mime_type":"application/json","read_endpoint":"$BACKEND/docs/handbook"},` +
+	`{"uri_template":"asgard://datasets/{id}","name":"Dataset","mime_type":"text/csv","read_endpoint":"$BACKEND/datasets/{id}"},` +
+	`{"uri":"asgard://logo","name":"Logo","mime_type":"image/png","read_endpoint":"$BACKEND/logo"},` +
+	`{"name":"broken","read_endpoint":"$BACKEND/broken"}]`
+
+// manifestSectionBackend serves a manifest with the search tool and a replaceable section, such as its resources
+// or prompts, and answers the read endpoints of handbookResources
+type manifestSectionBackend struct {
+	*httptest.Server
+	mu         sync.Mutex
+	section    string
+	generation int
+}
+
+// newManifestSectionBackend starts a backend whose manifest data carries the given JSON member after the tools
+func newManifestSectionBackend(t *testing.T, section string) *manifestSectionBackend {
+	t.Helper()
+	b := &manifestSectionBackend{section: section, generation: 1}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/manifest":
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			manifest := fmt.Sprintf(`{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":%d,"tools":[%s],%s}}`,
+				b.generation, searchTool, b.section)
+			_, _ = w.Write([]byte(strings.ReplaceAll(manifest, backendPlaceholder, "http://"+r.Host)))
+		case r.URL.Path == "/docs/handbook":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"title":"Handbook"}}`)
+		case strings.HasPrefix(r.URL.Path, "/datasets/"):
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = fmt.Fprintf(w, "id\n%s\n", strings.TrimPrefix(r.URL.Path, "/datasets/"))
+		case r.URL.Path == "/logo":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// setSection replaces the section of the manifest under a new generation
+func (b *manifestSectionBackend) setSection(section string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.section = section
+	b.generation++
+}
+
+// resourceContents is a decoded entry of a resources/read result
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Blob     string `json:"blob"`
+}
+
+// resourceRequest sends a resources request through the server's MCP handler and decodes its result into out,
+// failing the test on a JSON-RPC error
+func resourceRequest(t *testing.T, s *Server, method string, params map[string]interface{}, out interface{}) {
+	t.Helper()
+	request, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := json.Marshal(s.mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Error != nil {
+		t.Fatalf("%s failed: %s", method, envelope.Error.Message)
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		t.Fatalf("%s returned %s: %v", method, envelope.Result, err)
+	}
+}
+
+// listResourceURIs returns the URIs of fixed resources and the URI templates the server lists
+func listResourceURIs(t *testing.T, s *Server) (uris, templates []string) {
+	t.Helper()
+	var resources struct {
+		Resources []struct {
+			URI string `json:"uri"`
+		} `json:"resources"`
+	}
+	resourceRequest(t, s, "resources/list", nil, &resources)
+	for _, r := range resources.Resources {
+		uris = append(uris, r.URI)
+	}
+	var list struct {
+		ResourceTemplates []struct {
+			URITemplate string `json:"uriTemplate"`
+		} `json:"resourceTemplates"`
+	}
+	resourceRequest(t, s, "resources/templates/list", nil, &list)
+	for _, r := range list.ResourceTemplates {
+		templates = append(templates, r.URITemplate)
+	}
+	slices.Sort(uris)
+	return uris, templates
+}
+
+// readResource reads a resource through the server's MCP handler and returns its single content entry
+func readResource(t *testing.T, s *Server, uri string) resourceContents {
+	t.Helper()
+	var result struct {
+		Contents []resourceContents `json:"contents"`
+	}
+	resourceRequest(t, s, "resources/read", map[string]interface{}{"uri": uri}, &result)
+	if len(result.Contents) != 1 {
+		t.Fatalf("resources/read %s returned %d contents, want 1", uri, len(result.Contents))
+	}
+	return result.Contents[0]
+}
+
+func TestManifestResourcesAreListedAndRead(t *testing.T) {
+	backend := newManifestSectionBackend(t, handbookResources)
+	s := newToolServer(t, backend.Server)
+
+	uris, templates := listResourceURIs(t, s)
+	if want := []string{"asgard://docs/handbook", "asgard://logo"}; !slices.Equal(uris, want) {
+		t.Errorf("resources/list = %v, want %v without the invalid resource", uris, want)
+	}
+	if want := []string{"asgard://datasets/{id}"}; !slices.Equal(templates, want) {
+		t.Errorf("resources/templates/list = %v, want %v", templates, want)
+	}
+
+	tests := map[string]struct {
+		uri  string
+		want resourceContents
+	}{
+		"asgard json is unwrapped": {
+			uri:  "asgard://docs/handbook",
+			want: resourceContents{URI: "asgard://docs/handbook", MimeType: "application/json", Text: `{"title":"Handbook"}`},
+		},
+		"template variables fill the read endpoint": {
+			uri:  "asgard://datasets/q3-sales",
+			want: resourceContents{URI: "asgard://datasets/q3-sales", MimeType: "text/csv", Text: "id\nq3-sales\n"},
+		},
+		"binary content is a blob": {
+			uri:  "asgard://logo",
+			want: resourceContents{URI: "asgard://logo", MimeType: "image/png", Blob: base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'})},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := readResource(t, s, tt.uri); got != tt.want {
+				t.Errorf("resources/read %s = %+v, want %+v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadKeepsTheStartupResources(t *testing.T) {
+	backend := newManifestSectionBackend(t, handbookResources)
+	s := newToolServer(t, backend.Server)
+	wantURIs, wantTemplates := listResourceURIs(t, s)
+
+	backend.setSection(`"resources":[{"uri":"asgard://docs/faq","name":"FAQ","read_endpoint":"$BACKEND/docs/faq"}]`)
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	uris, templates := listResourceURIs(t, s)
+	if !slices.Equal(uris, wantURIs) || !slices.Equal(templates, wantTemplates) {
+		t.Errorf("after reload resources = %v %v, want the startup resources %v %v", uris, templates, wantURIs, wantTemplates)
+	}
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestManifestToolValidation(t *testing.T) {
+	tests := map[string]struct {
+		tool       string
+		wantErr    string
+		wantSchema string
+	}{
+		"complete": {
+			tool:       `{"name":"search","invoke_endpoints":{"json":"$BACKEND/search"},"input_schema":{"type":"object"}}`,
+			wantSchema: `{"type":"object"}`,
+		},
+		"missing name": {
+			tool:    `{"description":"Search","invoke_endpoints":{"json":"$BACKEND/search"}}`,
+			wantErr: "invalid tool #1 in manifest: missing name",
+		},
+		"missing endpoints": {
+			tool:    `{"name":"search"}`,
+			wantErr: "invalid tool search in manifest: missing JSON invoke endpoint",
+		},
+		"upload tool without form endpoint": {
+			tool:    `{"name":"search","allow_upload_files":true,"invoke_endpoints":{"json":"$BACKEND/search"}}`,
+			wantErr: "allows file uploads but has no form invoke endpoint",
+		},
+		"missing schema": {
+			tool:       `{"name":"search","invoke_endpoints":{"json":"$BACKEND/search"}}`,
+			wantSchema: string(emptyInputSchema),
+		},
+		"null schema": {
+			tool:       `{"name":"search","invoke_endpoints":{"json":"$BACKEND/search"},"input_schema":null}`,
+			wantSchema: string(emptyInputSchema),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Keep a valid tool first so skipping leaves the rest of the manifest intact
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`), searchTool, tt.tool)
+
+			skipping := newTestClient(backend.URL + "/manifest")
+			manifest, err := skipping.FetchToolsetManifest(context.Background())
+			if err != nil {
+				t.Fatalf("FetchToolsetManifest() error = %v, want invalid tools skipped", err)
+			}
+			wantTools := 2
+			if tt.wantErr != "" {
+				wantTools = 1
+			}
+			if len(manifest.Tools) != wantTools {
+				t.Fatalf("FetchToolsetManifest() returned %d tools, want %d", len(manifest.Tools), wantTools)
+			}
+			if tt.wantSchema != "" && string(manifest.Tools[1].InputSchema) != tt.wantSchema {
+				t.Errorf("input schema = %s, want %s", manifest.Tools[1].InputSchema, tt.wantSchema)
+			}
+
+			strict := newTestClient(backend.URL+"/manifest", WithInvalidTools(InvalidToolsError))
+			_, err = strict.FetchToolsetManifest(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("strict FetchToolsetManifest() error = %v, want success", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("strict FetchToolsetManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
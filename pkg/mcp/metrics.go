@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsPath is the path Prometheus metrics are served on
+const MetricsPath = "/metrics"
+
+// metrics holds the Prometheus collectors of the server and its API clients
+type metrics struct {
+	registry        *prometheus.Registry
+	toolCalls       *prometheus.CounterVec
+	toolErrors      *prometheus.CounterVec
+	toolInFlight    *prometheus.GaugeVec
+	toolDuration    *prometheus.HistogramVec
+	manifestFetches *prometheus.CounterVec
+}
+
+// newMetrics creates the collectors and registers them with a new registry along with the Go runtime collectors
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "asgard_mcp_tool_calls_total",
+			Help: "Tool calls forwarded to the backend.",
+		}, []string{"tool"}),
+		toolErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "asgard_mcp_tool_errors_total",
+			Help: "Tool calls that failed.",
+		}, []string{"tool"}),
+		toolInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "asgard_mcp_tool_calls_in_flight",
+			Help: "Tool calls currently executing.",
+		}, []string{"tool"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "asgard_mcp_tool_call_duration_seconds",
+			Help:    "Duration of tool calls, including retries.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"tool"}),
+		manifestFetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "asgard_mcp_manifest_fetches_total",
+			Help: "Toolset manifest fetches by result, success or failure.",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		m.toolCalls, m.toolErrors, m.toolInFlight, m.toolDuration, m.manifestFetches,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// callStarted marks a tool call as in flight; the returned function records its outcome
+func (m *metrics) callStarted(tool string) func(duration time.Duration, err error) {
+	if m == nil {
+		return func(time.Duration, error) {}
+	}
+
+	m.toolInFlight.WithLabelValues(tool).Inc()
+	return func(duration time.Duration, err error) {
+		m.toolInFlight.WithLabelValues(tool).Dec()
+		m.toolCalls.WithLabelValues(tool).Inc()
+		m.toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+		if err != nil {
+			m.toolErrors.WithLabelValues(tool).Inc()
+		}
+	}
+}
+
+// manifestFetched records the result of a manifest fetch
+func (m *metrics) manifestFetched(err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.manifestFetches.WithLabelValues(result).Inc()
+}
+
+// handler serves the registered metrics in the Prometheus exposition format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WithMetrics serves Prometheus metrics of tool calls and manifest fetches at /metrics on addr while serving;
+// an empty addr disables metrics
+func WithMetrics(addr string) ServerOption {
+	return func(s *Server) {
+		s.metricsAddr = addr
+	}
+}
+
+// withClientMetrics makes the client record its manifest fetches
+func withClientMetrics(m *metrics) APIClientOption {
+	return func(c *APIClient) {
+		c.metrics = m
+	}
+}
+
+// serveMetrics serves the metrics endpoint in the background until the returned function is called
+func (s *Server) serveMetrics() func() {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, s.metrics.handler())
+	httpServer := &http.Server{
+		Addr:              s.metricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		s.logger.Info("Serving metrics", "addr", s.metricsAddr, "path", MetricsPath)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Metrics server failed", "error", err)
+		}
+	}()
+	return func() { _ = httpServer.Close() }
+}
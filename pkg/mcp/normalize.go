@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// NormalizeRule cleans up model-supplied arguments of a tool before invocation.
+//
+// Rules are read from a JSON array, for example:
+//
+//	[{"tool": "search", "lowercase_keys": true, "trim": ["query", "region"]}]
+//
+// LowercaseKeys rewrites top-level argument names to lower case. When names collide, the argument that is
+// already lower case is kept, or else the one whose name sorts first, so NAME is kept over Name. Trim removes
+// leading and trailing whitespace from the named top-level string arguments, or from every top-level string
+// argument when it contains "*". Names in Trim refer to the arguments after key normalization.
+type NormalizeRule struct {
+	Tool          string   `json:"tool" yaml:"tool"`
+	LowercaseKeys bool     `json:"lowercase_keys" yaml:"lowercase_keys"`
+	Trim          []string `json:"trim" yaml:"trim"`
+}
+
+// LoadNormalizeRules reads normalization rules from a JSON file
+func LoadNormalizeRules(path string) ([]NormalizeRule, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return nil, fmt.Errorf("failed to read normalization rules: %w", err)
+	}
+
+	var rules []NormalizeRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse normalization rules: %w", err)
+	}
+	for i, rule := range rules {
+		if rule.Tool == "" {
+			return nil, fmt.Errorf("normalization rule %d has no tool", i)
+		}
+	}
+
+	return rules, nil
+}
+
+// apply normalizes args in place, logging dropped arguments to logger
+func (r NormalizeRule) apply(args map[string]interface{}, logger *slog.Logger) {
+	if r.LowercaseKeys {
+		// Rename in sorted order so the kept argument never depends on map iteration order
+		for _, name := range slices.Sorted(maps.Keys(args)) {
+			lower := strings.ToLower(name)
+			if lower == name {
+				continue
+			}
+			value := args[name]
+			delete(args, name)
+			if _, exists := args[lower]; exists {
+				logger.Warn("Dropping argument colliding with its lower-case name", "tool", r.Tool, "argument", name, "kept", lower)
+				continue
+			}
+			args[lower] = value
+		}
+	}
+
+	trimAll := false
+	trim := make(map[string]bool, len(r.Trim))
+	for _, name := range r.Trim {
+		trimAll = trimAll || name == "*"
+		trim[name] = true
+	}
+	for name, value := range args {
+		if str, ok := value.(string); ok && (trimAll || trim[name]) {
+			args[name] = strings.TrimSpace(str)
+		}
+	}
+}
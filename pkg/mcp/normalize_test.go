@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeRuleTrimsWhitespace(t *testing.T) {
+	tests := map[string]struct {
+		trim []string
+		want map[string]interface{}
+	}{
+		"listed": {
+			trim: []string{"query"},
+			want: map[string]interface{}{"query": "coffee beans", "region": "  eu\n", "limit": 5.0},
+		},
+		"all": {
+			trim: []string{"*"},
+			want: map[string]interface{}{"query": "coffee beans", "region": "eu", "limit": 5.0},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			args := map[string]interface{}{"query": "\t coffee beans ", "region": "  eu\n", "limit": 5.0}
+			NormalizeRule{Tool: "search", Trim: tt.trim}.apply(args, discardLogger)
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("apply() = %v, want %v", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRuleTrimsAfterLowercasingKeys(t *testing.T) {
+	args := map[string]interface{}{"Query": " coffee "}
+	NormalizeRule{Tool: "search", LowercaseKeys: true, Trim: []string{"query"}}.apply(args, discardLogger)
+
+	if want := map[string]interface{}{"query": "coffee"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("apply() = %v, want %v", args, want)
+	}
+}
+
+func TestNormalizeRuleCollisionsKeepDocumentedWinner(t *testing.T) {
+	tests := map[string]struct {
+		args map[string]interface{}
+		want map[string]interface{}
+	}{
+		"lower case present": {
+			args: map[string]interface{}{"name": "lower", "Name": "title", "NAME": "upper"},
+			want: map[string]interface{}{"name": "lower"},
+		},
+		"first sorted name": {
+			args: map[string]interface{}{"Name": "title", "NAME": "upper", "nAme": "mixed"},
+			want: map[string]interface{}{"name": "upper"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Map iteration order varies between runs, so repeat to catch order dependence
+			for range 50 {
+				args := make(map[string]interface{}, len(tt.args))
+				for k, v := range tt.args {
+					args[k] = v
+				}
+				NormalizeRule{Tool: "search", LowercaseKeys: true}.apply(args, discardLogger)
+				if !reflect.DeepEqual(args, tt.want) {
+					t.Fatalf("apply() = %v, want %v", args, tt.want)
+				}
+			}
+		})
+	}
+}
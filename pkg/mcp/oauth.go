@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// OAuth2Config holds the client credentials used to obtain bearer tokens from a token endpoint
+type OAuth2Config struct {
+	TokenURL     string   `yaml:"token_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// Enabled reports whether client credentials are configured
+func (o OAuth2Config) Enabled() bool {
+	return o.TokenURL != ""
+}
+
+// oauthTokens caches the token of a client credentials flow, fetching a new one shortly before expiry or on demand
+type oauthTokens struct {
+	config *clientcredentials.Config
+	ctx    context.Context
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// WithOAuth2 obtains bearer tokens from the token endpoint with client credentials instead of sending the API key;
+// tokens are cached, refreshed before they expire, and refreshed once more when the backend answers 401
+func WithOAuth2(config OAuth2Config) APIClientOption {
+	return func(c *APIClient) {
+		if !config.Enabled() {
+			return
+		}
+		c.oauth = &oauthTokens{
+			config: &clientcredentials.Config{
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				TokenURL:     config.TokenURL,
+				Scopes:       config.Scopes,
+			},
+			// Token requests share the client's transport, timeouts, and TLS settings
+			ctx: context.WithValue(context.Background(), oauth2.HTTPClient, c.client),
+		}
+	}
+}
+
+// get returns a valid access token, fetching a new one when the cached token expires soon or force is set
+func (o *oauthTokens) get(force bool) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !force && o.token.Valid() {
+		return o.token.AccessToken, nil
+	}
+	token, err := o.config.Token(o.ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain OAuth2 token: %w", err)
+	}
+	o.token = token
+	return token.AccessToken, nil
+}
+
+// secrets returns the client secret and the cached access token, for redaction
+func (o *oauthTokens) secrets() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	secrets := []string{o.config.ClientSecret}
+	if o.token != nil {
+		secrets = append(secrets, o.token.AccessToken)
+	}
+	return secrets
+}
+
+// authorize presents the client's credential on a backend request: an OAuth2 access token when client
+// credentials are configured, the API key otherwise
+func (c *APIClient) authorize(req *http.Request) error {
+	if c.oauth == nil {
+		c.setCredential(req, c.currentAPIKey())
+		return nil
+	}
+
+	token, err := c.oauth.get(false)
+	if err != nil {
+		return c.redactError(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// retryWithFreshToken retries a request rejected with 401 once with a newly fetched access token
+func (c *APIClient) retryWithFreshToken(req *http.Request, resp *http.Response) (*http.Response, error) {
+	used := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if used == "" || resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	token, err := c.oauth.get(true)
+	if err != nil || token == used {
+		return resp, nil
+	}
+
+	// A consumed body can only be replayed when the request knows how to recreate it
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	_ = resp.Body.Close()
+
+	c.logger.Info("Request was unauthorized, retrying with a fresh OAuth2 token", "method", req.Method, "url", redactURL(req.URL.String(), c.apiKeys()...))
+	return c.doWithRetry(retry)
+}
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tokenServer issues numbered access tokens for the client credentials grant, refusing unknown clients
+type tokenServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	issued int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	t.Helper()
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("grant_type") != "client_credentials" || id != "client" || secret != "s3cret" || r.PostForm.Get("scope") != "tools:call" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		s.mu.Lock()
+		s.issued++
+		n := s.issued
+		s.mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) issuedTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// bearerBackend answers tool calls, rejecting revoked tokens with 401, and records the tokens and bodies it receives
+type bearerBackend struct {
+	*httptest.Server
+	mu      sync.Mutex
+	revoked map[string]bool
+	tokens  []string
+	bodies  []string
+}
+
+func newBearerBackend(t *testing.T, revoked ...string) *bearerBackend {
+	t.Helper()
+	b := &bearerBackend{revoked: map[string]bool{}}
+	for _, token := range revoked {
+		b.revoked[token] = true
+	}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		b.mu.Lock()
+		b.tokens = append(b.tokens, token)
+		b.bodies = append(b.bodies, string(body))
+		rejected := b.revoked[token]
+		b.mu.Unlock()
+		if rejected {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(w, `{"isSuccess":false,"error":"token revoked"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// received returns the tokens and bodies of the requests seen so far
+func (b *bearerBackend) received() ([]string, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.tokens...), append([]string(nil), b.bodies...)
+}
+
+func (b *bearerBackend) tool() *Tool {
+	return &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: b.URL + "/search"}}
+}
+
+// newOAuthClient returns a client for backend obtaining its tokens from tokens
+func newOAuthClient(backend *bearerBackend, tokens *tokenServer, secret string) *APIClient {
+	return newTestClient(backend.URL, WithOAuth2(OAuth2Config{
+		TokenURL:     tokens.URL,
+		ClientID:     "client",
+		ClientSecret: secret,
+		Scopes:       []string{"tools:call"},
+	}))
+}
+
+// callTwice executes two tool calls and fails the test on any error
+func callTwice(t *testing.T, c *APIClient, tool *Tool) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOAuth2TokensAreCached(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	backend := newBearerBackend(t)
+	callTwice(t, newOAuthClient(backend, tokens, "s3cret"), backend.tool())
+
+	if n := tokens.issuedTokens(); n != 1 {
+		t.Errorf("token endpoint issued %d tokens, want the first one reused", n)
+	}
+	if got, _ := backend.received(); fmt.Sprint(got) != "[tok-1 tok-1]" {
+		t.Errorf("backend received tokens %q, want the cached token twice", got)
+	}
+}
+
+func TestOAuth2TokensAreRefreshedBeforeExpiry(t *testing.T) {
+	// Tokens expiring within seconds count as expired, so every call needs a new one
+	tokens := newTokenServer(t, 5)
+	backend := newBearerBackend(t)
+	callTwice(t, newOAuthClient(backend, tokens, "s3cret"), backend.tool())
+
+	if got, _ := backend.received(); fmt.Sprint(got) != "[tok-1 tok-2]" {
+		t.Errorf("backend received tokens %q, want a fresh token before the first expired", got)
+	}
+}
+
+func TestOAuth2UnauthorizedRetriesWithFreshToken(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	backend := newBearerBackend(t, "tok-1")
+	c := newOAuthClient(backend, tokens, "s3cret")
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{"query":"q"}`)); err != nil {
+		t.Fatalf("ExecuteToolRequest() = %v, want the retry with a fresh token to succeed", err)
+	}
+	got, bodies := backend.received()
+	if fmt.Sprint(got) != "[tok-1 tok-2]" {
+		t.Errorf("backend received tokens %q, want the revoked token then a fresh one", got)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"query":"q"}` {
+		t.Errorf("backend received bodies %q, want the body replayed on the retry", bodies)
+	}
+}
+
+func TestOAuth2TokenFailureNeverReachesBackend(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	backend := newBearerBackend(t)
+	c := newOAuthClient(backend, tokens, "wrong-secret")
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.tool(), []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "failed to obtain OAuth2 token") {
+		t.Fatalf("ExecuteToolRequest() = %v, want a token error", err)
+	}
+	if strings.Contains(err.Error(), "wrong-secret") {
+		t.Errorf("error %q carries the client secret", err)
+	}
+	if got, _ := backend.received(); len(got) != 0 {
+		t.Errorf("backend received tokens %q, want no request without a token", got)
+	}
+}
@@ -0,0 +1,275 @@
+package mcp
+
+import "time"
+
+// Defaults for the recent call log
+const (
+	DefaultCallLogSize    = 10
+	DefaultCallLogBodyCap = 4096
+)
+
+// DefaultVersion is the server version reported to clients unless WithVersion sets the build version
+const DefaultVersion = "0.0.1"
+
+// ServerOption configures optional behavior of the MCP asgard-mcp-server
+type ServerOption func(*Server)
+
+// PromptMode controls how prompt-flagged tools are exposed to MCP clients
+type PromptMode string
+
+const (
+	// PromptModeOff registers every tool as a tool, ignoring the prompt flag
+	PromptModeOff PromptMode = "off"
+	// PromptModeBoth registers prompt-flagged tools as both a tool and a prompt
+	PromptModeBoth PromptMode = "both"
+	// PromptModeOnly registers prompt-flagged tools as prompts instead of tools
+	PromptModeOnly PromptMode = "only"
+)
+
+// UploadSafeMode controls whether file uploads are blocked regardless of tool definitions
+type UploadSafeMode string
+
+const (
+	// UploadSafeModeOff allows uploads for tools that accept them
+	UploadSafeModeOff UploadSafeMode = "off"
+	// UploadSafeModeSkip leaves upload tools unregistered
+	UploadSafeModeSkip UploadSafeMode = "skip"
+	// UploadSafeModeReject registers upload tools without the upload field and rejects calls that pass it
+	UploadSafeModeReject UploadSafeMode = "reject"
+)
+
+// WithToolPrompts enables registering prompt-flagged tools through the MCP prompts capability
+func WithToolPrompts(mode PromptMode) ServerOption {
+	return func(s *Server) {
+		s.promptMode = mode
+	}
+}
+
+// WithCollapseSingleField collapses single-key response objects to their value for the named tools
+func WithCollapseSingleField(toolNames ...string) ServerOption {
+	return func(s *Server) {
+		if s.collapseTools == nil {
+			s.collapseTools = make(map[string]bool, len(toolNames))
+		}
+		for _, name := range toolNames {
+			s.collapseTools[name] = true
+		}
+	}
+}
+
+// WithBinaryOutput treats the responses of the named tools as raw bytes mapped to MCP content by content type
+func WithBinaryOutput(toolNames ...string) ServerOption {
+	return func(s *Server) {
+		if s.binaryTools == nil {
+			s.binaryTools = make(map[string]bool, len(toolNames))
+		}
+		for _, name := range toolNames {
+			s.binaryTools[name] = true
+		}
+	}
+}
+
+// WithAPIClientOptions passes options through to the API client used for backend requests
+func WithAPIClientOptions(opts ...APIClientOption) ServerOption {
+	return func(s *Server) {
+		s.clientOpts = append(s.clientOpts, opts...)
+	}
+}
+
+// WithToolTags adds local tags per tool name on top of the tags provided by the manifest
+func WithToolTags(tags map[string][]string) ServerOption {
+	return func(s *Server) {
+		if s.toolTags == nil {
+			s.toolTags = make(map[string][]string, len(tags))
+		}
+		for name, t := range tags {
+			s.toolTags[name] = append(s.toolTags[name], t...)
+		}
+	}
+}
+
+// WithArgumentRules adapts tool arguments according to generation-keyed rules before invocation
+func WithArgumentRules(rules ...ArgumentRule) ServerOption {
+	return func(s *Server) {
+		s.argumentRules = append(s.argumentRules, rules...)
+	}
+}
+
+// WithNormalizeRules trims argument values and normalizes key casing per tool before invocation
+func WithNormalizeRules(rules ...NormalizeRule) ServerOption {
+	return func(s *Server) {
+		s.normalizeRules = append(s.normalizeRules, rules...)
+	}
+}
+
+// WithStructuredErrors returns tool errors as JSON objects with code, message, status, and tool fields
+func WithStructuredErrors(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.structuredErrors = enabled
+	}
+}
+
+// WithCallLog retains the last size tool calls in memory with bodies capped at bodyCap bytes; zero size disables it
+func WithCallLog(size, bodyCap int) ServerOption {
+	return func(s *Server) {
+		s.callLogSize = size
+		s.callLogBodyCap = bodyCap
+	}
+}
+
+// WithVersion sets the server version reported to clients, such as the build version of the binary
+func WithVersion(version string) ServerOption {
+	return func(s *Server) {
+		if version != "" {
+			s.version = version
+		}
+	}
+}
+
+// WithCallSummary logs per-tool call counts, error counts, and latency percentiles when serving stops
+func WithCallSummary(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.callSummary = enabled
+	}
+}
+
+// WithRawResponses returns each tool's response body verbatim as the result text instead of pretty-printing it,
+// preserving key order and number formatting
+func WithRawResponses(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.rawResponses = enabled
+	}
+}
+
+// WithRawData attaches each tool's exact response bytes as an embedded JSON resource after the formatted text
+func WithRawData(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.includeRawData = enabled
+	}
+}
+
+// WithUnknownArguments sets how arguments missing from a tool's declared properties are handled
+func WithUnknownArguments(mode UnknownArgumentsMode) ServerOption {
+	return func(s *Server) {
+		s.unknownArguments = mode
+	}
+}
+
+// WithArgumentValidation checks tool arguments against the tool's input schema and rejects invalid calls
+// before the backend is called; it is enabled by default
+func WithArgumentValidation(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.validateArguments = enabled
+	}
+}
+
+// WithErrorDiagnostics appends the backend's status, headers, and a capped, redacted body snippet to tool errors
+func WithErrorDiagnostics(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.errorDiagnostics = enabled
+	}
+}
+
+// WithRequestIDMeta adds the backend's request ID to the _meta of tool results as requestId
+func WithRequestIDMeta(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.requestIDMeta = enabled
+	}
+}
+
+// WithUploadPathsSchema sets whether the uploaded file paths argument is added to the input schema of upload
+// tools; disabled, the backend's schema is exposed as is. It is enabled by default
+func WithUploadPathsSchema(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.uploadPathsSchema = enabled
+	}
+}
+
+// WithUploadPathsField renames the argument carrying the files to upload, for toolsets whose schemas already use
+// the default name
+func WithUploadPathsField(name string) ServerOption {
+	return func(s *Server) {
+		s.uploadField = name
+		s.clientOpts = append(s.clientOpts, WithUploadFieldName(name))
+	}
+}
+
+// WithNoUploads blocks every file upload so the server never reads local files on behalf of a tool
+func WithNoUploads(mode UploadSafeMode) ServerOption {
+	return func(s *Server) {
+		s.noUploads = mode
+	}
+}
+
+// WithTransport selects how the server is exposed, listening on addr for HTTP transports
+func WithTransport(transport Transport, addr string) ServerOption {
+	return func(s *Server) {
+		s.transport = transport
+		if addr != "" {
+			s.listenAddr = addr
+		}
+	}
+}
+
+// WithOnManifestRefresh calls fn with the tools added and removed after each successful reload of the manifest;
+// it runs outside the server's locks, and a panic in fn is logged without failing the reload
+func WithOnManifestRefresh(fn func(added, removed []Tool)) ServerOption {
+	return func(s *Server) {
+		s.onManifestRefresh = fn
+	}
+}
+
+// WithToolFilter exposes only the manifest tools passing the filter, logging the ones filtered out
+func WithToolFilter(filter ToolFilter) ServerOption {
+	return func(s *Server) {
+		s.toolFilter = filter
+	}
+}
+
+// WithEndpoints serves the tools of additional endpoints next to the primary one, calling each tool through the
+// client of its endpoint; an endpoint whose manifest cannot be fetched is logged and skipped
+func WithEndpoints(endpoints ...Endpoint) ServerOption {
+	return func(s *Server) {
+		s.extraEndpoints = append(s.extraEndpoints, endpoints...)
+	}
+}
+
+// WithToolPrefix prepends prefix to the name of every registered tool and prompt, so tools of several endpoints
+// can share a client; calls are still made under the manifest names
+func WithToolPrefix(prefix string) ServerOption {
+	return func(s *Server) {
+		s.toolPrefix = prefix
+	}
+}
+
+// WithAllowEmptyManifest sets whether a manifest declaring no tools is served with a warning, as by default, or
+// treated as a failed fetch
+func WithAllowEmptyManifest(allow bool) ServerOption {
+	return func(s *Server) {
+		s.allowEmptyManifest = allow
+	}
+}
+
+// WithLogManifestOrder keeps tools in manifest order instead of sorting them by name in the startup log, reload
+// diffs, and ExposedTools. It does not affect clients: tools/list is always sorted by name
+func WithLogManifestOrder(preserve bool) ServerOption {
+	return func(s *Server) {
+		s.logManifestOrder = preserve
+	}
+}
+
+// WithManifestRefresh reloads the manifest at the given interval while serving, registering new tools and
+// removing deleted ones; zero disables refreshing
+func WithManifestRefresh(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		s.manifestRefresh = interval
+	}
+}
+
+// WithResourceArguments enables the resources capability, serves each tool's latest response at
+// asgard://responses/<tool>, and replaces {"$resource": uri} argument objects with the resource's content
+func WithResourceArguments(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.resourceArguments = enabled
+	}
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithProgressHeartbeat sends a notifications/progress message at the given interval while a tool call is
+// outstanding, for clients that passed a progress token; zero disables heartbeats
+func WithProgressHeartbeat(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		s.progressInterval = interval
+	}
+}
+
+// reportProgress relays the events of streamed responses and, when enabled, heartbeats as progress notifications
+// for the tool call until the returned function is called; the returned context carries the stream handler
+func (s *Server) reportProgress(ctx context.Context, req mcp.CallToolRequest, name string) (context.Context, func()) {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return ctx, func() {}
+	}
+	token := req.Params.Meta.ProgressToken
+	start := time.Now()
+
+	// Progress must increase with each notification, so report the elapsed seconds
+	notify := func(message string) {
+		err := s.mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      time.Since(start).Seconds(),
+			"message":       message,
+		})
+		if err != nil {
+			s.logger.Debug("Failed to send progress notification", "tool", name, "error", err)
+		}
+	}
+	streamCtx := withStreamHandler(ctx, notify)
+
+	if s.progressInterval <= 0 {
+		return streamCtx, func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				notify(fmt.Sprintf("%s is still running after %s", name, time.Since(start).Round(time.Second)))
+			}
+		}
+	}()
+	return streamCtx, func() { close(done) }
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callWithProgress calls the search tool from a client session, passing a progress token when token is not empty,
+// and returns the progress notifications the session received
+func callWithProgress(t *testing.T, s *Server, token string) []map[string]any {
+	t.Helper()
+	params := map[string]interface{}{"name": "search", "arguments": map[string]interface{}{}}
+	if token != "" {
+		params["_meta"] = map[string]interface{}{"progressToken": token}
+	}
+	request, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := &notificationSession{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	response := s.mcpServer.HandleMessage(s.mcpServer.WithContext(context.Background(), session), request)
+	if result, ok := response.(mcp.JSONRPCResponse); !ok || result.Result.(mcp.CallToolResult).IsError {
+		t.Fatalf("tools/call returned %+v, want success", response)
+	}
+
+	var progress []map[string]any
+	for {
+		select {
+		case notification := <-session.notifications:
+			if notification.Method == "notifications/progress" {
+				progress = append(progress, notification.Params.AdditionalFields)
+			}
+		default:
+			return progress
+		}
+	}
+}
+
+func TestProgressHeartbeatsDuringSlowCalls(t *testing.T) {
+	backend := newToolBackend(t, replyAfter(200*time.Millisecond), searchTool)
+	s := newToolServer(t, backend, WithProgressHeartbeat(40*time.Millisecond))
+
+	progress := callWithProgress(t, s, "call-1")
+	if len(progress) < 2 {
+		t.Fatalf("client received %d progress notifications, want heartbeats throughout the call", len(progress))
+	}
+	last := -1.0
+	for _, p := range progress {
+		value, _ := p["progress"].(float64)
+		if p["progressToken"] != "call-1" || value <= last || !strings.Contains(p["message"].(string), "search is still running") {
+			t.Errorf("progress notification = %v, want increasing heartbeats for token call-1", p)
+		}
+		last = value
+	}
+}
+
+func TestProgressHeartbeatsNeedATokenAndAnInterval(t *testing.T) {
+	tests := map[string]struct {
+		token string
+		opts  []ServerOption
+	}{
+		"no token":       {opts: []ServerOption{WithProgressHeartbeat(20 * time.Millisecond)}},
+		"off by default": {token: "call-1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyAfter(100*time.Millisecond), searchTool)
+			s := newToolServer(t, backend, tt.opts...)
+
+			if progress := callWithProgress(t, s, tt.token); len(progress) != 0 {
+				t.Errorf("client received %v, want no progress notifications", progress)
+			}
+		})
+	}
+}
+
+func TestStreamedEventsBecomeProgress(t *testing.T) {
+	backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{"indexing", "ranking"} {
+			_, _ = w.Write([]byte("data: " + event + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}, searchTool)
+	s := newToolServer(t, backend)
+
+	var messages []string
+	for _, p := range callWithProgress(t, s, "call-2") {
+		messages = append(messages, p["message"].(string))
+	}
+	if strings.Join(messages, ",") != "indexing,ranking" {
+		t.Errorf("progress messages = %q, want each streamed event", messages)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// promptsEnabled reports whether prompt-flagged tools should be registered as prompts
+func (s *Server) promptsEnabled() bool {
+	return s.promptMode == PromptModeBoth || s.promptMode == PromptModeOnly
+}
+
+// promptOnly reports whether the tool is served as a prompt instead of a tool
+func (s *Server) promptOnly(tool Tool) bool {
+	return tool.Prompt && s.promptMode == PromptModeOnly
+}
+
+// newToolPrompt maps a prompt-flagged tool and its input schema to an MCP prompt definition
+func newToolPrompt(tool Tool, schema map[string]interface{}, uploadField string) mcp.Prompt {
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(tool.Description)}
+
+	// Collect required property names
+	required := make(map[string]bool)
+	if reqList, ok := schema["required"].([]interface{}); ok {
+		for _, r := range reqList {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	// Map each schema property to a prompt argument in a stable order
+	props, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(props))
+	for name := range props {
+		if name == uploadField {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var argOpts []mcp.ArgumentOption
+		if prop, ok := props[name].(map[string]interface{}); ok {
+			if desc, ok := prop["description"].(string); ok {
+				argOpts = append(argOpts, mcp.ArgumentDescription(desc))
+			}
+		}
+		if required[name] {
+			argOpts = append(argOpts, mcp.RequiredArgument())
+		}
+		opts = append(opts, mcp.WithArgument(name, argOpts...))
+	}
+
+	return mcp.NewPrompt(tool.Name, opts...)
+}
+
+// newPromptHandler creates a prompt handler that invokes the tool and returns its response as a user message
+func (s *Server) newPromptHandler(tool Tool, schema map[string]interface{}) server.PromptHandlerFunc {
+	props, _ := schema["properties"].(map[string]interface{})
+
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		// Prompt arguments are always strings; decode non-string properties as JSON values
+		args := make(map[string]interface{}, len(req.Params.Arguments))
+		for name, value := range req.Params.Arguments {
+			args[name] = value
+			prop, _ := props[name].(map[string]interface{})
+			if propType, _ := prop["type"].(string); propType != "" && propType != "string" {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+					args[name] = decoded
+				}
+			}
+		}
+
+		argsJSON, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal prompt arguments: %w", err)
+		}
+
+		s.logger.Info("Executing prompt", "prompt", tool.Name)
+
+		responseJSON, err := s.clientFor(tool).ExecuteToolRequest(ctx, &tool, argsJSON)
+		if err != nil {
+			s.logger.Error("Prompt execution failed", "prompt", tool.Name, "error", err)
+			return nil, fmt.Errorf("prompt execution failed: %w", err)
+		}
+
+		// Prompt templates usually render to a plain string; use it verbatim when so
+		var text string
+		if err := json.Unmarshal(responseJSON, &text); err != nil {
+			if text, err = formatToolResponse(responseJSON); err != nil {
+				return nil, err
+			}
+		}
+
+		return mcp.NewGetPromptResult(tool.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		}), nil
+	}
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// listPrompts returns the prompts the server lists to MCP clients
+func listPrompts(t *testing.T, s *Server) []mcp.Prompt {
+	t.Helper()
+	response, ok := s.mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("prompts/list did not return a result")
+	}
+	result, ok := response.Result.(mcp.ListPromptsResult)
+	if !ok {
+		t.Fatalf("prompts/list returned %T, want a prompt list", response.Result)
+	}
+	return result.Prompts
+}
+
+// getPrompt sends a prompts/get request through the server's MCP handler and returns the result
+func getPrompt(t *testing.T, s *Server, name string, args map[string]string) mcp.GetPromptResult {
+	t.Helper()
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "prompts/get",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, ok := s.mcpServer.HandleMessage(context.Background(), request).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("prompts/get %s did not return a result", name)
+	}
+	result, ok := response.Result.(mcp.GetPromptResult)
+	if !ok {
+		t.Fatalf("prompts/get %s returned %T, want a prompt result", name, response.Result)
+	}
+	return result
+}
+
+// summarizeTool declares a prompt-flagged tool for newToolBackend
+const summarizeTool = `{"name":"summarize","description":"Summarize text","prompt":true,"invoke_endpoints":{"json":"$BACKEND/summarize"},` +
+	`"input_schema":{"type":"object","properties":{"text":{"type":"string"},"max_words":{"type":"integer"}},"required":["text"]}}`
+
+func TestPromptFlaggedToolsRegistration(t *testing.T) {
+	tests := map[string]struct {
+		mode                 PromptMode
+		wantTool, wantPrompt bool
+	}{
+		"off":  {mode: PromptModeOff, wantTool: true},
+		"both": {mode: PromptModeBoth, wantTool: true, wantPrompt: true},
+		"only": {mode: PromptModeOnly, wantPrompt: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`), summarizeTool, searchTool)
+			s := newToolServer(t, backend, WithToolPrompts(tt.mode))
+
+			tools := listTools(t, s)
+			if got := slices.Contains(tools, "summarize"); got != tt.wantTool {
+				t.Errorf("tools/list = %v, want summarize listed %v", tools, tt.wantTool)
+			}
+			if !slices.Contains(tools, "search") {
+				t.Errorf("tools/list = %v, want tools without the prompt flag listed", tools)
+			}
+			if !tt.wantPrompt {
+				return
+			}
+
+			prompts := listPrompts(t, s)
+			if len(prompts) != 1 || prompts[0].Name != "summarize" || prompts[0].Description != "Summarize text" {
+				t.Fatalf("prompts/list = %+v, want only summarize", prompts)
+			}
+			var args []string
+			for _, arg := range prompts[0].Arguments {
+				args = append(args, fmt.Sprintf("%s required=%v", arg.Name, arg.Required))
+			}
+			if want := []string{"max_words required=false", "text required=true"}; !slices.Equal(args, want) {
+				t.Errorf("prompt arguments = %q, want %q", args, want)
+			}
+		})
+	}
+}
+
+func TestPromptsInvokeTheTool(t *testing.T) {
+	var recorder bodyRecorder
+	backend := newToolBackend(t, recorder.reply, summarizeTool)
+	s := newToolServer(t, backend, WithToolPrompts(PromptModeOnly))
+
+	result := getPrompt(t, s, "summarize", map[string]string{"text": "hi", "max_words": "10"})
+	if len(result.Messages) != 1 {
+		t.Fatalf("prompts/get returned %d messages, want 1", len(result.Messages))
+	}
+	if text, _ := result.Messages[0].Content.(mcp.TextContent); text.Text != "ok" || result.Messages[0].Role != mcp.RoleUser {
+		t.Errorf("prompt message = %+v, want the returned string as a user message", result.Messages[0])
+	}
+	bodies := recorder.received()
+	if len(bodies) != 1 || fmt.Sprint(bodies[0]) != "map[max_words:10 text:hi]" {
+		t.Errorf("backend received %v, want the arguments with max_words decoded as a number", bodies)
+	}
+}
+
+// ticketPrompt declares a manifest prompt template for newManifestSectionBackend
+const ticketPrompt = `"prompts":[{"name":"summarize_ticket","description":"Summarize a ticket",` +
+	`"arguments":[{"name":"ticket","required":true},{"name":"tone"}],"template":"Summarize ticket {{ticket}} {{tone}}."}]`
+
+func TestManifestPromptsAreListedAndRendered(t *testing.T) {
+	s := newToolServer(t, newManifestSectionBackend(t, ticketPrompt).Server)
+
+	prompts := listPrompts(t, s)
+	if len(prompts) != 1 || prompts[0].Name != "summarize_ticket" || prompts[0].Description != "Summarize a ticket" {
+		t.Fatalf("prompts/list = %+v, want only summarize_ticket", prompts)
+	}
+	var args []string
+	for _, arg := range prompts[0].Arguments {
+		args = append(args, fmt.Sprintf("%s required=%v", arg.Name, arg.Required))
+	}
+	if want := []string{"ticket required=true", "tone required=false"}; !slices.Equal(args, want) {
+		t.Errorf("prompt arguments = %q, want %q", args, want)
+	}
+
+	tests := map[string]struct {
+		args map[string]string
+		want string
+	}{
+		"all arguments":             {args: map[string]string{"ticket": "T-7", "tone": "briefly"}, want: "Summarize ticket T-7 briefly."},
+		"optional argument omitted": {args: map[string]string{"ticket": "T-7"}, want: "Summarize ticket T-7 ."},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := getPrompt(t, s, "summarize_ticket", tt.args)
+			if len(result.Messages) != 1 {
+				t.Fatalf("prompts/get returned %d messages, want 1", len(result.Messages))
+			}
+			if text, _ := result.Messages[0].Content.(mcp.TextContent); text.Text != tt.want || result.Messages[0].Role != mcp.RoleUser {
+				t.Errorf("prompt message = %+v, want %q as a user message", result.Messages[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestPromptsRequireTheirArguments(t *testing.T) {
+	s := newToolServer(t, newManifestSectionBackend(t, ticketPrompt).Server)
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"summarize_ticket","arguments":{"tone":"briefly"}}}`
+	response, ok := s.mcpServer.HandleMessage(context.Background(), []byte(request)).(mcp.JSONRPCError)
+	if !ok {
+		t.Fatal("prompts/get without the required argument did not fail")
+	}
+	if !strings.Contains(response.Error.Message, "missing required arguments: ticket") {
+		t.Errorf("error = %q, want the missing argument named", response.Error.Message)
+	}
+}
+
+func TestReloadRefreshesManifestPrompts(t *testing.T) {
+	backend := newManifestSectionBackend(t, ticketPrompt)
+	s := newToolServer(t, backend.Server)
+
+	backend.setSection(`"prompts":[{"name":"draft_reply","description":"Draft a reply","template":"Draft a reply."}]`)
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts := listPrompts(t, s)
+	if len(prompts) != 1 || prompts[0].Name != "draft_reply" {
+		t.Fatalf("prompts/list after reload = %+v, want only draft_reply", prompts)
+	}
+	result := getPrompt(t, s, "draft_reply", nil)
+	if text, _ := result.Messages[0].Content.(mcp.TextContent); text.Text != "Draft a reply." {
+		t.Errorf("prompt message = %+v, want the reloaded template", result.Messages[0])
+	}
+}
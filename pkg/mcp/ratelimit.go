@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ToolErrorRateLimited is the structured error code of calls rejected by a tool's rate limit
+const ToolErrorRateLimited = "rate_limited"
+
+// RateLimit allows Calls tool calls per Per, with bursts of up to Calls
+type RateLimit struct {
+	Calls int
+	Per   time.Duration
+}
+
+// String formats the limit as accepted by ParseRateLimit
+func (r RateLimit) String() string {
+	unit := "s"
+	switch r.Per {
+	case time.Minute:
+		unit = "m"
+	case time.Hour:
+		unit = "h"
+	}
+	return fmt.Sprintf("%d/%s", r.Calls, unit)
+}
+
+// ParseRateLimit parses a rate limit such as 10/s, 100/m, or 1000/h
+func ParseRateLimit(value string) (RateLimit, error) {
+	calls, unit, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(calls)
+	if !ok || err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, expected N/s, N/m, or N/h", value)
+	}
+	switch unit {
+	case "s":
+		return RateLimit{Calls: n, Per: time.Second}, nil
+	case "m":
+		return RateLimit{Calls: n, Per: time.Minute}, nil
+	case "h":
+		return RateLimit{Calls: n, Per: time.Hour}, nil
+	}
+	return RateLimit{}, fmt.Errorf("invalid rate limit %q, expected N/s, N/m, or N/h", value)
+}
+
+// toolRateLimiter holds a token bucket per limited tool
+type toolRateLimiter struct {
+	limits   map[string]RateLimit
+	limiters map[string]*rate.Limiter
+	maxWait  time.Duration
+}
+
+// WithRateLimits limits the calls of the named tools, keyed by manifest name; a call over the limit waits up to
+// maxWait for a token and is then rejected with a tool error. Tools without a limit are unlimited
+func WithRateLimits(limits map[string]RateLimit, maxWait time.Duration) ServerOption {
+	return func(s *Server) {
+		if len(limits) == 0 {
+			s.rateLimiter = nil
+			return
+		}
+		s.rateLimiter = &toolRateLimiter{
+			limits:   limits,
+			limiters: make(map[string]*rate.Limiter, len(limits)),
+			maxWait:  maxWait,
+		}
+		for name, limit := range limits {
+			s.rateLimiter.limiters[name] = rate.NewLimiter(rate.Every(limit.Per/time.Duration(limit.Calls)), limit.Calls)
+		}
+	}
+}
+
+// errRateLimited is returned when a call does not get a token within the wait deadline
+var errRateLimited = errors.New("rate limit exceeded")
+
+// wait takes a token for the tool, waiting up to maxWait; a nil limiter allows every call
+func (l *toolRateLimiter) wait(ctx context.Context, tool string) error {
+	if l == nil {
+		return nil
+	}
+	limiter, ok := l.limiters[tool]
+	if !ok {
+		return nil
+	}
+	if l.maxWait <= 0 {
+		if !limiter.Allow() {
+			return fmt.Errorf("%w: at most %s", errRateLimited, l.limits[tool])
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.maxWait)
+	defer cancel()
+	// Wait fails at once when the token would not be available before the deadline
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("%w: at most %s", errRateLimited, l.limits[tool])
+	}
+	return nil
+}
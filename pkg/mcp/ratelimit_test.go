@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRateLimiter returns the limiter WithRateLimits installs
+func newRateLimiter(limits map[string]RateLimit, maxWait time.Duration) *toolRateLimiter {
+	var s Server
+	WithRateLimits(limits, maxWait)(&s)
+	return s.rateLimiter
+}
+
+// waitConcurrently calls wait for the tool from n goroutines at once and counts the calls allowed and limited
+func waitConcurrently(t *testing.T, l *toolRateLimiter, tool string, n int) (allowed, limited int32) {
+	t.Helper()
+	var allowedCount, limitedCount atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			switch err := l.wait(context.Background(), tool); {
+			case err == nil:
+				allowedCount.Add(1)
+			case errors.Is(err, errRateLimited):
+				limitedCount.Add(1)
+			default:
+				t.Errorf("wait() = %v, want nil or a rate limit error", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return allowedCount.Load(), limitedCount.Load()
+}
+
+func TestRateLimiterAllowsBurstUnderConcurrency(t *testing.T) {
+	l := newRateLimiter(map[string]RateLimit{"search": {Calls: 5, Per: time.Hour}}, 0)
+
+	allowed, limited := waitConcurrently(t, l, "search", 20)
+	if allowed != 5 || limited != 15 {
+		t.Errorf("allowed %d and limited %d of 20 concurrent calls, want 5 and 15", allowed, limited)
+	}
+	if allowed, limited := waitConcurrently(t, l, "other", 20); allowed != 20 || limited != 0 {
+		t.Errorf("allowed %d and limited %d calls of an unlimited tool, want all allowed", allowed, limited)
+	}
+}
+
+func TestRateLimiterWaitsForTokensWithinMaxWait(t *testing.T) {
+	// A token every 20ms after a burst of 5, so the last of 10 calls waits about 100ms
+	l := newRateLimiter(map[string]RateLimit{"search": {Calls: 5, Per: 100 * time.Millisecond}}, time.Second)
+
+	start := time.Now()
+	allowed, limited := waitConcurrently(t, l, "search", 10)
+	if allowed != 10 || limited != 0 {
+		t.Errorf("allowed %d and limited %d of 10 concurrent calls, want all to wait for a token", allowed, limited)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("10 calls took %s, want the calls past the burst to wait for tokens", elapsed)
+	}
+}
+
+func TestRateLimiterRejectsCallsThatWouldWaitTooLong(t *testing.T) {
+	l := newRateLimiter(map[string]RateLimit{"search": {Calls: 1, Per: time.Hour}}, 50*time.Millisecond)
+
+	allowed, limited := waitConcurrently(t, l, "search", 5)
+	if allowed != 1 || limited != 4 {
+		t.Errorf("allowed %d and limited %d of 5 concurrent calls, want 1 and 4", allowed, limited)
+	}
+}
+
+func TestRateLimitedCallsReturnToolErrors(t *testing.T) {
+	var recorder bodyRecorder
+	backend := newToolBackend(t, recorder.reply, searchTool)
+	s := newToolServer(t, backend, WithStructuredErrors(true),
+		WithRateLimits(map[string]RateLimit{"search": {Calls: 1, Per: time.Hour}}, 0))
+
+	if result := callTool(t, s, "search", map[string]interface{}{}); result.IsError {
+		t.Fatalf("first call = %q, want success", resultText(result))
+	}
+	result := callTool(t, s, "search", map[string]interface{}{})
+	if !result.IsError || !strings.Contains(resultText(result), `"code":"`+ToolErrorRateLimited+`"`) {
+		t.Errorf("second call = %q, want a rate limit error", resultText(result))
+	}
+	if got := len(recorder.received()); got != 1 {
+		t.Errorf("backend received %d calls, want the limited call to be stopped", got)
+	}
+}
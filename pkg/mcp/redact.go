@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces secrets in logged or returned text
+const redactedPlaceholder = "[REDACTED]"
+
+// redactSecrets replaces every occurrence of the given secrets in s
+func redactSecrets(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	return s
+}
+
+// credentialPattern matches values following common credential header, field, and query parameter names, such as
+// "X-API-KEY: abc", "Authorization: Bearer abc", `"api_key": "abc"`, or "access_token=abc"
+var credentialPattern = regexp.MustCompile(`(?i)\b(x-api-key|api[_-]?key|authorization|proxy-authorization|access[_-]?token|token)` +
+	`("?\s*[:=]\s*"?)((?:bearer|basic)\s+)?[^\s"'&,;]+`)
+
+// redact scrubs the given secrets and any value following a common credential name from s, so it can be logged
+// or returned in an error
+func redact(s string, secrets ...string) string {
+	s = redactSecrets(s, secrets...)
+	return credentialPattern.ReplaceAllString(s, "${1}${2}${3}"+redactedPlaceholder)
+}
+
+// redactedError is an error whose message has been redacted, still unwrapping to the original error
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactError returns err with the given secrets and credential values scrubbed from its message
+func redactError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	msg := redact(err.Error(), secrets...)
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redact returns s with the client's API keys and any credential values scrubbed
+func (c *APIClient) redact(s string) string {
+	return redact(s, c.apiKeys()...)
+}
+
+// redactError returns err with the client's API keys and any credential values scrubbed from its message
+func (c *APIClient) redactError(err error) error {
+	return redactError(err, c.apiKeys()...)
+}
+
+// sensitiveHeaders are response headers whose values are never surfaced
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"}
+
+// redactHeaders returns a copy of h with sensitive header values and secrets redacted
+func redactHeaders(h http.Header, secrets ...string) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		redacted := make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = redactSecrets(v, secrets...)
+		}
+		out[name] = redacted
+	}
+	for _, name := range sensitiveHeaders {
+		if _, ok := out[name]; ok {
+			out[name] = []string{redactedPlaceholder}
+		}
+	}
+	return out
+}
+
+// redactURL returns the URL with any password and credential query parameters masked, for logging
+func redactURL(rawURL string, secrets ...string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redact(rawURL, secrets...)
+	}
+	return redact(u.Redacted(), secrets...)
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ReloadTools fetches the manifest again and replaces the registered tools and prompts, returning the
+// tools added and removed by name; connected clients receive notifications/tools/list_changed unless the
+// manifest is unchanged. Manifest resources stay as registered at startup. Canceling ctx aborts the manifest
+// fetch and keeps the current tools
+func (s *Server) ReloadTools(ctx context.Context) (added, removed []Tool, err error) {
+	added, removed, changed, err := s.reloadTools(ctx)
+	if err != nil || !changed {
+		return nil, nil, err
+	}
+
+	// Notify once the reload lock is released so the callback can use the server
+	s.notifyManifestRefresh(added, removed)
+	return added, removed, nil
+}
+
+// reloadTools replaces the registered tools and prompts under the reload lock, reporting whether anything changed
+func (s *Server) reloadTools(ctx context.Context) (added, removed []Tool, changed bool, err error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mutex.RLock()
+	oldTools, oldGeneration := s.tools, s.generation
+	s.mutex.RUnlock()
+	oldTemplates := s.manifestPromptTemplates()
+
+	manifestTools, generation, err := s.fetchTools(ctx, oldTools, oldGeneration)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to fetch toolset manifest: %w", err)
+	}
+
+	// Build every definition before touching the live registrations
+	tools, filtered := s.toolFilter.apply(manifestTools)
+	serverTools, serverPrompts, err := s.buildToolHandlers(tools)
+	if err != nil {
+		// Keep the prompt templates in step with the registrations that stay in place
+		for i, ep := range s.endpoints {
+			ep.prompts = oldTemplates[i]
+		}
+		return nil, nil, false, err
+	}
+
+	// Reloads are serialized, so the tools cannot have changed since they were read
+	if generation == oldGeneration && reflect.DeepEqual(oldTools, tools) && reflect.DeepEqual(oldTemplates, s.manifestPromptTemplates()) {
+		// Leave the registrations alone so clients are not told about a change that did not happen
+		return nil, nil, false, nil
+	}
+	s.mutex.Lock()
+	s.tools = tools
+	s.generation = generation
+	s.mutex.Unlock()
+
+	added, removed = diffTools(oldTools, tools)
+	s.logFilteredTools(filtered)
+
+	// Drop prompts that are gone and replace the tools in one operation; tool prompts are added last so they
+	// take precedence over manifest prompts of the same name, as they do at startup
+	serverPrompts = append(s.buildManifestPrompts(), serverPrompts...)
+	keep := make(map[string]bool, len(serverPrompts))
+	for _, prompt := range serverPrompts {
+		keep[prompt.Prompt.Name] = true
+	}
+	var stale []string
+	for _, tool := range oldTools {
+		if tool.Prompt && !keep[s.exposedName(tool)] {
+			stale = append(stale, s.exposedName(tool))
+		}
+	}
+	for i, templates := range oldTemplates {
+		for _, p := range templates {
+			if name := s.endpoints[i].Prefix + p.Name; !keep[name] {
+				stale = append(stale, name)
+			}
+		}
+	}
+	if len(stale) > 0 {
+		s.mcpServer.DeletePrompts(stale...)
+	}
+	if len(serverPrompts) > 0 {
+		s.mcpServer.AddPrompts(serverPrompts...)
+	}
+	s.mcpServer.SetTools(serverTools...)
+
+	s.logger.Info("Reloaded tools", "generation", generation, "tools", len(tools), "added", len(added), "removed", len(removed))
+	return added, removed, true, nil
+}
+
+// refreshManifestPeriodically reloads the tools every manifestRefresh until ctx is canceled, keeping the
+// current tools when a reload fails
+func (s *Server) refreshManifestPeriodically(ctx context.Context) {
+	s.logger.Info("Refreshing the manifest periodically", "interval", s.manifestRefresh)
+	ticker := time.NewTicker(s.manifestRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, _, err := s.ReloadTools(ctx); err != nil {
+				s.logger.Warn("Manifest refresh failed, keeping the current tools", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyManifestRefresh calls the refresh callback, logging instead of propagating a panic
+func (s *Server) notifyManifestRefresh(added, removed []Tool) {
+	if s.onManifestRefresh == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Manifest refresh callback failed", "panic", r)
+		}
+	}()
+	s.onManifestRefresh(added, removed)
+}
+
+// toolKey identifies a tool by endpoint and name
+type toolKey struct {
+	endpoint *endpoint
+	name     string
+}
+
+// diffTools returns the tools of next missing from prev and the tools of prev missing from next, by endpoint and name
+func diffTools(prev, next []Tool) (added, removed []Tool) {
+	prevKeys := make(map[toolKey]bool, len(prev))
+	for _, tool := range prev {
+		prevKeys[toolKey{tool.endpoint, tool.Name}] = true
+	}
+	nextKeys := make(map[toolKey]bool, len(next))
+	for _, tool := range next {
+		nextKeys[toolKey{tool.endpoint, tool.Name}] = true
+		if !prevKeys[toolKey{tool.endpoint, tool.Name}] {
+			added = append(added, tool)
+		}
+	}
+	for _, tool := range prev {
+		if !nextKeys[toolKey{tool.endpoint, tool.Name}] {
+			removed = append(removed, tool)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// changingBackend serves a manifest whose tools can be replaced between requests
+type changingBackend struct {
+	*httptest.Server
+	mu         sync.Mutex
+	tools      []string
+	generation int
+}
+
+// newChangingBackend starts a backend serving a manifest of the named tools and answering tool calls with "ok"
+func newChangingBackend(t *testing.T, tools ...string) *changingBackend {
+	t.Helper()
+	b := &changingBackend{tools: tools, generation: 1}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest" {
+			_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		entries := make([]string, len(b.tools))
+		for i, name := range b.tools {
+			entries[i] = fmt.Sprintf(`{"name":%q,"description":%q,"invoke_endpoints":{"json":"http://%s/%s"}}`, name, name, r.Host, name)
+		}
+		_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":%d,"tools":[%s]}}`,
+			b.generation, strings.Join(entries, ","))
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// setTools replaces the tools of the manifest under a new generation
+func (b *changingBackend) setTools(tools ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tools = tools
+	b.generation++
+}
+
+// toolNames returns the names of tools in order
+func toolNames(tools []Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestManifestRefreshCallbackReceivesTheDiff(t *testing.T) {
+	backend := newChangingBackend(t, "search", "export")
+	type diff struct{ added, removed, exposed []string }
+	var calls []diff
+	var s *Server
+	s = newToolServer(t, backend.Server, WithOnManifestRefresh(func(added, removed []Tool) {
+		// The callback runs outside the lock, so it can read the server
+		exposed, _ := s.ExposedTools()
+		var names []string
+		for _, tool := range exposed {
+			names = append(names, tool.Name)
+		}
+		calls = append(calls, diff{toolNames(added), toolNames(removed), names})
+	}))
+	if len(calls) != 0 {
+		t.Fatalf("callback ran %d times at startup, want only after reloads", len(calls))
+	}
+
+	backend.setTools("export", "report", "audit")
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("callback ran %d times, want once", len(calls))
+	}
+	if got := calls[0]; !slices.Equal(got.added, []string{"audit", "report"}) || !slices.Equal(got.removed, []string{"search"}) {
+		t.Errorf("callback received added %v and removed %v, want [audit report] and [search]", got.added, got.removed)
+	}
+	if got := calls[0].exposed; !slices.Equal(got, []string{"audit", "export", "report"}) {
+		t.Errorf("callback saw tools %v, want the reloaded tools", got)
+	}
+
+	// An unchanged manifest is not a refresh
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("callback ran %d times, want no call for an unchanged manifest", len(calls))
+	}
+}
+
+func TestManifestRefreshCallbackPanicsDoNotAbortTheReload(t *testing.T) {
+	backend := newChangingBackend(t, "search")
+	s := newToolServer(t, backend.Server, WithOnManifestRefresh(func(added, removed []Tool) {
+		panic("embedder bug")
+	}))
+
+	backend.setTools("search", "export")
+	added, _, err := s.ReloadTools(context.Background())
+	if err != nil || !slices.Equal(toolNames(added), []string{"export"}) {
+		t.Fatalf("ReloadTools() = %v, %v, want export added despite the callback", toolNames(added), err)
+	}
+	if got := listTools(t, s); !slices.Contains(got, "export") {
+		t.Errorf("tools/list = %v, want the reloaded tools registered", got)
+	}
+}
+
+// notificationSession is an initialized client session collecting the notifications sent to it
+type notificationSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (n *notificationSession) Initialize()       {}
+func (n *notificationSession) Initialized() bool { return true }
+func (n *notificationSession) SessionID() string { return "test-session" }
+func (n *notificationSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return n.notifications
+}
+
+// methods drains the notifications received so far and returns their methods
+func (n *notificationSession) methods() []string {
+	var methods []string
+	for {
+		select {
+		case notification := <-n.notifications:
+			methods = append(methods, notification.Method)
+		default:
+			return methods
+		}
+	}
+}
+
+func TestReloadToolsNotifiesConnectedClients(t *testing.T) {
+	backend := newChangingBackend(t, "search")
+	s := newToolServer(t, backend.Server)
+	session := &notificationSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := s.mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.setTools("search", "export")
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := session.methods(); !slices.Contains(got, string(mcp.MethodNotificationToolsListChanged)) {
+		t.Errorf("client received %v, want a tools list_changed notification", got)
+	}
+	if got := listTools(t, s); !slices.Equal(got, []string{"export", "search"}) {
+		t.Errorf("tools/list = %v, want the reloaded tools", got)
+	}
+
+	if _, _, err := s.ReloadTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := session.methods(); len(got) != 0 {
+		t.Errorf("client received %v after an unchanged reload, want nothing", got)
+	}
+}
+
+func TestToolCallsDuringReloads(t *testing.T) {
+	backend := newChangingBackend(t, "search", "export")
+	s := newToolServer(t, backend.Server)
+
+	request := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{}}}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				// Calls may hit a tool that was just removed, but must never panic or race
+				s.mcpServer.HandleMessage(ctx, request)
+			}
+		}()
+	}
+	for i := range 10 {
+		if i%2 == 0 {
+			backend.setTools("export")
+		} else {
+			backend.setTools("search", "export")
+		}
+		if _, _, err := s.ReloadTools(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}
+	cancel()
+	wg.Wait()
+}
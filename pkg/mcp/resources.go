@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResourceArgumentKey is the key of an argument object referencing an MCP resource, as in
+// {"$resource": "asgard://responses/search"}
+const ResourceArgumentKey = "$resource"
+
+// registerResponseResources serves each tool's latest response at RawResponseURIPrefix + tool name
+func (s *Server) registerResponseResources() {
+	template := mcp.NewResourceTemplate(RawResponseURIPrefix+"{tool}", "Latest tool response",
+		mcp.WithTemplateDescription("The raw response of the latest successful call to a tool"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	s.mcpServer.AddResourceTemplate(template, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		toolName := strings.TrimPrefix(req.Params.URI, RawResponseURIPrefix)
+		s.mutex.RLock()
+		data, ok := s.lastResponses[toolName]
+		s.mutex.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("no response recorded for tool %s", toolName)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}}, nil
+	})
+}
+
+// rememberResponse keeps the tool's latest response for reference by later calls
+func (s *Server) rememberResponse(toolName string, data json.RawMessage) {
+	if !s.resourceArguments {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.lastResponses == nil {
+		s.lastResponses = make(map[string]json.RawMessage)
+	}
+	s.lastResponses[toolName] = data
+}
+
+// resolveResourceArguments replaces every {"$resource": uri} object in args with the resource's content
+func (s *Server) resolveResourceArguments(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+	if !s.resourceArguments {
+		return args, nil
+	}
+
+	out := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		resolved, err := s.resolveResourceValue(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		out[name] = resolved
+	}
+	return out, nil
+}
+
+// resolveResourceValue resolves resource references within a single argument value
+func (s *Server) resolveResourceValue(ctx context.Context, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if uri, ok := v[ResourceArgumentKey].(string); ok && len(v) == 1 {
+			return s.readResource(ctx, uri)
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := s.resolveResourceValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := s.resolveResourceValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+// readResource reads a resource through the MCP server; JSON text becomes its value, other text a string,
+// and binary content a base64 string
+func (s *Server) readResource(ctx context.Context, uri string) (interface{}, error) {
+	request, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId("resource-argument"),
+		Request: mcp.Request{Method: string(mcp.MethodResourcesRead)},
+		Params:  mcp.ReadResourceParams{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource request: %w", err)
+	}
+
+	// Decode through JSON so any registered resource or template handler is honored
+	encoded, err := json.Marshal(s.mcpServer.HandleMessage(ctx, request))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource %s: %w", uri, err)
+	}
+	var response struct {
+		Result *struct {
+			Contents []struct {
+				MIMEType string  `json:"mimeType"`
+				Text     *string `json:"text"`
+				Blob     *string `json:"blob"`
+			} `json:"contents"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		return nil, fmt.Errorf("failed to read resource %s: %w", uri, err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("failed to read resource %s: %s", uri, response.Error.Message)
+	}
+	if response.Result == nil || len(response.Result.Contents) == 0 {
+		return nil, fmt.Errorf("resource %s has no content", uri)
+	}
+
+	content := response.Result.Contents[0]
+	switch {
+	case content.Text != nil:
+		var value interface{}
+		if strings.HasPrefix(content.MIMEType, "application/json") && json.Unmarshal([]byte(*content.Text), &value) == nil {
+			return value, nil
+		}
+		return *content.Text, nil
+	case content.Blob != nil:
+		return *content.Blob, nil
+	}
+	return nil, fmt.Errorf("resource %s has no content", uri)
+}
+
+// resourceServerOptions enables the resources capability when resource arguments are enabled or the manifest
+// declares resources
+func (s *Server) resourceServerOptions() []server.ServerOption {
+	if !s.resourceArguments && !s.hasManifestResources() {
+		return nil
+	}
+	return []server.ServerOption{server.WithResourceCapabilities(false, false)}
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newResourceBackend starts a backend whose search tool answers with a record and whose export tool is answered
+// by recorder
+func newResourceBackend(t *testing.T, recorder *bodyRecorder) *httptest.Server {
+	t.Helper()
+	return newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"id":7,"name":"acme"}}`)
+			return
+		}
+		recorder.reply(w, r)
+	}, searchTool, `{"name":"export","description":"Export","invoke_endpoints":{"json":"$BACKEND/export"}}`)
+}
+
+func TestResourceArgumentsAreResolvedBeforeTheCall(t *testing.T) {
+	var recorder bodyRecorder
+	s := newToolServer(t, newResourceBackend(t, &recorder), WithResourceArguments(true))
+	s.mcpServer.AddResource(mcp.NewResource("notes://today", "Today's note", mcp.WithMIMEType("text/plain")),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: "ship it"}}, nil
+		})
+
+	if result := callTool(t, s, "search", map[string]interface{}{}); result.IsError {
+		t.Fatalf("search returned %q", resultText(result))
+	}
+	result := callTool(t, s, "export", map[string]interface{}{
+		"record": map[string]interface{}{ResourceArgumentKey: RawResponseURIPrefix + "search"},
+		"notes":  []interface{}{map[string]interface{}{ResourceArgumentKey: "notes://today"}, "inline"},
+		"format": "csv",
+	})
+	if result.IsError {
+		t.Fatalf("export returned %q, want the references resolved", resultText(result))
+	}
+
+	bodies := recorder.received()
+	if len(bodies) != 1 {
+		t.Fatalf("backend received %d export calls, want 1", len(bodies))
+	}
+	if got, want := fmt.Sprint(bodies[0]), "map[format:csv notes:[ship it inline] record:map[id:7 name:acme]]"; got != want {
+		t.Errorf("backend received %s, want %s", got, want)
+	}
+}
+
+func TestResourceArgumentsNeedTheOption(t *testing.T) {
+	var recorder bodyRecorder
+	s := newToolServer(t, newResourceBackend(t, &recorder))
+
+	reference := map[string]interface{}{ResourceArgumentKey: RawResponseURIPrefix + "search"}
+	if result := callTool(t, s, "export", map[string]interface{}{"record": reference}); result.IsError {
+		t.Fatalf("export returned %q", resultText(result))
+	}
+	if got, want := fmt.Sprint(recorder.received()), "[map[record:map[$resource:asgard://responses/search]]]"; got != want {
+		t.Errorf("backend received %s, want the reference passed through", got)
+	}
+}
+
+func TestUnresolvableResourceArgumentsFailTheCall(t *testing.T) {
+	var recorder bodyRecorder
+	s := newToolServer(t, newResourceBackend(t, &recorder), WithResourceArguments(true))
+
+	// No search call has been made, so there is no response to reference
+	reference := map[string]interface{}{ResourceArgumentKey: RawResponseURIPrefix + "search"}
+	result := callTool(t, s, "export", map[string]interface{}{"record": reference})
+	if !result.IsError || !strings.Contains(resultText(result), "argument record") {
+		t.Errorf("result = %q, want an error naming the argument", resultText(result))
+	}
+	if got := len(recorder.received()); got != 0 {
+		t.Errorf("backend received %d calls, want none", got)
+	}
+}
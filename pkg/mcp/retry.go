@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed backend requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first; values below 2 disable retries
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on each further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, including delays requested through Retry-After
+	MaxDelay time.Duration
+	// Jitter randomizes each delay by up to this fraction in either direction, between 0 and 1
+	Jitter float64
+}
+
+// Retry defaults applied when a policy leaves delays unset
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 10 * time.Second
+)
+
+// RetryClassifier reports whether a failed request should be retried, given the response status
+// (zero when no response was received) and the transport error, if any
+type RetryClassifier func(status int, err error) bool
+
+// DefaultRetryClassifier retries transport errors, 429, and 5xx responses and treats other statuses as permanent
+func DefaultRetryClassifier(status int, err error) bool {
+	if err != nil {
+		return true
+	}
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// backoff returns the delay before the given retry, starting at one
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay)) //nolint:gosec
+	}
+	return delay
+}
+
+// retryAfter returns the delay requested by a Retry-After header in seconds or as an HTTP date, or zero
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// delay returns how long to wait before the given retry, honoring the server's Retry-After up to MaxDelay
+func (p RetryPolicy) delay(retry int, resp *http.Response) time.Duration {
+	delay := p.backoff(retry)
+	if requested := retryAfter(resp); requested > delay {
+		maxDelay := p.MaxDelay
+		if maxDelay <= 0 {
+			maxDelay = DefaultRetryMaxDelay
+		}
+		delay = min(requested, maxDelay)
+	}
+	return delay
+}
+
+// noRetryKey marks request contexts that must be attempted exactly once
+type noRetryKey struct{}
+
+// withoutRetries returns a context whose requests bypass the retry policy
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// retriesDisabled reports whether requests with the context must be attempted exactly once
+func retriesDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+	return disabled
+}
+
+// retryable reports whether calls of the tool may be retried: it must be marked retryable in the manifest or the
+// client's options, and not marked non-retryable in either
+func (c *APIClient) retryable(tool *Tool) bool {
+	if tool.NonRetryable || c.nonRetryableTools[tool.Name] {
+		return false
+	}
+	return tool.Retryable || c.retryableTools[tool.Name]
+}
+
+// doWithRetry executes the request, retrying failures the classifier deems transient according to the policy
+func (c *APIClient) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doOnce(req)
+
+		// Successful and permanently failed requests are returned as is
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		failed := err != nil || status >= 400
+		if !failed || attempt >= c.retryPolicy.MaxAttempts || retriesDisabled(req.Context()) || !c.retryClassifier(status, err) {
+			return resp, err
+		}
+
+		// A consumed body can only be replayed when the request knows how to recreate it
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		delay := c.retryPolicy.delay(attempt, resp)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		c.logger.Warn("Request failed, retrying", "method", req.Method, "url", redactURL(req.URL.String(), c.apiKeys()...),
+			"status", status, "error", c.redactError(err), "delay", delay, "attempt", attempt+1, "max_attempts", c.retryPolicy.MaxAttempts)
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyBackend fails the first requests of each method with a status, then answers the manifest and tool calls
+type flakyBackend struct {
+	*httptest.Server
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+// newFlakyBackend starts a backend failing the first failures requests of each method with status
+func newFlakyBackend(t *testing.T, status, failures int) *flakyBackend {
+	t.Helper()
+	b := &flakyBackend{attempts: make(map[string]int)}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		b.attempts[r.Method]++
+		attempt := b.attempts[r.Method]
+		b.mu.Unlock()
+
+		if attempt <= failures {
+			http.Error(w, "try again", status)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// attemptsOf returns the number of requests received with the method
+func (b *flakyBackend) attemptsOf(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attempts[method]
+}
+
+// tool returns a tool invoked at the backend
+func (b *flakyBackend) tool(name string) *Tool {
+	return &Tool{Name: name, InvokeEndpoints: ToolInvokeEndpoints{JSON: b.URL + "/" + name}}
+}
+
+// retryTwice is a retry policy allowing three attempts without noticeable delays
+var retryTwice = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+func TestToolCallsAreNotRetriedByDefault(t *testing.T) {
+	backend := newFlakyBackend(t, http.StatusServiceUnavailable, 1)
+	c := newTestClient(backend.URL, retryTwice)
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.tool("send"), []byte(`{}`)); err == nil {
+		t.Error("ExecuteToolRequest() succeeded, want the 503 of the only attempt")
+	}
+	if got := backend.attemptsOf(http.MethodPost); got != 1 {
+		t.Errorf("backend received %d POSTs, want 1", got)
+	}
+}
+
+func TestManifestFetchesAreRetried(t *testing.T) {
+	backend := newFlakyBackend(t, http.StatusBadGateway, 2)
+	c := newTestClient(backend.URL, retryTwice)
+
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Fatalf("FetchToolsetManifest() = %v, want the third attempt to succeed", err)
+	}
+	if got := backend.attemptsOf(http.MethodGet); got != 3 {
+		t.Errorf("backend received %d GETs, want 3", got)
+	}
+}
+
+func TestRetryableToolCallsAreRetried(t *testing.T) {
+	tests := map[string]struct {
+		tool func(*flakyBackend) *Tool
+		opts []APIClientOption
+	}{
+		"flagged in the manifest": {tool: func(b *flakyBackend) *Tool {
+			tool := b.tool("lookup")
+			tool.Retryable = true
+			return tool
+		}},
+		"listed in the options": {
+			tool: func(b *flakyBackend) *Tool { return b.tool("lookup") },
+			opts: []APIClientOption{WithRetryableTools("lookup")},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newFlakyBackend(t, http.StatusServiceUnavailable, 1)
+			c := newTestClient(backend.URL, append(tt.opts, retryTwice)...)
+
+			if _, err := c.ExecuteToolRequest(context.Background(), tt.tool(backend), []byte(`{}`)); err != nil {
+				t.Fatalf("ExecuteToolRequest() = %v, want the retry to succeed", err)
+			}
+			if got := backend.attemptsOf(http.MethodPost); got != 2 {
+				t.Errorf("backend received %d POSTs, want 2", got)
+			}
+		})
+	}
+}
+
+func TestCustomRetryClassifier(t *testing.T) {
+	retryConflicts := func(status int, err error) bool {
+		return status == http.StatusConflict || DefaultRetryClassifier(status, err)
+	}
+	tests := map[string]struct {
+		classifier RetryClassifier
+		wantErr    bool
+		attempts   int
+	}{
+		"default treats 409 as permanent": {classifier: DefaultRetryClassifier, wantErr: true, attempts: 1},
+		"custom retries 409":              {classifier: retryConflicts, attempts: 2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newFlakyBackend(t, http.StatusConflict, 1)
+			c := newTestClient(backend.URL, retryTwice, WithRetryClassifier(tt.classifier), WithRetryableTools("update"))
+
+			_, err := c.ExecuteToolRequest(context.Background(), backend.tool("update"), []byte(`{}`))
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("ExecuteToolRequest() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := backend.attemptsOf(http.MethodPost); got != tt.attempts {
+				t.Errorf("backend received %d POSTs, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestNonRetryableToolsMakeOneAttempt(t *testing.T) {
+	tests := map[string]struct {
+		tool func(*flakyBackend) *Tool
+		opts []APIClientOption
+	}{
+		"flagged in the manifest": {tool: func(b *flakyBackend) *Tool {
+			tool := b.tool("pay")
+			tool.Retryable, tool.NonRetryable = true, true
+			return tool
+		}},
+		"listed in the options": {
+			tool: func(b *flakyBackend) *Tool { return b.tool("pay") },
+			opts: []APIClientOption{WithRetryableTools("pay"), WithNonRetryableTools("pay")},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newFlakyBackend(t, http.StatusServiceUnavailable, 1)
+			c := newTestClient(backend.URL, append(tt.opts, retryTwice)...)
+
+			if _, err := c.ExecuteToolRequest(context.Background(), tt.tool(backend), []byte(`{}`)); err == nil {
+				t.Error("ExecuteToolRequest() succeeded, want the 503 of the only attempt")
+			}
+			if got := backend.attemptsOf(http.MethodPost); got != 1 {
+				t.Errorf("backend received %d POSTs of a non-retryable tool, want 1", got)
+			}
+		})
+	}
+}
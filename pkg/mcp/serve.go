@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transport selects how the server is exposed to MCP clients
+type Transport string
+
+const (
+	// TransportStdio serves a single client over standard input and output
+	TransportStdio Transport = "stdio"
+	// TransportSSE serves clients over the MCP HTTP+SSE transport
+	TransportSSE Transport = "sse"
+	// TransportStreamableHTTP serves clients over the MCP streamable-HTTP transport
+	TransportStreamableHTTP Transport = "streamable-http"
+)
+
+// Defaults for HTTP transports
+const (
+	DefaultListenAddr = "127.0.0.1:8080"
+
+	// StreamableHTTPPath is the path of the streamable-HTTP endpoint
+	StreamableHTTPPath = "/mcp"
+	// SSEPath and SSEMessagePath are the event stream and message paths of the SSE transport
+	SSEPath        = "/sse"
+	SSEMessagePath = "/message"
+	// DebugCallsPath is the path serving the recent call log on HTTP transports
+	DebugCallsPath = "/debug/calls"
+
+	// shutdownTimeout bounds how long in-flight HTTP requests may take to finish on shutdown
+	shutdownTimeout = 10 * time.Second
+)
+
+// serveStdio serves the MCP server over standard input and output
+func (s *Server) serveStdio() error {
+	// Create the stdio asgard-mcp-server
+	stdioServer := server.NewStdioServer(s.mcpServer)
+
+	// Set up error logging
+	stdioServer.SetErrorLogger(slog.NewLogLogger(s.logger.Handler(), slog.LevelError))
+
+	// Start the asgard-mcp-server
+	return server.ServeStdio(s.mcpServer)
+}
+
+// serveStreamableHTTP serves the MCP server over streamable HTTP until SIGINT or SIGTERM, then shuts down gracefully
+func (s *Server) serveStreamableHTTP() error {
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:              s.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	streamable := server.NewStreamableHTTPServer(s.mcpServer, server.WithStreamableHTTPServer(httpServer))
+	mux.Handle(StreamableHTTPPath, streamable)
+	if s.callLog != nil {
+		mux.Handle(DebugCallsPath, s.DebugHandler())
+	}
+
+	return s.serveHTTP(httpServer, streamable.Shutdown)
+}
+
+// serveSSE serves the MCP server over HTTP+SSE until SIGINT or SIGTERM, then shuts down gracefully
+func (s *Server) serveSSE() error {
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:              s.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	sseServer := server.NewSSEServer(s.mcpServer,
+		server.WithHTTPServer(httpServer),
+		server.WithSSEEndpoint(SSEPath),
+		server.WithMessageEndpoint(SSEMessagePath),
+		server.WithUseFullURLForMessageEndpoint(false),
+		server.WithKeepAlive(true),
+	)
+	mux.Handle(SSEPath, sseServer)
+	mux.Handle(SSEMessagePath, sseServer)
+	if s.callLog != nil {
+		mux.Handle(DebugCallsPath, s.DebugHandler())
+	}
+
+	return s.serveHTTP(httpServer, sseServer.Shutdown)
+}
+
+// serveHTTP runs an HTTP server until SIGINT or SIGTERM and then calls shutdown with a bounded deadline
+func (s *Server) serveHTTP(httpServer *http.Server, shutdown func(context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Listening", "addr", httpServer.Addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve HTTP: %w", err)
+	}
+	return nil
+}
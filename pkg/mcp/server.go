@@ -1,15 +1,20 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Server represents the local MCP asgard-mcp-server
@@ -17,31 +22,158 @@ type Server struct {
 	endpointURL string
 	apiKey      string
 	tools       []Tool
+	generation  int
 	mutex       sync.RWMutex
 	apiClient   *APIClient
 	mcpServer   *server.MCPServer
+	promptMode  PromptMode
+
+	// clientOpts configure the API client created for the endpoint
+	clientOpts []APIClientOption
+
+	// unknownArguments decides what happens to arguments the schema does not declare
+	unknownArguments UnknownArgumentsMode
+
+	// argumentRules adapt arguments to the contract of the manifest generation
+	argumentRules []ArgumentRule
+
+	// normalizeRules trim values and normalize key casing per tool
+	normalizeRules []NormalizeRule
+
+	// toolTags holds locally configured tags per tool, merged with manifest tags
+	toolTags map[string][]string
+
+	// descriptionOverrides replaces or extends manifest descriptions and titles per tool
+	descriptionOverrides map[string]DescriptionOverride
+
+	// structuredErrors returns tool errors as JSON objects instead of plain text
+	structuredErrors bool
+
+	// errorDiagnostics appends backend response details to tool errors
+	errorDiagnostics bool
+
+	// requestIDMeta adds the backend's request ID to tool result metadata
+	requestIDMeta bool
+
+	// stats accumulates per-tool call outcomes; callSummary logs them when serving stops
+	stats       *callStats
+	callSummary bool
+
+	// callLog retains recent tool calls for debugging
+	callLog        *callLog
+	callLogSize    int
+	callLogBodyCap int
+
+	// includeRawData attaches the exact response bytes next to the formatted text
+	includeRawData bool
+
+	// rawResponses returns the exact response bytes as the text instead of pretty-printing them
+	rawResponses bool
+
+	// collapseTools holds tools whose single-key responses are collapsed to the value
+	collapseTools map[string]bool
+
+	// noUploads blocks file uploads when not off
+	noUploads UploadSafeMode
+
+	// uploadPathsSchema adds the uploaded file paths argument, named uploadField, to the schema of upload tools
+	uploadPathsSchema bool
+	uploadField       string
+
+	// resourceArguments resolves resource references in arguments and serves each tool's latest response
+	resourceArguments bool
+	lastResponses     map[string]json.RawMessage
+
+	// onManifestRefresh is notified of the tools added and removed by each reload
+	onManifestRefresh func(added, removed []Tool)
+
+	// manifestRefresh reloads the manifest at this interval while serving when positive
+	manifestRefresh time.Duration
+
+	// reloadMu serializes reloads so their registrations cannot interleave
+	reloadMu sync.Mutex
+
+	// transport and listenAddr select how clients connect
+	transport  Transport
+	listenAddr string
+
+	// logManifestOrder keeps tools in manifest order rather than sorted by name in logs, reload diffs, and
+	// ExposedTools
+	logManifestOrder bool
+
+	// allowEmptyManifest serves manifests declaring no tools with a warning instead of failing their fetch
+	allowEmptyManifest bool
+
+	// toolFilter selects the manifest tools that are exposed
+	toolFilter ToolFilter
+
+	// endpoints are the primary endpoint followed by extraEndpoints, each with its own client
+	endpoints      []*endpoint
+	extraEndpoints []Endpoint
+
+	// validateArguments checks arguments against each tool's input schema before calling the backend
+	validateArguments bool
+
+	// toolPrefix is prepended to the names clients see, while the backend keeps the manifest names
+	toolPrefix string
+
+	// binaryTools holds tools whose responses are treated as binary on top of the manifest flag
+	binaryTools map[string]bool
+
+	// logger records requests and tool calls
+	logger *slog.Logger
+
+	// metrics are served on metricsAddr when set
+	metrics     *metrics
+	metricsAddr string
+
+	// version is reported to clients as the server version
+	version string
+
+	// callTimings times tool calls between the before and after hooks
+	callTimings callTimings
+
+	// maxResponseSize and maxLogSize bound response text returned to clients and written to the logs when positive
+	maxResponseSize int
+	maxLogSize      int
+
+	// callTimeout bounds each tool call as a whole when positive
+	callTimeout time.Duration
+
+	// progressInterval sends progress heartbeats during tool calls when positive
+	progressInterval time.Duration
+
+	// rateLimiter bounds the call rate of individual tools
+	rateLimiter *toolRateLimiter
+
+	// healthAddr serves liveness and readiness checks when set, readyMaxAge bounding the manifest age when positive
+	healthAddr  string
+	readyMaxAge time.Duration
 }
 
 // NewServer creates a new MCP asgard-mcp-server with the provided endpoint URL and API key
-func NewServer(endpointURL, apiKey string) (*Server, error) {
-	// Create the asgard-mcp-server
-	s := &Server{
-		endpointURL: endpointURL,
-		apiKey:      apiKey,
-	}
+func NewServer(endpointURL, apiKey string, opts ...ServerOption) (*Server, error) {
+	return NewServerWithContext(context.Background(), endpointURL, apiKey, opts...)
+}
 
-	// Create API client
-	s.apiClient = NewAPIClient(endpointURL, apiKey)
+// NewServerWithContext creates a server like NewServer; canceling ctx aborts the manifest fetches
+func NewServerWithContext(ctx context.Context, endpointURL, apiKey string, opts ...ServerOption) (*Server, error) {
+	s := newServer(endpointURL, apiKey, opts...)
 
-	// Fetch the toolset manifest
-	manifest, err := s.apiClient.FetchToolsetManifest()
+	// Fetch the toolset manifests
+	manifestTools, generation, err := s.fetchTools(ctx, nil, 0)
 	if err != nil {
+		s.closeEndpoints()
 		return nil, fmt.Errorf("failed to fetch toolset manifest: %w", err)
 	}
 
-	// Store tools from manifest
+	// Store the exposed tools from manifest
+	tools, filtered := s.toolFilter.apply(manifestTools)
+	s.logFilteredTools(filtered)
+	s.logDescriptionOverrides(manifestTools)
 	s.mutex.Lock()
-	s.tools = manifest.Tools
+	s.tools = tools
+	s.generation = generation
 	s.mutex.Unlock()
 
 	// Create hooks for logging
@@ -49,68 +181,100 @@ func NewServer(endpointURL, apiKey string) (*Server, error) {
 
 	// Add hook to log incoming requests
 	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
-		log.Printf("[RPC] Received method: %s", method)
+		s.logger.Info("Received request", "method", method)
 	})
 
 	// Add hook to log successful responses
 	hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
-		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		resultJSON, err := json.Marshal(result)
 		if err != nil {
-			log.Printf("[RPC] Response for method %s (failed to marshal)", method)
+			s.logger.Warn("Sent response that failed to marshal", "method", method, "error", err)
 			return
 		}
-		log.Printf("[RPC] Response for method %s: %s", method, string(resultJSON))
+		s.logger.Info("Sent response", "method", method, "bytes", len(resultJSON))
+		// Payloads may carry personal data, so they are only logged when debugging
+		s.logger.Debug("Sent response payload", "method", method, "result", s.truncateLog(string(resultJSON)))
 	})
 
 	// Add hook to log errors
 	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
-		log.Printf("[RPC] Error for method %s: %v", method, err)
+		s.logger.Error("Request failed", "method", method, "error", err)
+		// Failed calls never reach the after-call hook
+		if method == mcp.MethodToolsCall {
+			s.callTimings.finish(ctx, id)
+		}
 	})
 
 	// Add detailed logging for tool call requests
 	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
 		// Marshal tool arguments for detailed logging
-		argsJSON, err := json.MarshalIndent(message.Params.Arguments, "", "  ")
+		argsJSON, err := json.Marshal(message.Params.Arguments)
+		s.callTimings.begin(ctx, id, len(argsJSON))
 		if err != nil {
-			log.Printf("[RPC-TOOL] Call to tool '%s' with arguments (failed to marshal)", message.Params.Name)
+			s.logger.Warn("Tool call arguments failed to marshal", "tool", message.Params.Name, "error", err)
 			return
 		}
-		log.Printf("[RPC-TOOL] Call to tool '%s' with arguments: %s", message.Params.Name, string(argsJSON))
+		s.logger.Info("Tool call", "tool", message.Params.Name, "bytes", len(argsJSON))
+		s.logger.Debug("Tool call arguments", "tool", message.Params.Name, "arguments", s.truncateLog(string(argsJSON)))
 	})
 
 	// Add detailed logging for tool call responses
 	hooks.AddAfterCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest, result *mcp.CallToolResult) {
+		logger := s.logger.With("tool", message.Params.Name)
+		if timing, ok := s.callTimings.finish(ctx, id); ok {
+			logger = logger.With("duration_ms", time.Since(timing.start).Milliseconds(), "request_bytes", timing.requestBytes)
+		}
+		if resultJSON, err := json.Marshal(result); err == nil {
+			logger = logger.With("response_bytes", len(resultJSON))
+		}
 		switch {
 		case result.IsError:
-			log.Printf("[RPC-TOOL] Tool '%s' response (error)", message.Params.Name)
+			logger.Info("Tool call result", "content", "error")
 		case len(result.Content) > 0:
 			// Log first content item type
 			switch content := result.Content[0].(type) {
 			case mcp.TextContent:
-				log.Printf("[RPC-TOOL] Tool '%s' response (text): %s", message.Params.Name, content.Text)
+				logger.Info("Tool call result", "content", "text", "bytes", len(content.Text))
+				logger.Debug("Tool call result text", "text", s.truncateLog(content.Text))
 			case mcp.ImageContent:
-				log.Printf("[RPC-TOOL] Tool '%s' response (image): %s", message.Params.Name, content.MIMEType)
+				logger.Info("Tool call result", "content", "image", "mime_type", content.MIMEType)
 			case mcp.AudioContent:
-				log.Printf("[RPC-TOOL] Tool '%s' response (audio): %s", message.Params.Name, content.MIMEType)
+				logger.Info("Tool call result", "content", "audio", "mime_type", content.MIMEType)
 			case mcp.EmbeddedResource:
-				log.Printf("[RPC-TOOL] Tool '%s' response (resource)", message.Params.Name)
+				logger.Info("Tool call result", "content", "resource")
 			default:
-				log.Printf("[RPC-TOOL] Tool '%s' response (unknown content type)", message.Params.Name)
+				logger.Info("Tool call result", "content", "unknown")
 			}
 		default:
-			log.Printf("[RPC-TOOL] Tool '%s' response (empty)", message.Params.Name)
+			logger.Info("Tool call result", "content", "empty")
 		}
 	})
 
 	// Create MCP asgard-mcp-server with options
-	s.mcpServer = server.NewMCPServer(
-		"asgard-mcp-asgard-mcp-server",
-		"0.0.1",
+	serverOpts := []server.ServerOption{
 		server.WithToolCapabilities(true),
 		server.WithHooks(hooks),
 		server.WithLogging(),
+	}
+	if s.promptsEnabled() || s.hasManifestPrompts() {
+		serverOpts = append(serverOpts, server.WithPromptCapabilities(false))
+	}
+	serverOpts = append(serverOpts, s.resourceServerOptions()...)
+	s.mcpServer = server.NewMCPServer(
+		"asgard-mcp-asgard-mcp-server",
+		s.version,
+		serverOpts...,
 	)
 
+	// Serve tool responses for reference by later calls
+	if s.resourceArguments {
+		s.registerResponseResources()
+	}
+
+	// Serve the data sources and prompt templates of the manifests
+	s.registerManifestResources()
+	s.registerManifestPrompts()
+
 	// Register tool handlers
 	if err := s.registerToolHandlers(); err != nil {
 		return nil, fmt.Errorf("failed to register tool handlers: %w", err)
@@ -119,85 +283,274 @@ func NewServer(endpointURL, apiKey string) (*Server, error) {
 	return s, nil
 }
 
+// newServer creates the server and the client of every endpoint from the options, without fetching any manifest
+func newServer(endpointURL, apiKey string, opts ...ServerOption) *Server {
+	s := &Server{
+		endpointURL: endpointURL,
+		apiKey:      apiKey,
+		promptMode:  PromptModeOff,
+
+		unknownArguments:   UnknownArgumentsPass,
+		validateArguments:  true,
+		allowEmptyManifest: true,
+		noUploads:          UploadSafeModeOff,
+		uploadPathsSchema:  true,
+		uploadField:        UploadedFilePathsFieldName,
+		transport:          TransportStdio,
+		listenAddr:         DefaultListenAddr,
+
+		stats:          newCallStats(),
+		callLogSize:    DefaultCallLogSize,
+		callLogBodyCap: DefaultCallLogBodyCap,
+		logger:         slog.Default(),
+
+		maxResponseSize: DefaultMaxResponseSize,
+		maxLogSize:      DefaultMaxLogSize,
+		version:         DefaultVersion,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Log from the clients through the same logger, set first so options log through it too
+	s.clientOpts = append([]APIClientOption{WithClientLogger(s.logger)}, s.clientOpts...)
+
+	// Refuse uploads in the client as well when safe mode is active
+	if s.uploadsBlocked() {
+		s.logger.Warn("File uploads are disabled by safe mode", "mode", s.noUploads)
+		s.clientOpts = append(s.clientOpts, WithUploadsDisabled())
+	}
+
+	// Keep no more of a streamed response than the client is sent
+	s.clientOpts = append(s.clientOpts, withMaxStreamSize(s.maxResponseSize))
+
+	// Collect metrics in the clients as well
+	if s.metricsAddr != "" {
+		s.metrics = newMetrics()
+		s.clientOpts = append(s.clientOpts, withClientMetrics(s.metrics))
+	}
+
+	// Create API clients
+	s.apiClient = NewAPIClientWithOptions(endpointURL, apiKey, s.clientOpts...)
+	s.connectEndpoints()
+
+	// Create the recent call log
+	if s.callLogSize > 0 {
+		s.callLog = newCallLog(s.callLogSize, s.callLogBodyCap, s.apiKeys)
+	}
+	return s
+}
+
 // Start starts the MCP asgard-mcp-server, handling stdin/stdout communication
 func (s *Server) Start() error {
-	log.Println("Starting MCP asgard-mcp-server...")
-	log.Printf("Endpoint: %s", s.endpointURL)
-
 	s.mutex.RLock()
-	log.Printf("Available tools: %d", len(s.tools))
+	s.logger.Info("Starting MCP asgard-mcp-server", "endpoint", redactURL(s.endpointURL, s.apiKeys()...), "tools", len(s.tools))
 	for _, tool := range s.tools {
-		log.Printf("  - %s: %s", tool.Name, tool.Description)
+		s.logger.Info("Available tool", "tool", s.exposedName(tool), "description", tool.Description)
 	}
 	s.mutex.RUnlock()
 
-	// Create the stdio asgard-mcp-server
-	stdioServer := server.NewStdioServer(s.mcpServer)
+	// Stop watching the API key file once serving stops
+	defer s.closeEndpoints()
 
-	// Set up error logging
-	stdioServer.SetErrorLogger(log.New(os.Stderr, "[ERROR] ", log.LstdFlags))
+	// Summarize the session once serving stops
+	if s.callSummary {
+		defer s.stats.logSummary(s.logger)
+	}
+
+	// Allow dumping recent calls on demand
+	if s.callLog != nil {
+		s.dumpCallLogOnSignal()
+	}
 
-	// Start the asgard-mcp-server
-	return server.ServeStdio(s.mcpServer)
+	// Expose metrics next to the MCP transport
+	if s.metrics != nil {
+		defer s.serveMetrics()()
+	}
+	if s.healthAddr != "" {
+		defer s.serveHealth()()
+	}
+
+	// Keep the tools in sync with the manifest, aborting a running refresh once serving stops
+	if s.manifestRefresh > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.refreshManifestPeriodically(ctx)
+	}
+
+	// Serve over the selected transport
+	switch s.transport {
+	case TransportSSE:
+		return s.serveSSE()
+	case TransportStreamableHTTP:
+		return s.serveStreamableHTTP()
+	default:
+		return s.serveStdio()
+	}
 }
 
 // registerToolHandlers registers all tools from the manifest with the MCP asgard-mcp-server
 func (s *Server) registerToolHandlers() error {
 	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	tools := s.tools
+	s.mutex.RUnlock()
+
+	serverTools, serverPrompts, err := s.buildToolHandlers(tools)
+	if err != nil {
+		return err
+	}
+
+	// Register the staged prompts and tools with the asgard-mcp-server
+	if len(serverPrompts) > 0 {
+		s.mcpServer.AddPrompts(serverPrompts...)
+	}
+	if len(serverTools) > 0 {
+		s.mcpServer.AddTools(serverTools...)
+	}
+
+	return nil
+}
+
+// buildToolHandlers stages the MCP tool and prompt definitions for the given manifest tools
+func (s *Server) buildToolHandlers(tools []Tool) ([]server.ServerTool, []server.ServerPrompt, error) {
+	// Stage every definition so they are registered in one operation each
+	var serverTools []server.ServerTool
+	var serverPrompts []server.ServerPrompt
 
 	// Register handlers for each tool
-	for _, tool := range s.tools {
+	for _, tool := range tools {
 		// Create a local copy of the tool to avoid closure issues
 		localTool := tool
+		if s.skipsUploadTool(localTool) {
+			s.logger.Info("Skipping upload tool in safe mode", "tool", localTool.Name)
+			continue
+		}
+		localTool = s.overrideDescription(localTool)
+		if s.binaryTools[localTool.Name] {
+			localTool.BinaryOutput = true
+		}
+		name := s.exposedName(localTool)
+
+		// argumentSchema validates arguments before the backend is called, once the schema is compiled below
+		var argumentSchema *jsonschema.Schema
 
 		// Define a handler for the tool
 		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Trace the whole call
+			ctx, span := tracer().Start(ctx, "tools/call "+name, trace.WithAttributes(attrToolName.String(name)))
+			defer span.End()
+
+			// Substitute referenced resources, then apply configured argument transformations
+			args, err := s.resolveResourceArguments(ctx, req.GetArguments())
+			if err != nil {
+				return s.toolError(name, ToolErrorInvalidArguments, fmt.Sprintf("Invalid arguments: %v", err), err), nil
+			}
+			args, err = s.transformArguments(localTool, args)
+			if err != nil {
+				return s.toolError(name, ToolErrorInvalidArguments, fmt.Sprintf("Invalid arguments: %v", err), err), nil
+			}
+			if err := validateArguments(argumentSchema, args); err != nil {
+				return s.toolError(name, ToolErrorInvalidArguments, fmt.Sprintf("Invalid arguments: %v", err), err), nil
+			}
+
 			// Create the arguments JSON
-			argsJSON, err := json.Marshal(req.Params.Arguments)
+			argsJSON, err := json.Marshal(args)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
+				return s.toolError(name, ToolErrorInvalidArguments, fmt.Sprintf("Failed to marshal arguments: %v", err), err), nil
+			}
+
+			// Enforce the tool's rate limit
+			if err := s.rateLimiter.wait(ctx, localTool.Name); err != nil {
+				s.logger.Warn("Tool call rate limited", "tool", name, "error", err)
+				return s.toolError(name, ToolErrorRateLimited, fmt.Sprintf("Tool %s is rate limited (%v); retry later", name, err), err), nil
 			}
 
 			// Log API call
-			log.Printf("[API-CALL] Executing tool '%s'", localTool.Name)
+			s.logger.Info("Executing tool", "tool", name)
 
 			// Execute the tool request
 			// The APIClient.ExecuteToolRequest method now handles the Asgard response format
 			// and returns the "data" field content when applicable
-			responseJSON, err := s.apiClient.ExecuteToolRequest(&localTool, argsJSON)
+			start := time.Now()
+			done := s.metrics.callStarted(name)
+			callCtx, stopProgress := s.reportProgress(ctx, req, name)
+			callCtx, cancel, timeout := s.withCallDeadline(callCtx, localTool)
+			resp, err := s.clientFor(localTool).invokeTool(callCtx, &localTool, argsJSON)
+			// Only the call's own deadline counts, not the client giving up on the call
+			timedOut := err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+			cancel()
+			stopProgress()
+			var responseJSON json.RawMessage
+			if resp != nil {
+				responseJSON = resp.Data
+			}
+			duration := time.Since(start)
+			done(duration, err)
+			s.recordCall(name, argsJSON, responseJSON, err, duration)
+			if timedOut {
+				err = fmt.Errorf("tool call exceeded deadline of %s: %w", timeout, err)
+				failSpan(span, err)
+				s.logger.Error("Tool call timed out", "tool", name, "duration_ms", duration.Milliseconds(), "error", err)
+				return s.toolError(name, ToolErrorTimeout, fmt.Sprintf("Tool call exceeded deadline of %s", timeout), err), nil
+			}
+			if errors.Is(err, ErrBackendUnavailable) {
+				s.logger.Warn("Tool call short-circuited", "tool", name, "error", err)
+				return s.toolError(name, ToolErrorBackendUnavailable, fmt.Sprintf("Tool execution failed: %v", err), err), nil
+			}
 			if err != nil {
-				log.Printf("[API-CALL] Tool '%s' execution failed: %v", localTool.Name, err)
-				return mcp.NewToolResultError(fmt.Sprintf("Tool execution failed: %v", err)), nil
+				failSpan(span, err)
+				s.logger.Error("Tool execution failed", "tool", name, "duration_ms", duration.Milliseconds(), "error", err)
+				return s.toolError(name, ToolErrorExecutionFailed, fmt.Sprintf("Tool execution failed: %v", err), err), nil
 			}
 
-			log.Printf("[API-CALL] Tool '%s' response received: %d bytes", localTool.Name, len(responseJSON))
+			s.logger.Info("Tool response received", "tool", name, "duration_ms", duration.Milliseconds(), "bytes", len(responseJSON))
+			span.SetAttributes(attrResponseSize.Int(len(responseJSON)))
 
-			// Parse the response
-			var responseObj interface{}
-			if err := json.Unmarshal(responseJSON, &responseObj); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to parse tool response: %v", err)), nil
+			// Route binary output, images, and audio straight to MCP content
+			if localTool.BinaryOutput || isMediaContent(resp.ContentType) {
+				return s.withRequestID(binaryToolResult(name, resp.Data, resp.ContentType), resp.RequestID), nil
 			}
 
-			// Format the response as indented JSON for readability
-			responseText, err := json.MarshalIndent(responseObj, "", "  ")
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to format tool response: %v", err)), nil
+			rawJSON := responseJSON
+			s.rememberResponse(name, rawJSON)
+
+			// Collapse single-field envelopes when opted in for this tool
+			if s.collapseTools[localTool.Name] {
+				if value, ok := collapseSingleField(responseJSON); ok {
+					var text string
+					if err := json.Unmarshal(value, &text); err == nil {
+						return s.withRequestID(s.withRawData(name, mcp.NewToolResultText(s.truncateResponse(name, text)), rawJSON), resp.RequestID), nil
+					}
+					responseJSON = value
+				}
+			}
+
+			// Format the response for readability unless it is wanted verbatim
+			responseText := string(responseJSON)
+			if !s.rawResponses {
+				responseText, err = formatToolResponse(responseJSON)
+				if err != nil {
+					return s.toolError(name, ToolErrorInvalidResponse, fmt.Sprintf("Invalid tool response: %v", err), err), nil
+				}
 			}
 
-			return mcp.NewToolResultText(string(responseText)), nil
+			return s.withRequestID(s.withRawData(name, mcp.NewToolResultText(s.truncateResponse(name, responseText)), rawJSON), resp.RequestID), nil
 		}
 
 		// Create an MCP Tool definition
 		mcpTool := mcp.Tool{
-			Name:        localTool.Name,
-			Description: localTool.Description,
+			Name:        name,
+			Description: describeWithTags(localTool.Description, s.mergedTags(localTool)),
+			Annotations: localTool.Annotations.hints(),
 		}
+		mcpTool.Annotations.Title = toolTitle(localTool)
 
 		// Convert input schema from JSON to ToolInputSchema
 		var schema map[string]interface{}
 		if err := json.Unmarshal(localTool.InputSchema, &schema); err != nil {
-			return fmt.Errorf("failed to parse input schema for tool %s: %w", localTool.Name, err)
+			return nil, nil, fmt.Errorf("failed to parse input schema for tool %s: %w", localTool.Name, err)
 		}
 
 		if schema != nil {
@@ -205,14 +558,17 @@ func (s *Server) registerToolHandlers() error {
 			if _, ok := schema["type"]; !ok {
 				schema["type"] = "object"
 			}
-			if tool.AllowUploadFiles {
+			if tool.AllowUploadFiles && !s.uploadsBlocked() && s.uploadPathsSchema {
 				// Ensue the schema has the required 'properties' field
 				if _, ok := schema["properties"]; !ok {
 					schema["properties"] = make(map[string]interface{})
 				}
 				// Append the UploadedFilePaths field if the tool allows file uploads
 				if props, ok := schema["properties"].(map[string]interface{}); ok {
-					props[UploadedFilePathsFieldName] = UploadedFilePathsSchema
+					if _, taken := props[s.uploadField]; taken {
+						s.logger.Warn("Upload paths field replaces a property of the tool's schema", "tool", localTool.Name, "field", s.uploadField)
+					}
+					props[s.uploadField] = UploadedFilePathsSchema
 				}
 			}
 		}
@@ -220,15 +576,110 @@ func (s *Server) registerToolHandlers() error {
 		// Convert schema back to JSON for the tool definition
 		updatedSchema, err := json.Marshal(schema)
 		if err != nil {
-			return fmt.Errorf("failed to marshal updated input schema for tool %s: %w", localTool.Name, err)
+			return nil, nil, fmt.Errorf("failed to marshal updated input schema for tool %s: %w", localTool.Name, err)
 		}
 
 		// Set the RawInputSchema to the modified schema
 		mcpTool.RawInputSchema = updatedSchema
+		if s.validateArguments {
+			argumentSchema = s.compileArgumentSchema(name, updatedSchema)
+		}
+
+		// Expose prompt-flagged tools through the prompts capability when enabled
+		if localTool.Prompt && s.promptsEnabled() {
+			prompt := newToolPrompt(localTool, schema, s.uploadField)
+			prompt.Name = name
+			serverPrompts = append(serverPrompts, server.ServerPrompt{
+				Prompt:  prompt,
+				Handler: s.newPromptHandler(localTool, schema),
+			})
+			if s.promptOnly(localTool) {
+				continue
+			}
+		}
 
-		// Register the tool with the asgard-mcp-server
-		s.mcpServer.AddTool(mcpTool, handler)
+		// Stage the tool for registration
+		serverTools = append(serverTools, server.ServerTool{Tool: mcpTool, Handler: handler})
 	}
 
-	return nil
+	return serverTools, serverPrompts, nil
+}
+
+// mergedTags returns the manifest tags of the tool followed by any local additions, without duplicates
+func (s *Server) mergedTags(tool Tool) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, tool.Tags...), s.toolTags[tool.Name]...) {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// describeWithTags appends a tag line to the description so clients can group tools
+func describeWithTags(description string, tags []string) string {
+	if len(tags) == 0 {
+		return description
+	}
+	line := "Tags: " + strings.Join(tags, ", ")
+	if description == "" {
+		return line
+	}
+	return description + "\n\n" + line
+}
+
+// withRawData attaches the exact response bytes as an embedded JSON or text resource when enabled
+func (s *Server) withRawData(toolName string, result *mcp.CallToolResult, raw json.RawMessage) *mcp.CallToolResult {
+	if !s.includeRawData {
+		return result
+	}
+	mimeType := "application/json"
+	if !json.Valid(raw) {
+		mimeType = "text/plain"
+	}
+	result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		URI:      RawResponseURIPrefix + toolName,
+		MIMEType: mimeType,
+		Text:     string(raw),
+	}))
+	return result
+}
+
+// collapseSingleField returns the value of a single-key JSON object, reporting whether it collapsed
+func collapseSingleField(data json.RawMessage) (json.RawMessage, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || len(obj) != 1 {
+		return data, false
+	}
+	for _, value := range obj {
+		return value, true
+	}
+	return data, false
+}
+
+// formatToolResponse formats a JSON tool response as indented text for readability, returning other responses as is
+func formatToolResponse(responseJSON json.RawMessage) (string, error) {
+	// Pass plain text, CSV, Markdown, and other non-JSON bodies through verbatim
+	if !json.Valid(responseJSON) {
+		return string(responseJSON), nil
+	}
+
+	// Parse numbers as json.Number so large integers such as 64-bit IDs keep every digit
+	var responseObj interface{}
+	decoder := json.NewDecoder(bytes.NewReader(responseJSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&responseObj); err != nil {
+		return "", fmt.Errorf("failed to parse tool response: %w", err)
+	}
+
+	// Format the response as indented JSON
+	responseText, err := json.MarshalIndent(responseObj, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format tool response: %w", err)
+	}
+
+	return string(responseText), nil
 }
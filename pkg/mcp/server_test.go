@@ -0,0 +1,267 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// backendPlaceholder stands for the URL of the backend in tool declarations passed to newToolBackend
+const backendPlaceholder = "$BACKEND"
+
+// newToolBackend starts a backend serving a manifest of the given tool JSON objects at /manifest, with
+// backendPlaceholder replaced by the backend's URL, and answering every other request with reply
+func newToolBackend(t *testing.T, reply http.HandlerFunc, tools ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest" {
+			reply(w, r)
+			return
+		}
+		manifest := `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[` + strings.Join(tools, ",") + `]}}`
+		_, _ = w.Write([]byte(strings.ReplaceAll(manifest, backendPlaceholder, "http://"+r.Host)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newToolServer creates a server for the manifest of a backend started by newToolBackend
+func newToolServer(t *testing.T, backend *httptest.Server, opts ...ServerOption) *Server {
+	t.Helper()
+	s, err := NewServer(backend.URL+"/manifest", "test-key", append([]ServerOption{WithLogger(discardLogger)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFormatToolResponse(t *testing.T) {
+	tests := map[string]struct {
+		response string
+		want     string
+	}{
+		"object":     {response: `{"name":"alice","tags":["a"]}`, want: "{\n  \"name\": \"alice\",\n  \"tags\": [\n    \"a\"\n  ]\n}"},
+		"array":      {response: `[1,{"id":2}]`, want: "[\n  1,\n  {\n    \"id\": 2\n  }\n]"},
+		"string":     {response: `"done"`, want: `"done"`},
+		"plain text": {response: "Report ready: 3 rows", want: "Report ready: 3 rows"},
+		"csv":        {response: "id,name\n1,alice\n", want: "id,name\n1,alice\n"},
+		"markdown":   {response: "# Title\n\n- item", want: "# Title\n\n- item"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := formatToolResponse([]byte(tt.response))
+			if err != nil || got != tt.want {
+				t.Errorf("formatToolResponse(%q) = %q, %v, want %q", tt.response, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolResultsFormatResponseBodies(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"asgard object": {body: `{"isSuccess":true,"data":{"id":1}}`, want: "{\n  \"id\": 1\n}"},
+		"bare array":    {body: `[1,2]`, want: "[\n  1,\n  2\n]"},
+		"plain text":    {body: "not JSON at all", want: "not JSON at all"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, tt.body)
+			}, searchTool)
+			s := newToolServer(t, backend)
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if result.IsError || resultText(result) != tt.want {
+				t.Errorf("result = %q (error %v), want %q", resultText(result), result.IsError, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawResponsesKeepKeyOrder(t *testing.T) {
+	const data = `{"zeta":1,"alpha":{"y":2.50,"x":true}}`
+	tests := map[string]struct {
+		raw  bool
+		want string
+	}{
+		"raw":    {raw: true, want: data},
+		"pretty": {want: "{\n  \"alpha\": {\n    \"x\": true,\n    \"y\": 2.50\n  },\n  \"zeta\": 1\n}"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":`+data+`}`), searchTool)
+			s := newToolServer(t, backend, WithRawResponses(tt.raw))
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if result.IsError || resultText(result) != tt.want {
+				t.Errorf("result = %q, want %q", resultText(result), tt.want)
+			}
+		})
+	}
+}
+
+func TestLargeIntegersSurviveFormatting(t *testing.T) {
+	// 2^63-1 and a Snowflake ID, both beyond the 53 bits a float64 holds exactly
+	const data = `{"id":9223372036854775807,"items":[{"id":1541815603606036480}],"ratio":0.1}`
+	backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":`+data+`}`), searchTool)
+	s := newToolServer(t, backend)
+
+	text := resultText(callTool(t, s, "search", map[string]interface{}{}))
+	for _, want := range []string{`"id": 9223372036854775807`, `"id": 1541815603606036480`, `"ratio": 0.1`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result = %s, want it to contain %s", text, want)
+		}
+	}
+}
+
+func TestCollapseSingleField(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		collapse bool
+		want     string
+	}{
+		"single string key":  {data: `{"summary":"all good"}`, collapse: true, want: "all good"},
+		"single object key":  {data: `{"result":{"id":1}}`, collapse: true, want: "{\n  \"id\": 1\n}"},
+		"multiple keys":      {data: `{"id":1,"name":"a"}`, collapse: true, want: "{\n  \"id\": 1,\n  \"name\": \"a\"\n}"},
+		"tool not opted in":  {data: `{"summary":"all good"}`, want: "{\n  \"summary\": \"all good\"\n}"},
+		"array is untouched": {data: `["a"]`, collapse: true, want: "[\n  \"a\"\n]"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":`+tt.data+`}`), searchTool)
+			var opts []ServerOption
+			if tt.collapse {
+				opts = append(opts, WithCollapseSingleField("search"))
+			}
+			s := newToolServer(t, backend, opts...)
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if result.IsError || resultText(result) != tt.want {
+				t.Errorf("result = %q, want %q", resultText(result), tt.want)
+			}
+		})
+	}
+}
+
+func TestRawDataAccompaniesFormattedText(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		enabled  bool
+		wantText string
+		wantRaw  string
+		wantMIME string
+	}{
+		"json": {
+			body: `{"isSuccess":true,"data":{"b":1.50,"a":[1]}}`, enabled: true,
+			wantText: "{\n  \"a\": [\n    1\n  ],\n  \"b\": 1.50\n}", wantRaw: `{"b":1.50,"a":[1]}`, wantMIME: "application/json",
+		},
+		"plain text": {
+			body: "id,name\n1,alice\n", enabled: true,
+			wantText: "id,name\n1,alice\n", wantRaw: "id,name\n1,alice\n", wantMIME: "text/plain",
+		},
+		"disabled by default": {
+			body:     `{"isSuccess":true,"data":{"a":1}}`,
+			wantText: "{\n  \"a\": 1\n}",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, tt.body), searchTool)
+			var opts []ServerOption
+			if tt.enabled {
+				opts = append(opts, WithRawData(true))
+			}
+			s := newToolServer(t, backend, opts...)
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			if result.IsError || resultText(result) != tt.wantText {
+				t.Fatalf("result = %q, want %q", resultText(result), tt.wantText)
+			}
+			if !tt.enabled {
+				if len(result.Content) != 1 {
+					t.Errorf("result has %d contents, want only the text", len(result.Content))
+				}
+				return
+			}
+			if len(result.Content) != 2 {
+				t.Fatalf("result has %d contents, want the text and the raw data", len(result.Content))
+			}
+			embedded, ok := result.Content[1].(mcp.EmbeddedResource)
+			if !ok {
+				t.Fatalf("second content is %T, want an embedded resource", result.Content[1])
+			}
+			raw, ok := embedded.Resource.(mcp.TextResourceContents)
+			if !ok {
+				t.Fatalf("embedded resource is %T, want text contents", embedded.Resource)
+			}
+			if raw.URI != RawResponseURIPrefix+"search" || raw.MIMEType != tt.wantMIME || raw.Text != tt.wantRaw {
+				t.Errorf("raw data = %+v, want %q as %s", raw, tt.wantRaw, tt.wantMIME)
+			}
+		})
+	}
+}
+
+func TestPayloadsAreOnlyLoggedWhenDebugging(t *testing.T) {
+	tests := map[string]struct {
+		level        slog.Level
+		wantPayloads bool
+	}{
+		"info":  {level: slog.LevelInfo},
+		"debug": {level: slog.LevelDebug, wantPayloads: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":{"email":"alice@example.com"}}`), searchTool)
+			s := newToolServer(t, backend, WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: tt.level}))))
+
+			callTool(t, s, "search", map[string]interface{}{"ssn": "123-45-6789"})
+			if !strings.Contains(logs.String(), "Tool call result") {
+				t.Fatalf("logs = %q, want the call summarized", logs.String())
+			}
+			for _, payload := range []string{"123-45-6789", "alice@example.com"} {
+				if got := strings.Contains(logs.String(), payload); got != tt.wantPayloads {
+					t.Errorf("logs contain %q = %v, want %v:\n%s", payload, got, tt.wantPayloads, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServerVersionIsReported(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		want    string
+	}{
+		"build version": {version: "1.4.0", want: "1.4.0"},
+		"empty version": {version: "", want: DefaultVersion},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newToolServer(t, newToolBackend(t, nil, searchTool), WithVersion(tt.version))
+
+			request := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"}}}`
+			response, ok := s.mcpServer.HandleMessage(context.Background(), []byte(request)).(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatal("initialize did not return a result")
+			}
+			result, ok := response.Result.(mcp.InitializeResult)
+			if !ok {
+				t.Fatalf("initialize returned %T, want an initialize result", response.Result)
+			}
+			if result.ServerInfo.Version != tt.want {
+				t.Errorf("server version = %q, want %q", result.ServerInfo.Version, tt.want)
+			}
+		})
+	}
+}
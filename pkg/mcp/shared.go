@@ -1,23 +1,64 @@
 package mcp
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 const (
 	UploadedFilePathsFieldName = "_uploaded_file_paths"
 	FormDataKeyJSON            = "json"
 	FormDataKeyFile            = "file"
+
+	// SkippedUploadsFieldName lists the files left out of a best-effort upload in the JSON payload
+	SkippedUploadsFieldName = "_skipped_uploads"
+
+	// Fields of an inline entry in the uploaded file paths
+	UploadInlineFileName = "filename"
+	UploadInlineMimeType = "mime_type"
+	UploadInlineContent  = "content"
+
+	// RawResponseURIPrefix prefixes the URI of the embedded resource carrying a tool's raw response
+	RawResponseURIPrefix = "asgard://responses/"
 )
 
 var UploadedFilePathsSchema = map[string]interface{}{
-	"type":        "array",
-	"description": "List of file paths to be uploaded",
+	"type": "array",
+	"description": "List of files to be uploaded, each given as a local file path on the server's machine, an http(s) URL " +
+		"to fetch, or an object carrying the file inline as base64",
 	"items": map[string]interface{}{
-		"type":        "string",
-		"description": "Path to the uploaded file",
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type":        "string",
+				"description": "Local path or http(s) URL of the uploaded file",
+			},
+			map[string]interface{}{
+				"type":        "object",
+				"description": "Inline file content",
+				"properties": map[string]interface{}{
+					UploadInlineFileName: map[string]interface{}{
+						"type":        "string",
+						"description": "Name the file is uploaded under",
+					},
+					UploadInlineMimeType: map[string]interface{}{
+						"type":        "string",
+						"description": "MIME type of the file, detected from the content when omitted",
+					},
+					UploadInlineContent: map[string]interface{}{
+						"type":        "string",
+						"description": "Base64-encoded file content",
+					},
+				},
+				"required":             []interface{}{UploadInlineFileName, UploadInlineContent},
+				"additionalProperties": false,
+			},
+		},
 	},
 }
 
@@ -31,14 +72,98 @@ func DetectMime(filePath string) (string, error) {
 		_ = file.Close()
 	}(file)
 
-	// Read the first 512 bytes
-	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
+	mimeType, err := sniffMime(file)
 	if err != nil {
+		return "", err
+	}
+	return refineMime(mimeType, filePath), nil
+}
+
+// sniffMime detects the MIME type from the first 512 bytes of r, reading until the window is full or r ends
+func sniffMime(r io.Reader) (string, error) {
+	// A single Read may return fewer bytes than available, so fill the whole sniff window
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(r, buffer)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Detect the content type (MIME)
-	mimeType := http.DetectContentType(buffer)
+	mimeType := http.DetectContentType(buffer[:n])
 	return mimeType, nil
 }
+
+// refineMime replaces a generic sniffed MIME type with the type registered for the file's extension, as long as
+// the two agree on the kind of content, so a.csv becomes text/csv while binary data named .csv stays binary
+func refineMime(sniffed, filePath string) string {
+	byExtension := mimeTypeByExtension(filepath.Ext(filePath))
+	if byExtension == "" {
+		return sniffed
+	}
+	mediaType, _, _ := mime.ParseMediaType(byExtension)
+
+	switch sniffedType, _, _ := mime.ParseMediaType(sniffed); sniffedType {
+	case "", "application/octet-stream":
+		// Binary content keeps a generic type rather than claiming to be text
+		if !textualMimeType(byExtension) {
+			return byExtension
+		}
+	case "text/plain":
+		if textualMimeType(byExtension) {
+			return byExtension
+		}
+	case "text/xml", "application/xml":
+		if strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "/xml") {
+			return byExtension
+		}
+	case "application/zip":
+		// Office documents, EPUBs, and JARs are zip containers that sniffing cannot tell apart
+		if strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(mediaType, "application/vnd.oasis.opendocument.") ||
+			mediaType == "application/epub+zip" || mediaType == "application/java-archive" {
+			return byExtension
+		}
+	}
+	return sniffed
+}
+
+// extensionMimeTypes covers common upload formats that Go's builtin table lacks, so detection does not depend
+// on the host's mime.types files
+var extensionMimeTypes = map[string]string{
+	".csv":  "text/csv; charset=utf-8",
+	".tsv":  "text/tab-separated-values; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// mimeTypeByExtension returns the MIME type registered for ext, or "" if there is none
+func mimeTypeByExtension(ext string) string {
+	if mimeType, ok := extensionMimeTypes[strings.ToLower(ext)]; ok {
+		return mimeType
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// textualMimeType reports whether content of the given MIME type is text
+func textualMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/xml", mediaType == "application/x-ndjson",
+		mediaType == "application/javascript", mediaType == "application/csv", mediaType == "application/x-yaml",
+		mediaType == "application/yaml":
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// pngHeader is the signature content sniffing recognizes as PNG
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func TestSniffMimeFillsTheWindow(t *testing.T) {
+	html := "<!DOCTYPE html><html><body>" + strings.Repeat("x", 600) + "</body></html>"
+	tests := map[string]struct {
+		content []byte
+		want    string
+	}{
+		"short file":       {content: []byte("hello"), want: "text/plain; charset=utf-8"},
+		"exactly 512":      {content: append(append([]byte{}, pngHeader...), make([]byte, 512-len(pngHeader))...), want: "image/png"},
+		"longer than 512":  {content: []byte(html), want: "text/html; charset=utf-8"},
+		"empty file":       {content: nil, want: "text/plain; charset=utf-8"},
+		"binary, no magic": {content: []byte{0, 1, 2, 3}, want: "application/octet-stream"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			writeFile(t, path, string(tt.content))
+			got, err := DetectMime(path)
+			if err != nil || got != tt.want {
+				t.Errorf("DetectMime() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffMimeReadsPastShortReads(t *testing.T) {
+	// A single Read of this reader only returns "<", which sniffs as plain text
+	got, err := sniffMime(iotest.OneByteReader(strings.NewReader("<!DOCTYPE html><html></html>")))
+	if err != nil || got != "text/html; charset=utf-8" {
+		t.Errorf("sniffMime() = %q, %v, want text/html", got, err)
+	}
+
+	got, err = sniffMime(iotest.OneByteReader(bytes.NewReader(pngHeader)))
+	if err != nil || got != "image/png" {
+		t.Errorf("sniffMime() = %q, %v, want image/png", got, err)
+	}
+}
+
+func TestSniffMimeReportsReadErrors(t *testing.T) {
+	if _, err := sniffMime(iotest.ErrReader(iotest.ErrTimeout)); err == nil || !strings.Contains(err.Error(), "failed to read file") {
+		t.Errorf("sniffMime() error = %v, want the read error", err)
+	}
+}
+
+func TestDetectMimeRefinesByExtension(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		"csv":                  {name: "a.CSV", content: []byte("id,name\n1,alice\n"), want: "text/csv; charset=utf-8"},
+		"svg":                  {name: "logo.svg", content: []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`), want: "image/svg+xml"},
+		"binary named csv":     {name: "dump.csv", content: []byte{0, 1, 2, 3, 0xff}, want: "application/octet-stream"},
+		"png named txt":        {name: "notes.txt", content: append(append([]byte{}, pngHeader...), 0, 0, 0, 13), want: "image/png"},
+		"text named png":       {name: "image.png", content: []byte("just some text"), want: "text/plain; charset=utf-8"},
+		"unknown extension":    {name: "data.nosuchext", content: []byte("plain"), want: "text/plain; charset=utf-8"},
+		"zip named as an xlsx": {name: "sheet.xlsx", content: []byte("PK\x03\x04" + strings.Repeat("\x00", 26)), want: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			writeFile(t, path, string(tt.content))
+			got, err := DetectMime(path)
+			if err != nil || got != tt.want {
+				t.Errorf("DetectMime(%s) = %q, %v, want %q", tt.name, got, err, tt.want)
+			}
+		})
+	}
+}
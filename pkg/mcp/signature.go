@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ManifestSignatureHeader carries the base64-encoded Ed25519 signature of the manifest body
+const ManifestSignatureHeader = "X-Asgard-Signature"
+
+// CanonicalizeManifest returns the bytes a manifest signature covers: the JSON body without insignificant whitespace
+func CanonicalizeManifest(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, body); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyManifestSignature checks a base64-encoded Ed25519 signature over the canonical form of the manifest body
+func VerifyManifestSignature(body []byte, signature string, key ed25519.PublicKey) error {
+	if signature == "" {
+		return errors.New("manifest is not signed")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode manifest signature: %w", err)
+	}
+
+	canonical, err := CanonicalizeManifest(body)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(key, canonical, sig) {
+		return errors.New("manifest signature is invalid")
+	}
+	return nil
+}
+
+// LoadManifestPublicKey reads a PEM-encoded Ed25519 public key
+func LoadManifestPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("manifest public key %s is not PEM encoded", path)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest public key: %w", err)
+	}
+
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("manifest public key %s is not an Ed25519 key", path)
+	}
+	return key, nil
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// signedManifest is indented on purpose: signatures cover the compacted body
+const signedManifest = `{
+  "isSuccess": true,
+  "data": {"namespace": "ns", "name": "ts", "generation": 1, "tools": []}
+}`
+
+// newSignedManifestBackend starts a backend serving body with the given signature header
+func newSignedManifestBackend(t *testing.T, body, signature string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if signature != "" {
+			w.Header().Set(ManifestSignatureHeader, signature)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sign returns the base64-encoded signature of the canonical form of body
+func sign(t *testing.T, key ed25519.PrivateKey, body string) string {
+	t.Helper()
+	canonical, err := CanonicalizeManifest([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, canonical))
+}
+
+func TestManifestSignatureVerification(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(signedManifest, `"generation": 1`, `"generation": 2`, 1)
+
+	tests := map[string]struct {
+		body, signature string
+		wantErr         string
+	}{
+		"valid":         {body: signedManifest, signature: sign(t, priv, signedManifest)},
+		"unsigned":      {body: signedManifest, wantErr: "not signed"},
+		"malformed":     {body: signedManifest, signature: "not base64!", wantErr: "decode"},
+		"other key":     {body: signedManifest, signature: sign(t, otherPriv, signedManifest), wantErr: "invalid"},
+		"tampered body": {body: tampered, signature: sign(t, priv, signedManifest), wantErr: "invalid"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newSignedManifestBackend(t, tt.body, tt.signature)
+			c := newTestClient(backend.URL, WithManifestPublicKey(pub))
+
+			_, err := c.FetchToolsetManifest(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("FetchToolsetManifest() = %v, want the signed manifest accepted", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "refusing to load manifest") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FetchToolsetManifest() = %v, want it refused with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsignedManifestsAcceptedWithoutKey(t *testing.T) {
+	backend := newSignedManifestBackend(t, signedManifest, "")
+	if _, err := newTestClient(backend.URL).FetchToolsetManifest(context.Background()); err != nil {
+		t.Errorf("FetchToolsetManifest() = %v, want unsigned manifests accepted without a key", err)
+	}
+}
+
+func TestLoadManifestPublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "manifest.pub")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(dir, "manifest.raw")
+	if err := os.WriteFile(notPEM, der, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadManifestPublicKey(keyFile)
+	if err != nil || !key.Equal(pub) {
+		t.Errorf("LoadManifestPublicKey() = %v, %v, want the written key", key, err)
+	}
+	if _, err := LoadManifestPublicKey(notPEM); err == nil || !strings.Contains(err.Error(), "not PEM encoded") {
+		t.Errorf("LoadManifestPublicKey() of a raw key = %v, want a PEM error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// startupRetryPolicy is the backoff between startup attempts, a variable so tests can shorten it
+var startupRetryPolicy = RetryPolicy{BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// NewServerWithStartupRetry creates a server like NewServer, retrying the whole initialization with
+// exponential backoff for up to maxWait before returning the last error; zero maxWait fails fast. Canceling ctx
+// stops waiting for the next attempt and aborts the running one
+func NewServerWithStartupRetry(ctx context.Context, endpointURL, apiKey string, maxWait time.Duration, opts ...ServerOption) (*Server, error) {
+	return NewServerWithStartupAttempts(ctx, endpointURL, apiKey, maxWait, 0, opts...)
+}
+
+// NewServerWithStartupAttempts is NewServerWithStartupRetry with a bound on the number of attempts as well;
+// retrying stops at whichever of maxWait and maxAttempts is reached first, zero leaving that bound off. With
+// both zero the first failure is returned
+func NewServerWithStartupAttempts(ctx context.Context, endpointURL, apiKey string, maxWait time.Duration, maxAttempts int, opts ...ServerOption) (*Server, error) {
+	policy := startupRetryPolicy
+	var deadline time.Time
+	if maxWait > 0 {
+		deadline = time.Now().Add(maxWait)
+	}
+
+	// The server's logger does not exist yet, so startup is logged through the default logger
+	logger := slog.Default()
+	for attempt := 1; ; attempt++ {
+		s, err := NewServerWithContext(ctx, endpointURL, apiKey, opts...)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Initialization succeeded", "attempt", attempt)
+			}
+			return s, nil
+		}
+
+		if maxWait <= 0 && maxAttempts <= 0 {
+			return nil, err
+		}
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			logger.Error("Initialization failed, giving up", "attempt", attempt, "max_attempts", maxAttempts, "error", err)
+			return nil, err
+		}
+		delay := policy.backoff(attempt)
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				logger.Error("Initialization failed, giving up", "attempt", attempt, "max_wait", maxWait, "error", err)
+				return nil, err
+			}
+			delay = min(delay, remaining)
+		}
+		logger.Warn("Initialization failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("Initialization canceled, giving up", "attempt", attempt)
+			return nil, errors.Join(ctx.Err(), err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// quickStartupRetries shortens the backoff between startup attempts and silences the startup log for a test
+func quickStartupRetries(t *testing.T) {
+	t.Helper()
+	policy, logger := startupRetryPolicy, slog.Default()
+	startupRetryPolicy = RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	slog.SetDefault(discardLogger)
+	t.Cleanup(func() {
+		startupRetryPolicy = policy
+		slog.SetDefault(logger)
+	})
+}
+
+func TestStartupRetriesUntilTheBackendIsReady(t *testing.T) {
+	quickStartupRetries(t)
+	backend := newFlakyBackend(t, http.StatusServiceUnavailable, 2)
+
+	s, err := NewServerWithStartupAttempts(context.Background(), backend.URL, "test-key", 0, 5, WithLogger(discardLogger))
+	if err != nil {
+		t.Fatalf("NewServerWithStartupAttempts() = %v, want the third attempt to succeed", err)
+	}
+	if s == nil {
+		t.Fatal("NewServerWithStartupAttempts() returned no server")
+	}
+	if got := backend.attemptsOf(http.MethodGet); got != 3 {
+		t.Errorf("backend received %d manifest requests, want 3", got)
+	}
+}
+
+func TestStartupGivesUpAfterMaxAttempts(t *testing.T) {
+	quickStartupRetries(t)
+	backend := newFlakyBackend(t, http.StatusServiceUnavailable, 5)
+
+	_, err := NewServerWithStartupAttempts(context.Background(), backend.URL, "test-key", 0, 2, WithLogger(discardLogger))
+	if err == nil {
+		t.Fatal("NewServerWithStartupAttempts() succeeded, want the last error")
+	}
+	if got := backend.attemptsOf(http.MethodGet); got != 2 {
+		t.Errorf("backend received %d manifest requests, want 2", got)
+	}
+}
+
+func TestStartupFailsFastWithoutRetries(t *testing.T) {
+	quickStartupRetries(t)
+	backend := newFlakyBackend(t, http.StatusServiceUnavailable, 1)
+
+	if _, err := NewServerWithStartupAttempts(context.Background(), backend.URL, "test-key", 0, 0, WithLogger(discardLogger)); err == nil {
+		t.Fatal("NewServerWithStartupAttempts() succeeded, want the first error")
+	}
+	if got := backend.attemptsOf(http.MethodGet); got != 1 {
+		t.Errorf("backend received %d manifest requests, want 1", got)
+	}
+}
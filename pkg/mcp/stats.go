@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxLatencySamples bounds the latency samples kept per tool
+const maxLatencySamples = 10000
+
+// toolStats accumulates call outcomes for a single tool
+type toolStats struct {
+	calls     int
+	errors    int
+	latencies []time.Duration
+}
+
+// callStats is the single accounting path for tool call counts, errors, and latencies
+type callStats struct {
+	mu    sync.Mutex
+	tools map[string]*toolStats
+}
+
+// newCallStats creates empty call statistics
+func newCallStats() *callStats {
+	return &callStats{tools: make(map[string]*toolStats)}
+}
+
+// record accounts for a finished tool call
+func (c *callStats) record(tool string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ts, ok := c.tools[tool]
+	if !ok {
+		ts = &toolStats{}
+		c.tools[tool] = ts
+	}
+	ts.calls++
+	if err != nil {
+		ts.errors++
+	}
+	if len(ts.latencies) < maxLatencySamples {
+		ts.latencies = append(ts.latencies, duration)
+	} else {
+		ts.latencies[ts.calls%maxLatencySamples] = duration
+	}
+}
+
+// logSummary logs per-tool call counts, error counts, and latency percentiles
+func (c *callStats) logSummary(logger *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.tools))
+	for name := range c.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logger.Info("Tool calls this session", "tools", len(names))
+	for _, name := range names {
+		ts := c.tools[name]
+		sorted := append([]time.Duration(nil), ts.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		logger.Info("Tool call summary", "tool", name, "calls", ts.calls, "errors", ts.errors,
+			"p50_ms", percentile(sorted, 0.50).Milliseconds(), "p90_ms", percentile(sorted, 0.90).Milliseconds(),
+			"p99_ms", percentile(sorted, 0.99).Milliseconds(), "max_ms", percentile(sorted, 1).Milliseconds())
+	}
+}
+
+// recordCall accounts for a finished tool call in the statistics and the recent call log
+func (s *Server) recordCall(tool string, args, response []byte, err error, duration time.Duration) {
+	s.stats.record(tool, duration, err)
+	s.callLog.record(tool, args, response, err, duration)
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxEventSize bounds a single line of a streamed response
+const maxEventSize = 4 << 20
+
+// streamKey carries the handler receiving the events of streamed responses
+type streamKey struct{}
+
+// withStreamHandler returns a context whose streamed tool responses pass each event to handle as it arrives
+func withStreamHandler(ctx context.Context, handle func(data string)) context.Context {
+	return context.WithValue(ctx, streamKey{}, handle)
+}
+
+// streamHandler returns the stream handler of the context, or nil
+func streamHandler(ctx context.Context) func(data string) {
+	handle, _ := ctx.Value(streamKey{}).(func(string))
+	return handle
+}
+
+// isEventStream reports whether a content type is text/event-stream
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// withMaxStreamSize bounds the data of a streamed response kept for the tool result; zero keeps all of it
+func withMaxStreamSize(n int) APIClientOption {
+	return func(c *APIClient) {
+		c.maxStreamSize = n
+	}
+}
+
+// readEventStream reads a Server-Sent Events body, passing the data of each event to handle as soon as it is
+// complete, and returns the data of all events joined by newlines along with its size. Beyond limit bytes, when
+// positive, events are still passed to handle but only the start of the data is kept, cut so that it fits in limit
+// along with a marker of the omitted byte count
+func readEventStream(r io.Reader, handle func(data string), limit int) ([]byte, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var result strings.Builder
+	var size int
+	var data []string
+	keep := func(s string) {
+		size += len(s)
+		if limit > 0 && result.Len()+len(s) > limit {
+			s = s[:max(limit-result.Len(), 0)]
+		}
+		result.WriteString(s)
+	}
+	dispatch := func() {
+		if len(data) == 0 {
+			return
+		}
+		event := strings.Join(data, "\n")
+		data = data[:0]
+		if size > 0 {
+			keep("\n")
+		}
+		keep(event)
+		if handle != nil {
+			handle(event)
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			dispatch()
+		case strings.HasPrefix(line, ":"):
+			// Comment, often sent as a keep-alive
+		case line == "data":
+			data = append(data, "")
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Event names, IDs, and retry hints do not affect the result
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, size, fmt.Errorf("failed to read event stream: %w", err)
+	}
+
+	// A stream may end without a blank line after its last event
+	dispatch()
+	if limit <= 0 || size <= limit {
+		return []byte(result.String()), size, nil
+	}
+
+	// Make room for the marker, sized for the whole stream since fewer omitted bytes never need more digits
+	kept := result.String()
+	cut := max(limit-len(fmt.Sprintf(truncationMarker, size)), 0)
+	for cut > 0 && !utf8.RuneStart(kept[cut]) {
+		cut--
+	}
+	return []byte(kept[:cut] + fmt.Sprintf(truncationMarker, size-cut)), size, nil
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newEventStreamBackend starts a backend answering every tool call with the given events as a Server-Sent Events
+// stream, flushing each one separately
+func newEventStreamBackend(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		for i, event := range events {
+			_, _ = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", i, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// streamTool returns a tool invoked at the backend
+func streamTool(backend *httptest.Server) *Tool {
+	return &Tool{Name: "stream", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/stream"}}
+}
+
+// collectEvents returns a context whose stream handler records events, and a function returning them
+func collectEvents() (context.Context, func() []string) {
+	var mu sync.Mutex
+	var events []string
+	ctx := withStreamHandler(context.Background(), func(data string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, data)
+	})
+	return ctx, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(events)
+	}
+}
+
+func TestEventStreamForwardsEventsAndJoinsResult(t *testing.T) {
+	backend := newEventStreamBackend(t, "first", "second", "third")
+	c := newTestClient(backend.URL)
+	ctx, events := collectEvents()
+
+	data, err := c.ExecuteToolRequest(ctx, streamTool(backend), []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second", "third"}; !slices.Equal(events(), want) {
+		t.Errorf("stream handler received %q, want %q", events(), want)
+	}
+	if want := "first\nsecond\nthird"; string(data) != want {
+		t.Errorf("ExecuteToolRequest() = %q, want %q", data, want)
+	}
+}
+
+func TestEventStreamResultIsCapped(t *testing.T) {
+	const limit = 150
+	event := strings.Repeat("x", 100)
+	backend := newEventStreamBackend(t, event, event, event)
+	c := newTestClient(backend.URL, withMaxStreamSize(limit))
+	ctx, events := collectEvents()
+
+	data, err := c.ExecuteToolRequest(ctx, streamTool(backend), []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(events()); got != 3 {
+		t.Errorf("stream handler received %d events, want every event past the limit too", got)
+	}
+	if len(data) > limit {
+		t.Errorf("ExecuteToolRequest() returned %d bytes, want at most %d", len(data), limit)
+	}
+	full := strings.Join([]string{event, event, event}, "\n")
+	kept, marker, ok := strings.Cut(string(data), "…")
+	if !ok || !strings.HasPrefix(full, kept) {
+		t.Fatalf("ExecuteToolRequest() = %q, want the start of the stream followed by a truncation marker", data)
+	}
+	if want := fmt.Sprintf("[truncated, %d bytes omitted]", len(full)-len(kept)); marker != want {
+		t.Errorf("truncation marker = %q, want %q", marker, want)
+	}
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// BodyTemplateArgs is the string value replaced by the tool arguments in a request body template
+const BodyTemplateArgs = "<args>"
+
+// ParseBodyTemplate checks that a request body template is valid JSON
+func ParseBodyTemplate(template string) (json.RawMessage, error) {
+	if !json.Valid([]byte(template)) {
+		return nil, fmt.Errorf("body template is not valid JSON: %s", template)
+	}
+	return json.RawMessage(template), nil
+}
+
+// applyBodyTemplate wraps the arguments in the tool's request body template, if any
+func (c *APIClient) applyBodyTemplate(tool *Tool, input json.RawMessage) (json.RawMessage, error) {
+	template, ok := c.bodyTemplates[tool.Name]
+	if !ok {
+		return input, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(template, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse body template for tool %s: %w", tool.Name, err)
+	}
+	body, err := json.Marshal(substituteArgs(value, input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply body template for tool %s: %w", tool.Name, err)
+	}
+	return body, nil
+}
+
+// substituteArgs replaces every BodyTemplateArgs string in value with the arguments
+func substituteArgs(value interface{}, args json.RawMessage) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == BodyTemplateArgs {
+			return args
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = substituteArgs(item, args)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substituteArgs(item, args)
+		}
+	}
+	return value
+}
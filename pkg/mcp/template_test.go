@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBodyTemplatesWrapTheArguments(t *testing.T) {
+	var recorder bodyRecorder
+	backend := httptest.NewServer(http.HandlerFunc(recorder.reply))
+	t.Cleanup(backend.Close)
+	template, err := ParseBodyTemplate(`{"input":"<args>","options":{"lang":"en","inputs":["<args>","other"]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(backend.URL, WithBodyTemplates(map[string]json.RawMessage{"search": template}))
+
+	for _, name := range []string{"search", "plain"} {
+		tool := &Tool{Name: name, InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/" + name}}
+		if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{"query":"acme","limit":5}`)); err != nil {
+			t.Fatalf("ExecuteToolRequest(%s) error = %v", name, err)
+		}
+	}
+
+	bodies := recorder.received()
+	want := []string{
+		`{"input":{"limit":5,"query":"acme"},"options":{"inputs":[{"limit":5,"query":"acme"},"other"],"lang":"en"}}`,
+		`{"limit":5,"query":"acme"}`,
+	}
+	for i, body := range bodies {
+		got, _ := json.Marshal(body)
+		if string(got) != want[i] {
+			t.Errorf("backend received %s, want %s", got, want[i])
+		}
+	}
+	if len(bodies) != len(want) {
+		t.Errorf("backend received %d calls, want %d", len(bodies), len(want))
+	}
+}
+
+func TestParseBodyTemplateRejectsInvalidJSON(t *testing.T) {
+	if _, err := ParseBodyTemplate(`{"input": <args>}`); err == nil {
+		t.Error("ParseBodyTemplate() accepted a template that is not JSON")
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// DefaultTLSMinVersion is the lowest TLS version accepted from backends unless configured otherwise
+const DefaultTLSMinVersion = tls.VersionTLS12
+
+// tlsVersions maps configuration names to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion converts a version such as "1.2" to its crypto/tls constant
+func ParseTLSVersion(name string) (uint16, error) {
+	version, ok := tlsVersions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q, expected one of 1.0, 1.1, 1.2, 1.3", name)
+	}
+	return version, nil
+}
+
+// ParseCipherSuites converts IANA cipher suite names to their IDs, accepting only suites crypto/tls considers secure
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// LoadCABundle returns the system roots extended with the PEM certificates in path
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path) //nolint
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// LoadClientCertificate loads a PEM certificate and private key pair for mutual TLS
+func LoadClientCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// tlsConfig returns the transport's TLS config, creating it when unset
+func (c *APIClient) tlsConfig() *tls.Config {
+	if c.transport.TLSClientConfig == nil {
+		c.transport.TLSClientConfig = &tls.Config{MinVersion: DefaultTLSMinVersion} //nolint:gosec
+	}
+	return c.transport.TLSClientConfig
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTLS12Backend starts a TLS backend that speaks at most TLS 1.2, serving an empty manifest and counting the
+// requests that get through the handshake
+func newTLS12Backend(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	// The refused handshakes are expected, keep them out of the test output
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// trusting returns the option trusting the certificate of a TLS test server
+func trusting(srv *httptest.Server) APIClientOption {
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	return WithRootCAs(pool)
+}
+
+func TestTLSMinVersionRefusesOlderBackends(t *testing.T) {
+	backend, requests := newTLS12Backend(t)
+
+	c := newTestClient(backend.URL, trusting(backend), WithTLSMinVersion(tls.VersionTLS13))
+	_, err := c.FetchToolsetManifest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "protocol version") {
+		t.Fatalf("FetchToolsetManifest() error = %v, want a protocol version error", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("backend handled %d requests, want none below the TLS minimum", n)
+	}
+
+	c = newTestClient(backend.URL, trusting(backend), WithTLSMinVersion(tls.VersionTLS12))
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Errorf("FetchToolsetManifest() at the backend's version = %v", err)
+	}
+}
+
+func TestTLSMinVersionAppliesToURLUploads(t *testing.T) {
+	files, requests := newTLS12Backend(t)
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, trusting(files), WithTLSMinVersion(tls.VersionTLS13),
+		WithUploadURLAllowlist(hostOf(t, files)))
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, files.URL+"/a.txt"))
+	if err == nil || !strings.Contains(err.Error(), "protocol version") {
+		t.Fatalf("ExecuteToolRequest() error = %v, want a protocol version error", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("file server handled %d requests, want none below the TLS minimum", n)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	if v, err := ParseTLSVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf(`ParseTLSVersion("1.3") = %v, %v, want TLS 1.3`, v, err)
+	}
+	if _, err := ParseTLSVersion("1.4"); err == nil {
+		t.Error(`ParseTLSVersion("1.4") succeeded, want an error`)
+	}
+}
+
+func TestCABundleTrustsPrivateBackends(t *testing.T) {
+	backend, _ := newTLS12Backend(t)
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	writeFile(t, bundle, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})))
+
+	// The test server's certificate is signed by no system root
+	_, err := newTestClient(backend.URL).FetchToolsetManifest(context.Background())
+	var unknownAuthority x509.UnknownAuthorityError
+	if !errors.As(err, &unknownAuthority) {
+		t.Fatalf("FetchToolsetManifest() without the bundle = %v, want an unknown authority error", err)
+	}
+
+	pool, err := LoadCABundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestClient(backend.URL, WithRootCAs(pool)).FetchToolsetManifest(context.Background()); err != nil {
+		t.Errorf("FetchToolsetManifest() with the bundle = %v, want the backend trusted", err)
+	}
+}
+
+func TestLoadCABundleRejectsFilesWithoutCertificates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, path, "not a certificate\n")
+	if _, err := LoadCABundle(path); err == nil || !strings.Contains(err.Error(), "holds no PEM certificates") {
+		t.Errorf("LoadCABundle() = %v, want an error for a bundle without certificates", err)
+	}
+	if _, err := LoadCABundle(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("LoadCABundle() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// unsafeToolNameChars matches runs of characters some MCP clients reject in tool names
+var unsafeToolNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// SanitizeToolName replaces every run of characters outside [A-Za-z0-9_-] with an underscore, so that names with
+// spaces, dots, or slashes work with every client
+func SanitizeToolName(name string) string {
+	sanitized := unsafeToolNameChars.ReplaceAllString(name, "_")
+	if sanitized == "" {
+		return "tool"
+	}
+	return sanitized
+}
+
+// uniqueToolName returns name, or name with the first free numeric suffix when it is already taken
+func uniqueToolName(name string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
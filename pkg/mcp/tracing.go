@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/asgard-ai-platform/asgard-mcp-server/pkg/mcp"
+
+// Span attributes set on tool call spans
+const (
+	attrToolName     = attribute.Key("mcp.tool.name")
+	attrResponseSize = attribute.Key("mcp.tool.response.size")
+)
+
+// tracer returns the tracer of the globally registered provider, a no-op unless SetupTracing configured one
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// tracingConfigured reports whether the standard OTEL_* environment variables configure an OTLP trace exporter
+func tracingConfigured() bool {
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" || os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// SetupTracing registers an OTLP/HTTP trace exporter configured through the standard OTEL_* environment variables
+// and W3C trace context propagation; without an exporter endpoint tracing stays a no-op. The returned function
+// flushes pending spans and must be called before exiting
+func SetupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if !tracingConfigured() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// The default resource honors OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.Default()),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// failSpan records err on the span and marks the span as failed
+func failSpan(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// errBodyReadTimeout is returned when the backend stalls while sending a response body
+var errBodyReadTimeout = errors.New("response body read timed out")
+
+// DefaultMaxRedirects is the number of redirects followed when no limit is configured
+const DefaultMaxRedirects = 5
+
+// DefaultMaxBodySize bounds the manifest pages and buffered tool responses read from the backend
+const DefaultMaxBodySize = 64 << 20
+
+// DefaultRequestIDHeader is the response header the backend's request ID is read from by default
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// ProxyDirect is the proxy setting that connects directly, ignoring the proxy environment variables
+const ProxyDirect = "direct"
+
+// ParseProxy parses a proxy setting: an http, https, or socks5 URL, ProxyDirect, or empty to honor HTTP_PROXY,
+// HTTPS_PROXY, and NO_PROXY
+func ParseProxy(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, or socks5", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// requestID returns the backend's request ID from the configured response header
+func (c *APIClient) requestID(resp *http.Response) string {
+	if c.requestIDHeader == "" {
+		return ""
+	}
+	return resp.Header.Get(c.requestIDHeader)
+}
+
+// checkRedirect stops redirect chains longer than the configured maximum, naming the URL that was not followed
+func (c *APIClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.maxRedirects {
+		return fmt.Errorf("stopped after %d redirects, last redirect to %s", c.maxRedirects, req.URL.Redacted())
+	}
+	return nil
+}
+
+// do executes the request with the configured retry policy, retrying once with a rotated key or a fresh
+// OAuth2 token when unauthorized
+func (c *APIClient) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.oauth != nil {
+		return c.retryWithFreshToken(req, resp)
+	}
+	return c.retryUnauthorized(req, resp)
+}
+
+// requestTimeoutKey carries a timeout replacing the client timeout for the requests of a context
+type requestTimeoutKey struct{}
+
+// withRequestTimeout returns a context whose requests are each bounded by d instead of the client timeout
+func withRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, d)
+}
+
+// readBody reads a response body of at most c.maxBodySize bytes, failing instead of buffering a larger one
+func (c *APIClient) readBody(body io.Reader) ([]byte, error) {
+	if c.maxBodySize <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, c.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxBodySize {
+		return nil, fmt.Errorf("response body exceeds %d bytes", c.maxBodySize)
+	}
+	return data, nil
+}
+
+// doOnce executes a single attempt, enforcing the configured read deadline on the response body
+func (c *APIClient) doOnce(req *http.Request) (*http.Response, error) {
+	client := c.client
+	if d, ok := req.Context().Value(requestTimeoutKey{}).(time.Duration); ok {
+		override := *c.client
+		override.Timeout = d
+		client = &override
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = newDeadlineBody(resp.Body, c.bodyReadTimeout, cancel)
+	return resp, nil
+}
+
+// deadlineBody aborts the request when no bytes arrive on the body within the timeout
+type deadlineBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// newDeadlineBody wraps body so a stall longer than timeout cancels the request; zero disables the deadline
+func newDeadlineBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *deadlineBody {
+	b := &deadlineBody{
+		body:    body,
+		timeout: timeout,
+		cancel:  cancel,
+	}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, func() {
+			b.timedOut.Store(true)
+			cancel()
+		})
+	}
+	return b
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.timedOut.Load() {
+		return n, errBodyReadTimeout
+	}
+	if n > 0 && b.timer != nil {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *deadlineBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,389 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSizedBackend starts a backend answering the manifest and tool calls with a body padded to size bytes
+func newSizedBackend(t *testing.T, size int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		if r.Method == http.MethodGet {
+			body = `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]},"padding":"%s"}`
+		} else {
+			body = `{"isSuccess":true,"data":"%s"}`
+		}
+		padding := size - len(body) + len("%s")
+		_, _ = fmt.Fprintf(w, body, strings.Repeat("x", max(padding, 0)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResponseBodiesAreBounded(t *testing.T) {
+	const limit = 1024
+	tests := map[string]struct {
+		size    int
+		wantErr bool
+	}{
+		"below the limit":   {size: limit - 1},
+		"exactly the limit": {size: limit},
+		"over the limit":    {size: limit + 1, wantErr: true},
+		"far over":          {size: 10 * limit, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newSizedBackend(t, tt.size)
+			c := newTestClient(backend.URL, WithMaxBodySize(limit))
+
+			_, err := c.FetchToolsetManifest(context.Background())
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("FetchToolsetManifest() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "exceeds 1024 bytes")) {
+				t.Errorf("FetchToolsetManifest() error = %v, want the body limit", err)
+			}
+
+			tool := &Tool{Name: "big", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/big"}}
+			_, err = c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`))
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("ExecuteToolRequest() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "exceeds 1024 bytes")) {
+				t.Errorf("ExecuteToolRequest() error = %v, want the body limit", err)
+			}
+		})
+	}
+}
+
+func TestResponseBodiesAreUnboundedAtZero(t *testing.T) {
+	backend := newSizedBackend(t, 4096)
+	c := newTestClient(backend.URL, WithMaxBodySize(0))
+	tool := &Tool{Name: "big", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/big"}}
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+		t.Errorf("ExecuteToolRequest() error = %v, want no limit", err)
+	}
+}
+
+// newRedirectBackend starts a backend redirecting /hop/<n> to /hop/<n+1> until n reaches hops, then serving an empty
+// manifest, and counting the requests it receives; a negative hops redirects forever
+func newRedirectBackend(t *testing.T, hops int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var n int
+		_, _ = fmt.Sscanf(r.URL.Path, "/hop/%d", &n)
+		if hops < 0 || n < hops {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n+1), http.StatusFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestRedirectLoopStopsAtTheLimit(t *testing.T) {
+	const limit = 3
+	backend, requests := newRedirectBackend(t, -1)
+	c := newTestClient(backend.URL+"/hop/0", WithMaxRedirects(limit))
+
+	_, err := c.FetchToolsetManifest(context.Background())
+	if err == nil {
+		t.Fatal("FetchToolsetManifest() succeeded, want the redirect loop to fail")
+	}
+	want := fmt.Sprintf("stopped after %d redirects, last redirect to %s/hop/%d", limit, backend.URL, limit+1)
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("FetchToolsetManifest() error = %v, want %q", err, want)
+	}
+	if got := requests.Load(); got != limit+1 {
+		t.Errorf("backend received %d requests, want the first and %d redirects", got, limit)
+	}
+}
+
+func TestRedirectsWithinTheLimitAreFollowed(t *testing.T) {
+	backend, _ := newRedirectBackend(t, DefaultMaxRedirects)
+	c := newTestClient(backend.URL + "/hop/0")
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Errorf("FetchToolsetManifest() = %v, want %d redirects to be followed", err, DefaultMaxRedirects)
+	}
+}
+
+// newSlowBackend starts a backend that waits headerDelay before answering, then dribbles a tool response one byte
+// every interval, giving up as soon as the client goes away
+func newSlowBackend(t *testing.T, headerDelay, interval time.Duration, bytes int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-time.After(headerDelay):
+		case <-r.Context().Done():
+			return
+		}
+		flusher := w.(http.Flusher)
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"`)
+		flusher.Flush()
+		for range bytes {
+			select {
+			case <-time.After(interval):
+			case <-r.Context().Done():
+				return
+			}
+			_, _ = fmt.Fprint(w, "x")
+			flusher.Flush()
+		}
+		_, _ = fmt.Fprint(w, `"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStalledResponsesAreAborted(t *testing.T) {
+	tests := map[string]struct {
+		headerDelay time.Duration
+		interval    time.Duration
+		opts        []APIClientOption
+		wantErr     string
+	}{
+		"slow headers": {
+			headerDelay: 5 * time.Second,
+			opts:        []APIClientOption{WithResponseHeaderTimeout(50 * time.Millisecond)},
+			wantErr:     "timeout awaiting response headers",
+		},
+		"stalled body": {
+			interval: 5 * time.Second,
+			opts:     []APIClientOption{WithBodyReadTimeout(50 * time.Millisecond)},
+			wantErr:  errBodyReadTimeout.Error(),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newSlowBackend(t, tt.headerDelay, tt.interval, 3)
+			c := newTestClient(backend.URL, tt.opts...)
+			tool := &Tool{Name: "slow", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/slow"}}
+
+			start := time.Now()
+			_, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ExecuteToolRequest() error = %v, want %q", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("stalled response took %s to abort", elapsed)
+			}
+		})
+	}
+}
+
+func TestBodyReadTimeoutAllowsSteadyProgress(t *testing.T) {
+	// Every byte arrives well within the deadline, though the whole body takes longer than it
+	backend := newSlowBackend(t, 0, 20*time.Millisecond, 10)
+	c := newTestClient(backend.URL, WithBodyReadTimeout(100*time.Millisecond))
+	tool := &Tool{Name: "slow", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/slow"}}
+
+	data, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ExecuteToolRequest() error = %v, want the dribbled response", err)
+	}
+	if string(data) != `"xxxxxxxxxx"` {
+		t.Errorf("response = %s, want every dribbled byte", data)
+	}
+}
+
+func TestRequestIDsAppearInLogs(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		opts   []APIClientOption
+	}{
+		"default header": {header: DefaultRequestIDHeader},
+		"custom header":  {header: "X-Trace", opts: []APIClientOption{WithRequestIDHeader("X-Trace")}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Header().Set(tt.header, "req-manifest")
+					_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+					return
+				}
+				w.Header().Set(tt.header, "req-call")
+				_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+			}))
+			t.Cleanup(backend.Close)
+			var logs bytes.Buffer
+			c := newTestClient(backend.URL, append(tt.opts, WithClientLogger(slog.New(slog.NewTextHandler(&logs, nil))))...)
+
+			if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+			if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"request_id=req-manifest", "request_id=req-call"} {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("logs do not contain %q:\n%s", want, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRequestIDsReachToolResultMetadata(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprint(enabled), func(t *testing.T) {
+			reply := replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`, DefaultRequestIDHeader, "req-7")
+			backend := newToolBackend(t, reply, searchTool)
+			s := newToolServer(t, backend, WithRequestIDMeta(enabled))
+
+			result := callTool(t, s, "search", map[string]interface{}{})
+			var got interface{}
+			if result.Meta != nil {
+				got = result.Meta["requestId"]
+			}
+			if (got == "req-7") != enabled {
+				t.Errorf("result metadata requestId = %v, want it present %v", got, enabled)
+			}
+		})
+	}
+}
+
+// newForwardProxy starts an HTTP proxy answering every request itself with an empty manifest, recording the
+// absolute URLs it was asked to fetch
+func newForwardProxy(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var urls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		urls = append(urls, r.URL.String())
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[]}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), urls...)
+	}
+}
+
+func TestProxyCarriesBackendRequests(t *testing.T) {
+	proxy, proxied := newForwardProxy(t)
+
+	// The backend host does not resolve, so only a proxied request can succeed
+	c := newTestClient("http://backend.invalid/manifest", WithProxy(proxy.URL))
+	if _, err := c.FetchToolsetManifest(context.Background()); err != nil {
+		t.Fatalf("FetchToolsetManifest() through the proxy = %v", err)
+	}
+	if got := proxied(); len(got) != 1 || got[0] != "http://backend.invalid/manifest" {
+		t.Errorf("proxy received %v, want the manifest URL", got)
+	}
+
+	direct := newTestClient("http://backend.invalid/manifest", WithProxy(ProxyDirect))
+	if _, err := direct.FetchToolsetManifest(context.Background()); err == nil {
+		t.Error("FetchToolsetManifest() without a proxy succeeded, want the unresolvable host to fail")
+	}
+	if got := proxied(); len(got) != 1 {
+		t.Errorf("proxy received %d requests, want direct connections to bypass it", len(got))
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	tests := map[string]struct {
+		proxy     string
+		wantProxy string
+		wantErr   bool
+	}{
+		"environment": {proxy: ""},
+		"direct":      {proxy: ProxyDirect},
+		"http":        {proxy: "http://proxy.example:3128", wantProxy: "http://proxy.example:3128"},
+		"socks5":      {proxy: "socks5://proxy.example:1080", wantProxy: "socks5://proxy.example:1080"},
+		"no host":     {proxy: "http://", wantErr: true},
+		"bad scheme":  {proxy: "ftp://proxy.example", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			proxyFunc, err := ParseProxy(tt.proxy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProxy(%q) error = %v, want error %v", tt.proxy, err, tt.wantErr)
+			}
+			if tt.wantProxy == "" {
+				return
+			}
+			got, err := proxyFunc(httptest.NewRequest(http.MethodGet, "http://backend.example/manifest", nil))
+			if err != nil || got.String() != tt.wantProxy {
+				t.Errorf("proxy for a backend request = %v, %v, want %s", got, err, tt.wantProxy)
+			}
+		})
+	}
+}
+
+// newCountingBackend starts a backend answering tool calls after delay and counting the connections opened to it
+func newCountingBackend(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var opened atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		time.Sleep(delay)
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			opened.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv, &opened
+}
+
+func TestConnectionsAreReused(t *testing.T) {
+	tests := map[string]struct {
+		opts       []APIClientOption
+		wantReused bool
+	}{
+		"default pool":      {wantReused: true},
+		"one idle per host": {opts: []APIClientOption{WithConnectionPool(DefaultMaxIdleConns, 1, DefaultIdleConnTimeout)}},
+	}
+	const concurrency, rounds = 8, 3
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend, opened := newCountingBackend(t, 50*time.Millisecond)
+			c := newTestClient(backend.URL, tt.opts...)
+			tool := &Tool{Name: "search", InvokeEndpoints: ToolInvokeEndpoints{JSON: backend.URL + "/search"}}
+
+			for round := 0; round < rounds; round++ {
+				var wg sync.WaitGroup
+				for i := 0; i < concurrency; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, err := c.ExecuteToolRequest(context.Background(), tool, []byte(`{}`)); err != nil {
+							t.Errorf("ExecuteToolRequest() error = %v", err)
+						}
+					}()
+				}
+				wg.Wait()
+			}
+
+			// Each round runs every call at once, so a pool keeping all its connections never opens more
+			if got := opened.Load(); (got <= concurrency) != tt.wantReused {
+				t.Errorf("opened %d connections for %d rounds of %d concurrent calls, want reuse %v", got, rounds, concurrency, tt.wantReused)
+			}
+		})
+	}
+}
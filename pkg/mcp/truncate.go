@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Defaults for truncating large tool responses
+const (
+	// DefaultMaxResponseSize bounds the text of a tool result returned to the client
+	DefaultMaxResponseSize = 1 << 20
+	// DefaultMaxLogSize bounds the response text written to the logs
+	DefaultMaxLogSize = 4096
+)
+
+// truncationMarker ends truncated text, formatted with the number of bytes omitted
+const truncationMarker = "…[truncated, %d bytes omitted]"
+
+// WithResponseLimits truncates tool result text beyond maxResponse bytes and logged response text beyond maxLog
+// bytes, marking how much was cut; zero disables the respective limit
+func WithResponseLimits(maxResponse, maxLog int) ServerOption {
+	return func(s *Server) {
+		s.maxResponseSize = maxResponse
+		s.maxLogSize = maxLog
+	}
+}
+
+// truncateText cuts s to at most limit bytes on a UTF-8 boundary, appending a marker with the omitted byte count,
+// and reports whether it was cut; a non-positive limit keeps s whole
+func truncateText(s string, limit int) (string, bool) {
+	if limit <= 0 || len(s) <= limit {
+		return s, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf(truncationMarker, len(s)-cut), true
+}
+
+// truncateResponse bounds the text returned for a tool call, pointing to the full response when it is served as a
+// resource
+func (s *Server) truncateResponse(toolName, text string) string {
+	truncated, cut := truncateText(text, s.maxResponseSize)
+	if !cut {
+		return text
+	}
+	s.logger.Warn("Truncated tool response", "tool", toolName, "bytes", len(text), "limit", s.maxResponseSize)
+	if s.resourceArguments {
+		truncated += fmt.Sprintf("\n\nThe full response is available as the resource %s%s", RawResponseURIPrefix, toolName)
+	}
+	return truncated
+}
+
+// truncateLog bounds response text written to the logs
+func (s *Server) truncateLog(text string) string {
+	truncated, _ := truncateText(text, s.maxLogSize)
+	return truncated
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTruncateTextBoundary(t *testing.T) {
+	tests := map[string]struct {
+		text    string
+		limit   int
+		want    string
+		wantCut bool
+	}{
+		"shorter than the limit": {text: "abcd", limit: 5, want: "abcd"},
+		"exactly the limit":      {text: "abcde", limit: 5, want: "abcde"},
+		"one byte over":          {text: "abcdef", limit: 5, want: "abcde…[truncated, 1 bytes omitted]", wantCut: true},
+		"limit inside a rune":    {text: "abcdé", limit: 5, want: "abcd…[truncated, 2 bytes omitted]", wantCut: true},
+		"limit after a rune":     {text: "abcéf", limit: 5, want: "abcé…[truncated, 1 bytes omitted]", wantCut: true},
+		"zero disables":          {text: "abcdef", limit: 0, want: "abcdef"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, cut := truncateText(tt.text, tt.limit)
+			if got != tt.want || cut != tt.wantCut {
+				t.Errorf("truncateText(%q, %d) = %q, %v, want %q, %v", tt.text, tt.limit, got, cut, tt.want, tt.wantCut)
+			}
+		})
+	}
+}
+
+func TestToolResultsAreTruncatedAtTheLimit(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want string
+	}{
+		"at the limit":      {data: `"0123456789"`, want: `"0123456789"`},
+		"one byte over":     {data: `"0123456789a"`, want: `"0123456789a…[truncated, 1 bytes omitted]`},
+		"well over the cap": {data: `"0123456789abcdef"`, want: `"0123456789a…[truncated, 6 bytes omitted]`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":`+tt.data+`}`), searchTool)
+			s := newToolServer(t, backend, WithResponseLimits(12, 0))
+
+			result := callTool(t, s, "search", map[string]interface{}{"query": "q"})
+			if got := resultText(result); got != tt.want {
+				t.Errorf("result text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,580 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errUploadsDisabled is returned for calls passing files while uploads are disabled
+var errUploadsDisabled = errors.New("file uploads are disabled by safe mode")
+
+// uploadsBlocked reports whether safe mode blocks file uploads
+func (s *Server) uploadsBlocked() bool {
+	return s.noUploads != "" && s.noUploads != UploadSafeModeOff
+}
+
+// skipsUploadTool reports whether safe mode leaves the tool unregistered
+func (s *Server) skipsUploadTool(tool Tool) bool {
+	return tool.AllowUploadFiles && s.noUploads == UploadSafeModeSkip
+}
+
+// isUploadURL reports whether an upload entry is an http(s) URL rather than a local path
+func isUploadURL(entry string) bool {
+	u, err := url.Parse(entry)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// uploadEntry is one entry of the uploaded file paths: a local path, an http(s) URL, or inline content
+type uploadEntry struct {
+	// Path is the local path or URL, empty for inline content
+	Path string
+	// Name, MimeType, and Content describe inline content
+	Name     string
+	MimeType string
+	Content  []byte
+}
+
+// String returns the path or URL of the entry, or a description of inline content
+func (e uploadEntry) String() string {
+	if e.Path == "" {
+		return fmt.Sprintf("inline file %q", e.Name)
+	}
+	return e.Path
+}
+
+// local reports whether the entry is a file on the server's disk
+func (e uploadEntry) local() bool {
+	return e.Path != "" && !isUploadURL(e.Path)
+}
+
+// parseUploadEntries parses the uploaded file paths argument into upload entries
+func parseUploadEntries(value interface{}) ([]uploadEntry, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	entries := make([]uploadEntry, 0, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			entries = append(entries, uploadEntry{Path: v})
+		case map[string]interface{}:
+			name, _ := v[UploadInlineFileName].(string)
+			content, _ := v[UploadInlineContent].(string)
+			mimeType, _ := v[UploadInlineMimeType].(string)
+			if name == "" {
+				return nil, fmt.Errorf("inline file %d has no %s", i, UploadInlineFileName)
+			}
+			data, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode content of inline file %q: %w", name, err)
+			}
+			entries = append(entries, uploadEntry{Name: name, MimeType: mimeType, Content: data})
+		default:
+			return nil, fmt.Errorf("uploaded file %d must be a path, a URL, or an object with %s and %s",
+				i, UploadInlineFileName, UploadInlineContent)
+		}
+	}
+	return entries, nil
+}
+
+// stripInlineUploads replaces inline entries of the uploaded file paths in field with their file names so their
+// content is only sent once, as a file part; the input is returned unchanged without inline entries
+func stripInlineUploads(inputData map[string]interface{}, field string, entries []uploadEntry, input json.RawMessage) (json.RawMessage, error) {
+	inline := false
+	refs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		if e.Path == "" {
+			inline = true
+			refs[i] = e.Name
+		} else {
+			refs[i] = e.Path
+		}
+	}
+	if !inline {
+		return input, nil
+	}
+
+	inputData[field] = refs
+	stripped, err := json.Marshal(inputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	return stripped, nil
+}
+
+// uploadBaseName returns the file name an upload entry is sent under by default
+func uploadBaseName(entry uploadEntry) string {
+	switch {
+	case entry.Path == "":
+		// Inline names are client-chosen, so never let them carry directories
+		return path.Base(filepath.ToSlash(entry.Name))
+	case isUploadURL(entry.Path):
+		u, _ := url.Parse(entry.Path)
+		if name := path.Base(u.Path); name != "." && name != "/" {
+			return name
+		}
+		return "download"
+	}
+	return filepath.Base(entry.Path)
+}
+
+// openUpload opens an upload entry and detects its MIME type and size, which is -1 when unknown;
+// URLs are fetched without backend credentials under the upload URL policy
+func (c *APIClient) openUpload(ctx context.Context, entry uploadEntry) (io.ReadCloser, string, int64, error) {
+	if entry.Path == "" {
+		// Inline content declares its type or has it sniffed like a local file
+		mimeType := entry.MimeType
+		if mimeType == "" {
+			mimeType, _ = sniffMime(bytes.NewReader(entry.Content))
+			mimeType = refineMime(mimeType, entry.Name)
+		}
+		return io.NopCloser(bytes.NewReader(entry.Content)), mimeType, int64(len(entry.Content)), nil
+	}
+
+	if entry.local() {
+		path, err := c.checkUploadPath(entry.Path)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		f, err := os.Open(path) //nolint
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("failed to open file %s: %w", entry, err)
+		}
+
+		// Detect MIME type
+		mimeType, err := DetectMime(path)
+		if err != nil {
+			_ = f.Close()
+			return nil, "", 0, fmt.Errorf("failed to detect MIME type for file %s: %w", entry, err)
+		}
+
+		size := int64(-1)
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+		return f, mimeType, size, nil
+	}
+
+	resp, err := c.fetchUploadURL(ctx, entry)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	// Prefer the declared type and sniff the content otherwise
+	mimeType := resp.Header.Get("Content-Type")
+	body := bufio.NewReader(resp.Body)
+	if mimeType == "" {
+		head, _ := body.Peek(512)
+		mimeType = refineMime(http.DetectContentType(head), uploadBaseName(entry))
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}, mimeType, resp.ContentLength, nil
+}
+
+// absUploadPath returns the absolute path of a local upload, resolving relative paths against the upload base
+// directory when set and the working directory otherwise
+func (c *APIClient) absUploadPath(entry string) (string, error) {
+	path := entry
+	if c.uploadBaseDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(c.uploadBaseDir, path)
+	}
+	return filepath.Abs(path)
+}
+
+// checkUploadPath resolves a local upload path with absUploadPath and, when upload roots are configured, rejects
+// paths that escape every root through ".." or symlinks
+func (c *APIClient) checkUploadPath(entry string) (string, error) {
+	abs, err := c.absUploadPath(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve upload path %s: %w", entry, err)
+	}
+	if len(c.uploadRoots) == 0 {
+		return abs, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve upload path %s: %w", entry, err)
+	}
+	for _, root := range c.uploadRoots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("upload path %s resolves to %s, outside the allowed upload roots %s",
+		entry, resolved, strings.Join(c.uploadRoots, ", "))
+}
+
+// skippedUpload is a local upload left out of a best-effort upload, reported to the backend
+type skippedUpload struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// checkLocalUploads checks that every local upload can be opened, reporting all that cannot in one error before
+// any file is read
+func (c *APIClient) checkLocalUploads(entries []uploadEntry) error {
+	_, skipped := c.skipUnreadableUploads(entries)
+	if len(skipped) == 0 {
+		return nil
+	}
+	problems := make([]string, len(skipped))
+	for i, s := range skipped {
+		problems[i] = s.Error
+	}
+	return fmt.Errorf("cannot upload %d of %d files: %s", len(skipped), len(entries), strings.Join(problems, "; "))
+}
+
+// skipUnreadableUploads returns the uploads without the local files that cannot be opened, and why each of those
+// was left out
+func (c *APIClient) skipUnreadableUploads(entries []uploadEntry) ([]uploadEntry, []skippedUpload) {
+	readable := make([]uploadEntry, 0, len(entries))
+	var skipped []skippedUpload
+	for _, entry := range entries {
+		if !entry.local() {
+			readable = append(readable, entry)
+			continue
+		}
+		if problem := c.unreadableUpload(entry.Path); problem != "" {
+			skipped = append(skipped, skippedUpload{Path: entry.Path, Error: problem})
+			continue
+		}
+		readable = append(readable, entry)
+	}
+	return readable, skipped
+}
+
+// unreadableUpload describes why a local upload cannot be opened, or returns "" when it can
+func (c *APIClient) unreadableUpload(entry string) string {
+	path, err := c.checkUploadPath(entry)
+	if err != nil {
+		return err.Error()
+	}
+	f, err := os.Open(path) //nolint
+	if err != nil {
+		return uploadProblem(entry, err)
+	}
+	info, err := f.Stat()
+	_ = f.Close()
+	switch {
+	case err != nil:
+		return uploadProblem(entry, err)
+	case info.IsDir():
+		return fmt.Sprintf("%s is a directory", entry)
+	}
+	return ""
+}
+
+// uploadProblem describes why a local upload cannot be read
+func uploadProblem(path string, err error) string {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return fmt.Sprintf("%s does not exist", path)
+	case errors.Is(err, os.ErrPermission):
+		return fmt.Sprintf("%s is not readable", path)
+	}
+	return fmt.Sprintf("%s: %v", path, err)
+}
+
+// resolvePath returns the absolute path with symlinks resolved, or the cleaned absolute path when it cannot
+// be resolved
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// uploadPart is an opened upload ready to be streamed into a multipart body
+type uploadPart struct {
+	header   textproto.MIMEHeader
+	body     io.Reader
+	closer   io.Closer
+	compress bool
+}
+
+// streamMultipart opens every upload and returns a multipart body that writes the JSON payload and the files
+// as it is read; errors opening the files are returned before any byte is sent
+func (c *APIClient) streamMultipart(ctx context.Context, tool *Tool, boundary string, payload []byte, entries []uploadEntry) (io.ReadCloser, error) {
+	parts, err := c.openUploadParts(ctx, tool, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// The writer fails with the reader's error once the request is done with the body, and vice versa
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(writeMultipartBody(pw, boundary, payload, parts))
+	}()
+	return pr, nil
+}
+
+// openUploadParts opens the uploads in order, checking them against the upload limits
+func (c *APIClient) openUploadParts(ctx context.Context, tool *Tool, entries []uploadEntry) ([]uploadPart, error) {
+	fileNames := c.uploadFileNames(entries)
+	budget := &uploadBudget{maxFile: c.maxUploadFileSize, maxTotal: c.maxUploadTotalSize}
+	quoteEscaper := strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+	parts := make([]uploadPart, 0, len(entries))
+	for i, entry := range entries {
+		// Open the local file, fetch the URL, or read the inline content
+		rc, mimeType, size, err := c.openUpload(ctx, entry)
+		if err != nil {
+			closeUploadParts(parts)
+			return nil, err
+		}
+
+		// Enforce the size limits up front when the size is known and while streaming otherwise
+		f, err := budget.limit(entry.String(), rc, size)
+		if err != nil {
+			_ = rc.Close()
+			closeUploadParts(parts)
+			return nil, err
+		}
+
+		// Gzip large text files when the backend accepts it
+		compress := c.compressibleUpload(tool, mimeType, size)
+		fileName := fileNames[i]
+		if compress {
+			fileName += ".gz"
+		}
+
+		h := make(textproto.MIMEHeader)
+		fileName = quoteEscaper.Replace(fileName)
+		h.Set("Content-Disposition",
+			fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+				FormDataKeyFile, fileName))
+		h.Set("Content-Type", mimeType)
+		if compress {
+			h.Set("Content-Encoding", "gzip")
+		}
+		parts = append(parts, uploadPart{header: h, body: f, closer: rc, compress: compress})
+	}
+	return parts, nil
+}
+
+// writeMultipartBody writes the JSON payload field followed by the upload parts to w, closing the parts
+func writeMultipartBody(w io.Writer, boundary string, payload []byte, parts []uploadPart) error {
+	defer closeUploadParts(parts)
+
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return fmt.Errorf("failed to set multipart boundary: %w", err)
+	}
+
+	// 1) JSON payload field
+	if err := mw.WriteField(FormDataKeyJSON, string(payload)); err != nil {
+		return fmt.Errorf("failed to write JSON field: %w", err)
+	}
+
+	// 2) Files
+	for _, p := range parts {
+		part, err := mw.CreatePart(p.header)
+		if err != nil {
+			return fmt.Errorf("failed to create form file part: %w", err)
+		}
+		if p.compress {
+			zw := gzip.NewWriter(part)
+			if _, err := io.Copy(zw, p.body); err != nil {
+				return fmt.Errorf("failed to compress file into form: %w", err)
+			}
+			if err := zw.Close(); err != nil {
+				return fmt.Errorf("failed to compress file into form: %w", err)
+			}
+		} else if _, err := io.Copy(part, p.body); err != nil {
+			return fmt.Errorf("failed to copy file into form: %w", err)
+		}
+	}
+
+	// 3) finalize
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return nil
+}
+
+// closeUploadParts closes every opened upload
+func closeUploadParts(parts []uploadPart) {
+	for _, p := range parts {
+		_ = p.closer.Close()
+	}
+}
+
+// uploadBudget tracks the upload limits of a single request
+type uploadBudget struct {
+	maxFile  int64
+	maxTotal int64
+	// declared sums the known sizes of the files opened so far, streamed the bytes actually read
+	declared int64
+	streamed int64
+}
+
+// limit checks an upload of the given size, -1 when unknown, against the limits and returns a reader failing
+// once the file or the request as a whole exceeds them
+func (b *uploadBudget) limit(entry string, r io.Reader, size int64) (io.Reader, error) {
+	if b.maxFile <= 0 && b.maxTotal <= 0 {
+		return r, nil
+	}
+	if size >= 0 {
+		if err := b.check(entry, size, b.declared+size); err != nil {
+			return nil, err
+		}
+		b.declared += size
+	}
+	return &uploadLimitReader{r: r, entry: entry, budget: b}, nil
+}
+
+// check reports an error when fileSize or totalSize exceed their limits
+func (b *uploadBudget) check(entry string, fileSize, totalSize int64) error {
+	if b.maxFile > 0 && fileSize > b.maxFile {
+		return fmt.Errorf("file %s exceeds the upload limit of %d bytes per file", entry, b.maxFile)
+	}
+	if b.maxTotal > 0 && totalSize > b.maxTotal {
+		return fmt.Errorf("file %s exceeds the upload limit of %d bytes per request", entry, b.maxTotal)
+	}
+	return nil
+}
+
+// uploadLimitReader fails reads once its file or the request exceeds the upload limits
+type uploadLimitReader struct {
+	r      io.Reader
+	entry  string
+	read   int64
+	budget *uploadBudget
+}
+
+func (u *uploadLimitReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+	u.budget.streamed += int64(n)
+	if limitErr := u.budget.check(u.entry, u.read, u.budget.streamed); limitErr != nil {
+		return n, limitErr
+	}
+	return n, err
+}
+
+// compressibleUpload reports whether a file part of the given MIME type and size is worth gzip-encoding
+func (c *APIClient) compressibleUpload(tool *Tool, mimeType string, size int64) bool {
+	if c.gzipUploadsMin <= 0 || !tool.AcceptGzipUploads || size < c.gzipUploadsMin {
+		return false
+	}
+
+	return textualMimeType(mimeType)
+}
+
+// uploadFileNames returns the multipart file name for each entry, disambiguating duplicate base names per the
+// client's mode
+func (c *APIClient) uploadFileNames(entries []uploadEntry) []string {
+	names := make([]string, len(entries))
+	counts := make(map[string]int, len(entries))
+	for i, e := range entries {
+		names[i] = uploadBaseName(e)
+		counts[names[i]]++
+	}
+
+	// Nothing to do without a collision
+	collision := false
+	for name, n := range counts {
+		if n > 1 {
+			collision = true
+			c.logger.Warn("Uploaded files share a file name", "name", name, "files", n)
+		}
+	}
+	if !collision {
+		return names
+	}
+
+	switch c.duplicateFileNames {
+	case DuplicateFileNameIndex:
+		// Suffixed names skip any name already in use, such as an uploaded a-2.txt next to two a.txt
+		taken := make(map[string]bool, len(names))
+		for _, name := range names {
+			taken[name] = true
+		}
+		seen := make(map[string]int, len(entries))
+		for i, name := range names {
+			if counts[name] < 2 {
+				continue
+			}
+			seen[name]++
+			if seen[name] == 1 {
+				continue
+			}
+			ext := filepath.Ext(name)
+			for n := seen[name]; ; n++ {
+				candidate := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+				if !taken[candidate] {
+					names[i] = candidate
+					taken[candidate] = true
+					seen[name] = n
+					break
+				}
+			}
+		}
+	case DuplicateFileNamePath:
+		// Resolve relative paths the way they are opened, against the upload base directory
+		abs := make([]string, len(entries))
+		var local []string
+		for i, e := range entries {
+			if !e.local() {
+				continue
+			}
+			if a, err := c.absUploadPath(e.Path); err == nil {
+				abs[i] = a
+				local = append(local, a)
+			}
+		}
+		base := commonDir(local)
+		for i := range entries {
+			if counts[names[i]] < 2 || abs[i] == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(base, abs[i]); err == nil {
+				names[i] = filepath.ToSlash(rel)
+			}
+		}
+	default:
+		c.logger.Warn("Sending duplicate file names unchanged, the backend may overwrite files")
+	}
+
+	return names
+}
+
+// commonDir returns the deepest directory containing every absolute path
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	dir := filepath.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for !strings.HasPrefix(p, dir+string(filepath.Separator)) && dir != filepath.Dir(dir) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,663 @@
+package mcp
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// discardLogger drops every record, keeping test output readable
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestClient creates a client for a backend at baseURL that logs nothing
+func newTestClient(baseURL string, opts ...APIClientOption) *APIClient {
+	return NewAPIClientWithOptions(baseURL, "test-key", append([]APIClientOption{WithClientLogger(discardLogger)}, opts...)...)
+}
+
+// writeFile creates a file with the given content and any missing parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadFileNamesIndexSkipsTakenNames(t *testing.T) {
+	entries := []uploadEntry{{Path: "a.txt"}, {Path: "dir/a.txt"}, {Path: "a-2.txt"}, {Path: "other/a.txt"}}
+	c := newTestClient("http://backend.invalid", WithDuplicateFileNames(DuplicateFileNameIndex))
+
+	got := c.uploadFileNames(entries)
+	want := []string{"a.txt", "a-3.txt", "a-2.txt", "a-4.txt"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("uploadFileNames() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckUploadPathResolvesRelativePathsAgainstBaseDir(t *testing.T) {
+	base := t.TempDir()
+	writeFile(t, filepath.Join(base, "docs", "a.txt"), "a")
+	c := newTestClient("http://backend.invalid", WithUploadBaseDir(base))
+
+	got, err := c.checkUploadPath(filepath.Join("docs", "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(base, "docs", "a.txt"); got != want {
+		t.Errorf("checkUploadPath() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckUploadPathKeepsAbsolutePaths(t *testing.T) {
+	elsewhere := filepath.Join(t.TempDir(), "b.txt")
+	writeFile(t, elsewhere, "b")
+	c := newTestClient("http://backend.invalid", WithUploadBaseDir(t.TempDir()))
+
+	got, err := c.checkUploadPath(elsewhere)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != elsewhere {
+		t.Errorf("checkUploadPath() = %q, want %q", got, elsewhere)
+	}
+}
+
+func TestUploadFileNamesPathModeUsesBaseDir(t *testing.T) {
+	base := t.TempDir()
+	entries := []uploadEntry{{Path: filepath.Join("x", "a.txt")}, {Path: filepath.Join(base, "y", "a.txt")}}
+	c := newTestClient("http://backend.invalid", WithUploadBaseDir(base), WithDuplicateFileNames(DuplicateFileNamePath))
+
+	got := c.uploadFileNames(entries)
+	if want := []string{"x/a.txt", "y/a.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("uploadFileNames() = %v, want %v", got, want)
+	}
+}
+
+// uploadRootFixture creates an upload root holding inside.txt next to a directory outside it holding secret.txt,
+// returning the root and the outside directory
+func uploadRootFixture(t *testing.T) (root, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "root")
+	outside = filepath.Join(dir, "outside")
+	writeFile(t, filepath.Join(root, "inside.txt"), "inside")
+	writeFile(t, filepath.Join(outside, "secret.txt"), "secret")
+	return root, outside
+}
+
+// symlink creates a symlink or skips the test where symlinks are unavailable
+func symlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+}
+
+func TestCheckUploadPathAllowsPathsInsideRoots(t *testing.T) {
+	root, _ := uploadRootFixture(t)
+	c := newTestClient("http://backend.invalid", WithUploadRoots(root))
+
+	for _, entry := range []string{filepath.Join(root, "inside.txt"), filepath.Join(root, "sub", "..", "inside.txt")} {
+		if _, err := c.checkUploadPath(entry); err != nil {
+			t.Errorf("checkUploadPath(%q) = %v, want no error", entry, err)
+		}
+	}
+}
+
+func TestCheckUploadPathRejectsTraversal(t *testing.T) {
+	root, _ := uploadRootFixture(t)
+	c := newTestClient("http://backend.invalid", WithUploadRoots(root), WithUploadBaseDir(root))
+
+	for _, entry := range []string{
+		filepath.Join(root, "..", "outside", "secret.txt"),
+		filepath.Join("..", "outside", "secret.txt"),
+		filepath.Join("sub", "..", "..", "outside", "secret.txt"),
+	} {
+		if _, err := c.checkUploadPath(entry); err == nil {
+			t.Errorf("checkUploadPath(%q) succeeded, want an error for a path outside the roots", entry)
+		}
+	}
+}
+
+func TestCheckUploadPathRejectsSymlinkEscapes(t *testing.T) {
+	root, outside := uploadRootFixture(t)
+	symlink(t, filepath.Join(outside, "secret.txt"), filepath.Join(root, "file-link.txt"))
+	symlink(t, outside, filepath.Join(root, "dir-link"))
+	c := newTestClient("http://backend.invalid", WithUploadRoots(root))
+
+	for _, entry := range []string{filepath.Join(root, "file-link.txt"), filepath.Join(root, "dir-link", "secret.txt")} {
+		if _, err := c.checkUploadPath(entry); err == nil {
+			t.Errorf("checkUploadPath(%q) succeeded, want an error for a symlink leaving the roots", entry)
+		}
+	}
+}
+
+func TestUploadOutsideRootsNeverReachesBackend(t *testing.T) {
+	root, _ := uploadRootFixture(t)
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithUploadRoots(root))
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(),
+		uploadArguments(t, filepath.Join(root, "inside.txt"), filepath.Join(root, "..", "outside", "secret.txt")))
+	if err == nil || !strings.Contains(err.Error(), "outside the allowed upload roots") {
+		t.Fatalf("ExecuteToolRequest() error = %v, want an upload roots error", err)
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+}
+
+func TestUploadBudgetRejectsDeclaredSizes(t *testing.T) {
+	b := &uploadBudget{maxFile: 10, maxTotal: 15}
+
+	if _, err := b.limit("a.txt", strings.NewReader(""), 11); err == nil || !strings.Contains(err.Error(), "per file") {
+		t.Errorf("limit() of a file over the per-file limit = %v, want a per-file error", err)
+	}
+	if _, err := b.limit("b.txt", strings.NewReader(""), 10); err != nil {
+		t.Fatalf("limit() of a file within the limits = %v", err)
+	}
+	if _, err := b.limit("c.txt", strings.NewReader(""), 6); err == nil || !strings.Contains(err.Error(), "per request") {
+		t.Errorf("limit() of a file exceeding the request total = %v, want a per-request error", err)
+	}
+}
+
+func TestUploadBudgetStopsStreamsOfUnknownSize(t *testing.T) {
+	tests := map[string]struct {
+		budget  uploadBudget
+		sizes   []int
+		message string
+	}{
+		"per file":    {budget: uploadBudget{maxFile: 10}, sizes: []int{11}, message: "per file"},
+		"per request": {budget: uploadBudget{maxFile: 10, maxTotal: 15}, sizes: []int{8, 8}, message: "per request"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			for i, size := range tt.sizes {
+				var r io.Reader
+				r, err = tt.budget.limit(fmt.Sprintf("file-%d", i), strings.NewReader(strings.Repeat("x", size)), -1)
+				if err != nil {
+					t.Fatalf("limit() of a file of unknown size = %v, want the check deferred to reading", err)
+				}
+				if _, err = io.ReadAll(r); err != nil {
+					break
+				}
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("reading past the limit = %v, want a %s error", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestUploadLimitsStopCallsBeforeTheBackend(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.txt"), "0123456789")
+	writeFile(t, filepath.Join(dir, "b.txt"), "0123456789")
+	backend := newUploadBackend(t)
+	tests := map[string]APIClientOption{
+		"per file":    WithUploadLimits(5, 0),
+		"per request": WithUploadLimits(10, 15),
+	}
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(backend.URL, opt)
+			_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(),
+				uploadArguments(t, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")))
+			if err == nil || !strings.Contains(err.Error(), "upload limit") {
+				t.Fatalf("ExecuteToolRequest() error = %v, want an upload limit error", err)
+			}
+		})
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+
+	c := newTestClient(backend.URL, WithUploadLimits(10, 20))
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(),
+		uploadArguments(t, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"))); err != nil {
+		t.Errorf("ExecuteToolRequest() within the limits = %v", err)
+	}
+}
+
+func TestStreamMultipartWritesPayloadThenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "notes.txt"), strings.Repeat("note ", 100))
+	writeFile(t, filepath.Join(dir, "data.bin"), "\x00\x01\x02")
+	tool := &Tool{Name: "ingest", AllowUploadFiles: true, AcceptGzipUploads: true}
+	c := newTestClient("http://backend.invalid", WithGzipUploads(100))
+
+	const boundary = "test-boundary"
+	body, err := c.streamMultipart(context.Background(), tool, boundary, []byte(`{"query":"q"}`),
+		[]uploadEntry{{Path: filepath.Join(dir, "notes.txt")}, {Path: filepath.Join(dir, "data.bin")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = body.Close() }()
+
+	type part struct{ name, fileName, encoding, data string }
+	var got []part
+	mr := multipart.NewReader(body, boundary)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r io.Reader = p
+		if p.Header.Get("Content-Encoding") == "gzip" {
+			if r, err = gzip.NewReader(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, part{p.FormName(), p.FileName(), p.Header.Get("Content-Encoding"), string(data)})
+	}
+	want := []part{
+		{FormDataKeyJSON, "", "", `{"query":"q"}`},
+		{FormDataKeyFile, "notes.txt.gz", "gzip", strings.Repeat("note ", 100)},
+		{FormDataKeyFile, "data.bin", "", "\x00\x01\x02"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("multipart body = %q, want %q", got, want)
+	}
+}
+
+func TestStreamMultipartFailsBeforeSendingOnUnreadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.txt"), "a")
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL)
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(),
+		uploadArguments(t, filepath.Join(dir, "a.txt"), filepath.Join(dir, "missing.txt")))
+	if err == nil {
+		t.Fatal("ExecuteToolRequest() succeeded, want an error for the missing file")
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+}
+
+func TestStreamedUploadsAreChunkedAndRebuiltOnRetry(t *testing.T) {
+	content := strings.Repeat("0123456789abcdef", 64<<10)
+	path := filepath.Join(t.TempDir(), "large.txt")
+	writeFile(t, path, content)
+
+	var mu sync.Mutex
+	var attempts int
+	var lengths []int64
+	var received []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var file string
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(p)
+			if p.FormName() == FormDataKeyFile {
+				file = string(data)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		lengths = append(lengths, r.ContentLength)
+		received = append(received, file)
+		if attempts == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(backend.Close)
+	c := newTestClient(backend.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	tool := &Tool{Name: "ingest", AllowUploadFiles: true, Retryable: true, InvokeEndpoints: ToolInvokeEndpoints{Form: backend.URL}}
+
+	if _, err := c.ExecuteToolRequest(context.Background(), tool, uploadArguments(t, path)); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatalf("backend received %d attempts, want 2", attempts)
+	}
+	for i := range received {
+		if lengths[i] != -1 {
+			t.Errorf("attempt %d has Content-Length %d, want a chunked body streamed as it is written", i+1, lengths[i])
+		}
+		if received[i] != content {
+			t.Errorf("attempt %d received %d bytes of the file, want all %d", i+1, len(received[i]), len(content))
+		}
+	}
+}
+
+func TestSafeModeBlocksUploads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.txt")
+	writeFile(t, path, "secret")
+
+	tests := map[string]struct {
+		mode       UploadSafeMode
+		wantIngest bool
+		wantField  bool
+	}{
+		"off":    {mode: UploadSafeModeOff, wantIngest: true, wantField: true},
+		"skip":   {mode: UploadSafeModeSkip},
+		"reject": {mode: UploadSafeModeReject, wantIngest: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls []string
+			backend := newToolBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls = append(calls, r.URL.Path)
+				mu.Unlock()
+				_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+			}, ingestTool, searchTool)
+			s := newToolServer(t, backend, WithNoUploads(tt.mode), WithUploadPathsSchema(true))
+
+			var ingest *mcp.Tool
+			tools := listToolDefinitions(t, s)
+			for i := range tools {
+				if tools[i].Name == "ingest" {
+					ingest = &tools[i]
+				}
+			}
+			if (ingest != nil) != tt.wantIngest {
+				t.Fatalf("ingest listed = %v, want %v", ingest != nil, tt.wantIngest)
+			}
+			if !slices.Contains(listTools(t, s), "search") {
+				t.Error("search is not listed, want tools without uploads to stay registered")
+			}
+			if ingest == nil {
+				return
+			}
+			if got := strings.Contains(string(ingest.RawInputSchema), UploadedFilePathsFieldName); got != tt.wantField {
+				t.Errorf("ingest schema %s has %s = %v, want %v", ingest.RawInputSchema, UploadedFilePathsFieldName, got, tt.wantField)
+			}
+
+			result := callTool(t, s, "ingest", map[string]interface{}{UploadedFilePathsFieldName: []interface{}{path}})
+			if tt.mode == UploadSafeModeReject {
+				if !result.IsError || !strings.Contains(resultText(result), "disabled by safe mode") {
+					t.Errorf("result = %q, want the upload rejected by safe mode", resultText(result))
+				}
+				mu.Lock()
+				if len(calls) != 0 {
+					t.Errorf("backend received %v, want no call passing files", calls)
+				}
+				mu.Unlock()
+
+				// Calls without files still go through
+				if result := callTool(t, s, "ingest", map[string]interface{}{"note": "n"}); result.IsError {
+					t.Errorf("result = %q, want a call without files to succeed", resultText(result))
+				}
+			} else if result.IsError {
+				t.Errorf("result = %q, want the upload to succeed", resultText(result))
+			}
+		})
+	}
+}
+
+func TestGzipUploadsCompressOnlyLargeTextParts(t *testing.T) {
+	const minSize = 100
+	dir := t.TempDir()
+	files := map[string]string{
+		"large.csv": strings.Repeat("a,b\n", 50),
+		"exact.csv": strings.Repeat("x", minSize),
+		"small.csv": "a,b\n1,2\n",
+		"large.png": string(pngHeader) + strings.Repeat("\x00", 200),
+	}
+	var entries []uploadEntry
+	for _, name := range []string{"large.csv", "exact.csv", "small.csv", "large.png"} {
+		writeFile(t, filepath.Join(dir, name), files[name])
+		entries = append(entries, uploadEntry{Path: filepath.Join(dir, name)})
+	}
+
+	tests := map[string]struct {
+		acceptGzip bool
+		want       map[string]string
+	}{
+		"tool accepts gzip": {acceptGzip: true, want: map[string]string{
+			"large.csv.gz": "gzip", "exact.csv.gz": "gzip", "small.csv": "", "large.png": "",
+		}},
+		"tool does not": {want: map[string]string{
+			"large.csv": "", "exact.csv": "", "small.csv": "", "large.png": "",
+		}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tool := &Tool{Name: "ingest", AllowUploadFiles: true, AcceptGzipUploads: tt.acceptGzip}
+			c := newTestClient("http://backend.invalid", WithGzipUploads(minSize))
+			const boundary = "test-boundary"
+			body, err := c.streamMultipart(context.Background(), tool, boundary, []byte(`{}`), entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = body.Close() }()
+
+			got := make(map[string]string)
+			mr := multipart.NewReader(body, boundary)
+			for {
+				p, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.FormName() != FormDataKeyFile {
+					continue
+				}
+				encoding := p.Header.Get("Content-Encoding")
+				got[p.FileName()] = encoding
+
+				// Compressed parts still carry the whole file
+				var r io.Reader = p
+				if encoding == "gzip" {
+					if r, err = gzip.NewReader(p); err != nil {
+						t.Fatal(err)
+					}
+				}
+				data, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if original := strings.TrimSuffix(p.FileName(), ".gz"); string(data) != files[original] {
+					t.Errorf("part %s carries %d bytes, want the %d of %s", p.FileName(), len(data), len(files[original]), original)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("part encodings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingUploadsAreAllReportedUpFront(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.txt")
+	writeFile(t, valid, "ok")
+	missing := []string{filepath.Join(dir, "first-missing.txt"), filepath.Join(dir, "second-missing.txt")}
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL)
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, missing[0], valid, missing[1]))
+	if err == nil {
+		t.Fatal("ExecuteToolRequest() succeeded, want the missing files reported")
+	}
+	want := fmt.Sprintf("cannot upload 2 of 3 files: %s does not exist; %s does not exist", missing[0], missing[1])
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("ExecuteToolRequest() error = %v, want %q", err, want)
+	}
+	if strings.Contains(err.Error(), valid) {
+		t.Errorf("ExecuteToolRequest() error = %v, want the readable file left out", err)
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+}
+
+func TestBestEffortUploadsSkipUnreadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.txt")
+	writeFile(t, valid, "kept")
+	missing := filepath.Join(dir, "missing.txt")
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithBestEffortUploads(true))
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, valid, missing)); err != nil {
+		t.Fatalf("ExecuteToolRequest() = %v, want the readable file uploaded", err)
+	}
+	calls, parts := backend.received()
+	if calls != 1 || len(parts) != 1 || parts[0].FileName != "valid.txt" || parts[0].Data != "kept" {
+		t.Fatalf("backend received %d calls with parts %+v, want one call with valid.txt", calls, parts)
+	}
+
+	backend.mu.Lock()
+	payload := backend.payloads[0]
+	backend.mu.Unlock()
+	var got struct {
+		Query   string          `json:"query"`
+		Skipped []skippedUpload `json:"_skipped_uploads"`
+	}
+	if err := json.Unmarshal([]byte(payload), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []skippedUpload{{Path: missing, Error: missing + " does not exist"}}
+	if got.Query != "q" || fmt.Sprint(got.Skipped) != fmt.Sprint(want) {
+		t.Errorf("payload = %s, want the query and %s listing %+v", payload, SkippedUploadsFieldName, want)
+	}
+}
+
+func TestBestEffortUploadsReportNothingWhenAllFilesAreRead(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.txt")
+	writeFile(t, valid, "kept")
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithBestEffortUploads(true))
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, valid)); err != nil {
+		t.Fatal(err)
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if strings.Contains(backend.payloads[0], SkippedUploadsFieldName) {
+		t.Errorf("payload = %s, want no %s field", backend.payloads[0], SkippedUploadsFieldName)
+	}
+}
+
+// ingestTool declares an upload tool with its own schema for newToolBackend
+const ingestTool = `{"name":"ingest","description":"Ingest","allow_upload_files":true,"invoke_endpoints":{"form":"$BACKEND/ingest"},` +
+	`"input_schema":{"type":"object","properties":{"note":{"type":"string"}}}}`
+
+func TestUploadPathsSchemaCanBeDisabled(t *testing.T) {
+	tests := map[string]struct {
+		opts      []ServerOption
+		wantField bool
+	}{
+		"enabled by default": {wantField: true},
+		"enabled":            {opts: []ServerOption{WithUploadPathsSchema(true)}, wantField: true},
+		"disabled":           {opts: []ServerOption{WithUploadPathsSchema(false)}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newToolBackend(t, replyWith(http.StatusOK, `{"isSuccess":true,"data":"ok"}`), ingestTool)
+			s := newToolServer(t, backend, tt.opts...)
+
+			tools := listToolDefinitions(t, s)
+			if len(tools) != 1 {
+				t.Fatalf("tools/list returned %d tools, want ingest only", len(tools))
+			}
+			var schema struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			if err := json.Unmarshal(tools[0].RawInputSchema, &schema); err != nil {
+				t.Fatal(err)
+			}
+			if _, got := schema.Properties[UploadedFilePathsFieldName]; got != tt.wantField {
+				t.Errorf("ingest schema %s has %s = %v, want %v", tools[0].RawInputSchema, UploadedFilePathsFieldName, got, tt.wantField)
+			}
+			if _, ok := schema.Properties["note"]; !ok {
+				t.Errorf("ingest schema %s lost the backend's note property", tools[0].RawInputSchema)
+			}
+		})
+	}
+}
+
+func TestCustomUploadPathsFieldEndToEnd(t *testing.T) {
+	uploads := newUploadBackend(t)
+	ingest := `{"name":"ingest","description":"Ingest","allow_upload_files":true,"invoke_endpoints":{"form":"` + uploads.URL + `"},` +
+		`"input_schema":{"type":"object","properties":{"_uploaded_file_paths":{"type":"string"}}}}`
+	s := newToolServer(t, newToolBackend(t, nil, ingest), WithUploadPathsField("attachments"))
+	path := filepath.Join(t.TempDir(), "report.txt")
+	writeFile(t, path, "quarterly numbers")
+
+	tools := listToolDefinitions(t, s)
+	if len(tools) != 1 {
+		t.Fatalf("tools/list returned %d tools, want ingest only", len(tools))
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(tools[0].RawInputSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Properties["attachments"].Type != "array" || schema.Properties[UploadedFilePathsFieldName].Type != "string" {
+		t.Errorf("ingest schema = %s, want attachments added beside the toolset's own %s", tools[0].RawInputSchema, UploadedFilePathsFieldName)
+	}
+
+	result := callTool(t, s, "ingest", map[string]interface{}{"attachments": []interface{}{path}, UploadedFilePathsFieldName: "kept"})
+	if result.IsError {
+		t.Fatalf("tools/call ingest failed: %s", resultText(result))
+	}
+	calls, parts := uploads.received()
+	if calls != 1 || len(parts) != 1 || parts[0].FileName != "report.txt" || parts[0].Data != "quarterly numbers" {
+		t.Errorf("backend received %d calls with files %+v, want report.txt uploaded from attachments", calls, parts)
+	}
+	uploads.mu.Lock()
+	defer uploads.mu.Unlock()
+	want, err := json.Marshal(map[string]interface{}{UploadedFilePathsFieldName: "kept", "attachments": []string{path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads.payloads) != 1 || uploads.payloads[0] != string(want) {
+		t.Errorf("backend received payloads %q, want %s with the toolset's own field untouched", uploads.payloads, want)
+	}
+}
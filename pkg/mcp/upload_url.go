@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// errUploadURLRefused is returned for URL uploads the upload URL policy does not allow
+var errUploadURLRefused = errors.New("upload URL refused")
+
+// WithUploadURLAllowlist restricts URL uploads to the given hosts, such as "files.example.com", or URL prefixes,
+// such as "https://files.example.com/public/". Without an allowlist any URL is fetched as long as it resolves to
+// a public address; URLs matching the allowlist may also point at loopback, link-local, and private addresses
+func WithUploadURLAllowlist(entries ...string) APIClientOption {
+	return func(c *APIClient) {
+		c.uploadURLAllowlist = append(c.uploadURLAllowlist, entries...)
+	}
+}
+
+// ValidateUploadURLAllowlistEntry checks that an allowlist entry is a host name or an absolute http(s) URL
+func ValidateUploadURLAllowlistEntry(entry string) error {
+	if !strings.Contains(entry, "://") {
+		if entry == "" || strings.ContainsAny(entry, "/?#@") {
+			return fmt.Errorf("expected a host name or an http or https URL prefix, got %q", entry)
+		}
+		return nil
+	}
+	u, err := url.Parse(entry)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected a host name or an http or https URL prefix, got %q", entry)
+	}
+	return nil
+}
+
+// allowedUploadURL reports whether u matches an entry of the allowlist: a host name matched exactly, or a URL
+// prefix whose scheme and host match exactly and whose path is a prefix of the URL's path. Paths with ".."
+// segments never match a prefix, since the server may resolve them outside it
+func allowedUploadURL(allowlist []string, u *url.URL) bool {
+	dotDot := slices.Contains(strings.Split(u.Path, "/"), "..")
+	for _, entry := range allowlist {
+		if !strings.Contains(entry, "://") {
+			if strings.EqualFold(u.Hostname(), entry) {
+				return true
+			}
+			continue
+		}
+		prefix, err := url.Parse(entry)
+		if err != nil {
+			continue
+		}
+		if !dotDot && strings.EqualFold(prefix.Scheme, u.Scheme) && strings.EqualFold(prefix.Host, u.Host) &&
+			strings.HasPrefix(u.EscapedPath(), prefix.EscapedPath()) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUploadURL checks an upload URL, or the target of one of its redirects, against the upload URL policy;
+// the addresses it resolves to are checked when connecting
+func (c *APIClient) checkUploadURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %s is not an http or https URL", errUploadURLRefused, u.Redacted())
+	}
+	if len(c.uploadURLAllowlist) > 0 && !allowedUploadURL(c.uploadURLAllowlist, u) {
+		return fmt.Errorf("%w: %s is not in the upload URL allowlist", errUploadURLRefused, u.Redacted())
+	}
+	return nil
+}
+
+// checkURLUploads checks every URL upload against the upload URL policy before any file is read
+func (c *APIClient) checkURLUploads(entries []uploadEntry) error {
+	for _, entry := range entries {
+		if entry.Path == "" || entry.local() {
+			continue
+		}
+		u, err := url.Parse(entry.Path)
+		if err != nil {
+			return fmt.Errorf("invalid upload URL %s: %w", entry, err)
+		}
+		if err := c.checkUploadURL(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publicAddress reports whether ip is neither loopback, link-local, private, multicast, nor unspecified, which
+// keeps URL uploads away from cloud metadata services and hosts inside the server's network
+func publicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// refusePrivateAddresses is a dialer control function failing connections to addresses that are not public
+func refusePrivateAddresses(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+		return fmt.Errorf("%w: %s is not a public address; add the host to the upload URL allowlist to fetch it",
+			errUploadURLRefused, host)
+	}
+	return nil
+}
+
+// newUploadURLClient creates the client fetching URL uploads. It shares the backend client's TLS settings and
+// timeouts but sends no credentials, connects directly so the address check sees the real destination, and
+// checks every redirect against the upload URL policy
+func (c *APIClient) newUploadURLClient() *http.Client {
+	dialer := *c.dialer
+	if len(c.uploadURLAllowlist) == 0 {
+		dialer.Control = refusePrivateAddresses
+	}
+	transport := c.transport.Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   c.client.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if err := c.checkRedirect(req, via); err != nil {
+				return err
+			}
+			return c.checkUploadURL(req.URL)
+		},
+	}
+}
+
+// fetchUploadURL fetches a URL upload through the upload URL client, enforcing the body read timeout
+func (c *APIClient) fetchUploadURL(ctx context.Context, entry uploadEntry) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", entry.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", entry, err)
+	}
+	if err := c.checkUploadURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	resp, err := c.uploadURLClient.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to fetch %s: %w", entry, err)
+	}
+	resp.Body = newDeadlineBody(resp.Body, c.bodyReadTimeout, cancel)
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status code: %d", entry, resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,315 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordedPart is a file part received by an upload backend
+type recordedPart struct {
+	FileName string
+	Data     string
+}
+
+// uploadBackend is a fake Asgard backend recording the multipart requests it receives
+type uploadBackend struct {
+	*httptest.Server
+	mu       sync.Mutex
+	calls    int
+	payloads []string
+	parts    []recordedPart
+}
+
+// newUploadBackend starts a backend answering every multipart tool call with a successful response
+func newUploadBackend(t *testing.T) *uploadBackend {
+	t.Helper()
+	b := &uploadBackend{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.calls++
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(p)
+			if p.FormName() == FormDataKeyJSON {
+				b.payloads = append(b.payloads, string(data))
+				continue
+			}
+			b.parts = append(b.parts, recordedPart{FileName: p.FileName(), Data: string(data)})
+		}
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// received returns the number of calls and the file parts received so far
+func (b *uploadBackend) received() (int, []recordedPart) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls, append([]recordedPart(nil), b.parts...)
+}
+
+// uploadTool returns a tool uploading files to the backend
+func (b *uploadBackend) uploadTool() *Tool {
+	return &Tool{Name: "ingest", AllowUploadFiles: true, InvokeEndpoints: ToolInvokeEndpoints{Form: b.URL}}
+}
+
+// uploadArguments returns tool arguments uploading the given entries
+func uploadArguments(t *testing.T, entries ...string) json.RawMessage {
+	t.Helper()
+	input, err := json.Marshal(map[string]interface{}{"query": "q", UploadedFilePathsFieldName: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
+// newFileServer serves fixed file contents by path, redirecting /redirect to the location query parameter
+func newFileServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// hostOf returns the host name of a test server
+func hostOf(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Hostname()
+}
+
+func TestURLOnlyUpload(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/reports/q1.csv": "a,b\n1,2\n"})
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithUploadURLAllowlist(hostOf(t, files)))
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, files.URL+"/reports/q1.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, parts := backend.received()
+	want := []recordedPart{{FileName: "q1.csv", Data: "a,b\n1,2\n"}}
+	if fmt.Sprint(parts) != fmt.Sprint(want) {
+		t.Errorf("backend received %v, want %v", parts, want)
+	}
+}
+
+func TestMixedLocalAndURLUpload(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/remote.txt": "remote"})
+	backend := newUploadBackend(t)
+	local := filepath.Join(t.TempDir(), "local.txt")
+	writeFile(t, local, "local")
+	c := newTestClient(backend.URL, WithUploadURLAllowlist(files.URL+"/"))
+
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, local, files.URL+"/remote.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, parts := backend.received()
+	want := []recordedPart{{FileName: "local.txt", Data: "local"}, {FileName: "remote.txt", Data: "remote"}}
+	if fmt.Sprint(parts) != fmt.Sprint(want) {
+		t.Errorf("backend received %v, want %v", parts, want)
+	}
+}
+
+func TestURLUploadRefusesLoopbackByDefault(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/secret": "metadata"})
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL)
+
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, files.URL+"/secret"))
+	if !errors.Is(err, errUploadURLRefused) {
+		t.Fatalf("ExecuteToolRequest() error = %v, want %v", err, errUploadURLRefused)
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+}
+
+func TestURLUploadRefusesURLsOutsideAllowlist(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/public/a.txt": "a", "/private/b.txt": "b"})
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithUploadURLAllowlist(files.URL+"/public/"))
+
+	tests := map[string]string{
+		"outside prefix":   files.URL + "/private/b.txt",
+		"other host":       "http://files.invalid/public/a.txt",
+		"redirect outside": files.URL + "/public/../redirect?to=" + url.QueryEscape(files.URL+"/private/b.txt"),
+	}
+	for name, entry := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, entry))
+			if !errors.Is(err, errUploadURLRefused) {
+				t.Fatalf("ExecuteToolRequest() error = %v, want %v", err, errUploadURLRefused)
+			}
+		})
+	}
+	if calls, _ := backend.received(); calls != 0 {
+		t.Errorf("backend received %d calls, want none", calls)
+	}
+}
+
+func TestURLUploadChecksRedirects(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/public/a.txt": "a", "/private/b.txt": "b"})
+	backend := newUploadBackend(t)
+	c := newTestClient(backend.URL, WithUploadURLAllowlist(files.URL+"/public/", files.URL+"/redirect"))
+
+	entry := files.URL + "/redirect?to=" + url.QueryEscape(files.URL+"/private/b.txt")
+	_, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, entry))
+	if !errors.Is(err, errUploadURLRefused) {
+		t.Fatalf("ExecuteToolRequest() error = %v, want %v", err, errUploadURLRefused)
+	}
+
+	entry = files.URL + "/redirect?to=" + url.QueryEscape(files.URL+"/public/a.txt")
+	if _, err := c.ExecuteToolRequest(context.Background(), backend.uploadTool(), uploadArguments(t, entry)); err != nil {
+		t.Fatalf("ExecuteToolRequest() following an allowed redirect: %v", err)
+	}
+}
+
+func TestUploadEntryVariants(t *testing.T) {
+	files := newFileServer(t, map[string]string{"/remote.txt": "remote"})
+	local := filepath.Join(t.TempDir(), "local.txt")
+	writeFile(t, local, "local")
+	inline := func(fields ...string) map[string]interface{} {
+		entry := make(map[string]interface{})
+		for i := 0; i+1 < len(fields); i += 2 {
+			entry[fields[i]] = fields[i+1]
+		}
+		return entry
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte("id,name\n1,alice\n"))
+
+	tests := map[string]struct {
+		entry       interface{}
+		wantPart    recordedPart
+		wantPayload interface{}
+		wantErr     string
+	}{
+		"local path": {entry: local, wantPart: recordedPart{FileName: "local.txt", Data: "local"}, wantPayload: local},
+		"url": {
+			entry:       files.URL + "/remote.txt",
+			wantPart:    recordedPart{FileName: "remote.txt", Data: "remote"},
+			wantPayload: files.URL + "/remote.txt",
+		},
+		"inline base64": {
+			entry:       inline(UploadInlineFileName, "rows.csv", UploadInlineContent, encoded, UploadInlineMimeType, "text/csv"),
+			wantPart:    recordedPart{FileName: "rows.csv", Data: "id,name\n1,alice\n"},
+			wantPayload: "rows.csv",
+		},
+		"inline name stays a base name": {
+			entry:       inline(UploadInlineFileName, "../../etc/rows.csv", UploadInlineContent, encoded),
+			wantPart:    recordedPart{FileName: "rows.csv", Data: "id,name\n1,alice\n"},
+			wantPayload: "../../etc/rows.csv",
+		},
+		"inline without a name": {entry: inline(UploadInlineContent, encoded), wantErr: "has no filename"},
+		"inline with bad base64": {
+			entry:   inline(UploadInlineFileName, "rows.csv", UploadInlineContent, "not base64!"),
+			wantErr: `failed to decode content of inline file "rows.csv"`,
+		},
+		"neither": {entry: 42, wantErr: "must be a path, a URL, or an object"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := newUploadBackend(t)
+			c := newTestClient(backend.URL, WithUploadURLAllowlist(files.URL+"/"))
+			input, err := json.Marshal(map[string]interface{}{"query": "q", UploadedFilePathsFieldName: []interface{}{tt.entry}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.ExecuteToolRequest(context.Background(), backend.uploadTool(), input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ExecuteToolRequest() error = %v, want %q", err, tt.wantErr)
+				}
+				if calls, _ := backend.received(); calls != 0 {
+					t.Errorf("backend received %d calls, want none", calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, parts := backend.received()
+			if len(parts) != 1 || parts[0] != tt.wantPart {
+				t.Errorf("backend received %v, want %v", parts, tt.wantPart)
+			}
+			// Inline content travels once, as the file part, with only its name left in the payload
+			var payload map[string]interface{}
+			backend.mu.Lock()
+			err = json.Unmarshal([]byte(backend.payloads[0]), &payload)
+			backend.mu.Unlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(payload[UploadedFilePathsFieldName]); got != fmt.Sprint([]interface{}{tt.wantPayload}) {
+				t.Errorf("payload lists %s, want [%v]", got, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestInlineUploadMimeTypes(t *testing.T) {
+	c := newTestClient("http://backend.invalid")
+	tests := map[string]struct {
+		entry uploadEntry
+		want  string
+	}{
+		"declared":           {entry: uploadEntry{Name: "data.bin", MimeType: "application/x-custom", Content: []byte("x")}, want: "application/x-custom"},
+		"sniffed by content": {entry: uploadEntry{Name: "image", Content: pngHeader}, want: "image/png"},
+		"refined by name":    {entry: uploadEntry{Name: "rows.csv", Content: []byte("a,b\n")}, want: "text/csv"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, mimeType, size, err := c.openUpload(context.Background(), tt.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = body.Close()
+			if !strings.HasPrefix(mimeType, tt.want) || size != int64(len(tt.entry.Content)) {
+				t.Errorf("openUpload() = %q, %d bytes, want %q, %d bytes", mimeType, size, tt.want, len(tt.entry.Content))
+			}
+		})
+	}
+}
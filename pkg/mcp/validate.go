@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// compileArgumentSchema compiles the input schema a tool is registered with for validating arguments, returning
+// nil when the tool declares no schema or the schema cannot be compiled
+func (s *Server) compileArgumentSchema(toolName string, schema []byte) *jsonschema.Schema {
+	if len(schema) == 0 || string(schema) == "null" {
+		return nil
+	}
+
+	const url = "schema.json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(schema)); err != nil {
+		s.logger.Warn("Not validating arguments, the input schema cannot be loaded", "tool", toolName, "error", err)
+		return nil
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		s.logger.Warn("Not validating arguments, the input schema does not compile", "tool", toolName, "error", err)
+		return nil
+	}
+	return compiled
+}
+
+// validateArguments checks the arguments against a compiled schema, listing every failure in the returned error
+func validateArguments(schema *jsonschema.Schema, args map[string]interface{}) error {
+	if schema == nil {
+		return nil
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	err := schema.Validate(args)
+	var validationErr *jsonschema.ValidationError
+	if err == nil || !errors.As(err, &validationErr) {
+		return err
+	}
+
+	// Report the innermost failures, which name the offending argument
+	var failures []string
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := strings.TrimPrefix(e.InstanceLocation, "/")
+			if location == "" {
+				failures = append(failures, e.Message)
+			} else {
+				failures = append(failures, fmt.Sprintf("%s: %s", location, e.Message))
+			}
+			return
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(validationErr)
+	sort.Strings(failures)
+	return fmt.Errorf("schema validation failed: %s", strings.Join(failures, "; "))
+}
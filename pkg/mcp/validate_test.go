@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callTool sends a tools/call request through the server's MCP handler and returns the result
+func callTool(t *testing.T, s *Server, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, ok := s.mcpServer.HandleMessage(context.Background(), request).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tools/call %s did not return a result", name)
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("tools/call %s returned %T, want a tool result", name, response.Result)
+	}
+	return &result
+}
+
+// resultText returns the text of the first content item of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	if len(result.Content) == 0 {
+		return ""
+	}
+	text, _ := result.Content[0].(mcp.TextContent)
+	return text.Text
+}
+
+func TestArgumentValidation(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/manifest" {
+			_, _ = fmt.Fprintf(w, `{"isSuccess":true,"data":{"namespace":"ns","name":"ts","generation":1,"tools":[`+
+				`{"name":"search","description":"Search","invoke_endpoints":{"json":"http://%s/search"},"input_schema":`+
+				`{"type":"object","properties":{"query":{"type":"string","minLength":1},"limit":{"type":"integer","maximum":50}},"required":["query"]}}]}}`,
+				r.Host)
+			return
+		}
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"isSuccess":true,"data":"ok"}`)
+	}))
+	t.Cleanup(backend.Close)
+
+	tests := map[string]struct {
+		validate bool
+		args     map[string]interface{}
+		failures []string
+	}{
+		"valid":            {validate: true, args: map[string]interface{}{"query": "q", "limit": 10}},
+		"missing required": {validate: true, args: map[string]interface{}{"limit": 10}, failures: []string{"query"}},
+		"every failure":    {validate: true, args: map[string]interface{}{"query": "", "limit": "ten"}, failures: []string{"query:", "limit:"}},
+		"disabled":         {validate: false, args: map[string]interface{}{"limit": "ten"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewServer(backend.URL+"/manifest", "test-key", WithLogger(discardLogger), WithArgumentValidation(tt.validate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			before := calls
+			mu.Unlock()
+
+			result := callTool(t, s, "search", tt.args)
+
+			mu.Lock()
+			reached := calls > before
+			mu.Unlock()
+			if len(tt.failures) == 0 {
+				if result.IsError || !reached {
+					t.Fatalf("call returned %q and reached the backend: %t, want a successful call", resultText(result), reached)
+				}
+				return
+			}
+			if !result.IsError || reached {
+				t.Fatalf("call returned %q and reached the backend: %t, want it rejected before the backend", resultText(result), reached)
+			}
+			for _, failure := range tt.failures {
+				if !strings.Contains(resultText(result), failure) {
+					t.Errorf("error %q does not name %q", resultText(result), failure)
+				}
+			}
+		})
+	}
+}